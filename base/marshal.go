@@ -0,0 +1,51 @@
+package base
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Marshaler is implemented by estimators and transformers whose fitted state can be saved as JSON
+type Marshaler interface {
+	Marshal() ([]byte, error)
+	Unmarshal(buf []byte) error
+}
+
+var marshalers = struct {
+	sync.RWMutex
+	byName map[string]func() Marshaler
+}{byName: make(map[string]func() Marshaler)}
+
+// RegisterMarshaler registers under typeName a constructor of empty instances of a Marshaler type,
+// allowing composite estimators (pipeline.Pipeline) to rebuild their steps when unmarshaled.
+// packages register their types in init
+func RegisterMarshaler(typeName string, newMarshaler func() Marshaler) {
+	marshalers.Lock()
+	defer marshalers.Unlock()
+	marshalers.byName[typeName] = newMarshaler
+}
+
+// MarshalerTypeName returns the name under which the type of m was registered
+func MarshalerTypeName(m interface{}) (typeName string, ok bool) {
+	marshalers.RLock()
+	defer marshalers.RUnlock()
+	t := reflect.TypeOf(m)
+	for name, newMarshaler := range marshalers.byName {
+		if reflect.TypeOf(newMarshaler()) == t {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// NewMarshaler returns a new empty instance of the type registered as typeName
+func NewMarshaler(typeName string) (Marshaler, error) {
+	marshalers.RLock()
+	defer marshalers.RUnlock()
+	newMarshaler, ok := marshalers.byName[typeName]
+	if !ok {
+		return nil, fmt.Errorf("no Marshaler registered as %s", typeName)
+	}
+	return newMarshaler(), nil
+}
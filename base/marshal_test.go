@@ -0,0 +1,31 @@
+package base
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type testMarshaler struct{ V float64 }
+
+func (m *testMarshaler) Marshal() ([]byte, error)   { return json.Marshal(m) }
+func (m *testMarshaler) Unmarshal(buf []byte) error { return json.Unmarshal(buf, m) }
+
+func TestRegisterMarshaler(t *testing.T) {
+	RegisterMarshaler("testMarshaler", func() Marshaler { return &testMarshaler{} })
+	if name, ok := MarshalerTypeName(&testMarshaler{V: 1}); !ok || name != "testMarshaler" {
+		t.Errorf("expected testMarshaler, got %q %v", name, ok)
+	}
+	if _, ok := MarshalerTypeName(testMarshaler{}); ok {
+		t.Error("testMarshaler value type should not be registered")
+	}
+	m, err := NewMarshaler("testMarshaler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Unmarshal([]byte(`{"V":2}`)); err != nil || m.(*testMarshaler).V != 2 {
+		t.Errorf("unexpected %v %v", m, err)
+	}
+	if _, err = NewMarshaler("unknown"); err == nil {
+		t.Error("expected error for unknown type")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math"
 
 	"gonum.org/v1/gonum/blas/blas64"
 	"gonum.org/v1/gonum/mat"
@@ -239,3 +240,19 @@ func FromDense(dst mat.Mutable, dense *mat.Dense) *mat.Dense {
 	}
 	return dense
 }
+
+// CheckArray returns an error locating the first infinite value of X, or the first NaN unless allowNaN is set
+func CheckArray(X *mat.Dense, allowNaN bool) error {
+	if X == nil || X.IsEmpty() {
+		return nil
+	}
+	Xmat := X.RawMatrix()
+	for r, pos := 0, 0; r < Xmat.Rows; r, pos = r+1, pos+Xmat.Stride {
+		for c, v := range Xmat.Data[pos : pos+Xmat.Cols] {
+			if math.IsInf(v, 0) || (!allowNaN && math.IsNaN(v)) {
+				return fmt.Errorf("input contains %g at row %d, col %d", v, r, c)
+			}
+		}
+	}
+	return nil
+}
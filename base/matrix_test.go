@@ -2,6 +2,7 @@ package base
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"gonum.org/v1/gonum/mat"
@@ -80,3 +81,21 @@ func TestToDense(t *testing.T) {
 	}
 
 }
+
+func TestCheckArray(t *testing.T) {
+	X := mat.NewDense(3, 2, []float64{1, 2, 3, math.NaN(), 5, 6})
+	err := CheckArray(X, false)
+	if err == nil || err.Error() != "input contains NaN at row 1, col 1" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err = CheckArray(X, true); err != nil {
+		t.Errorf("unexpected error %v with allowNaN", err)
+	}
+	X.Set(0, 1, math.Inf(-1))
+	if err = CheckArray(X, true); err == nil || err.Error() != "input contains -Inf at row 0, col 1" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err = CheckArray(mat.NewDense(2, 2, nil), false); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
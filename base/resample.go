@@ -0,0 +1,36 @@
+package base
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+// StratifiedResample returns a bootstrap sample of (X,Y) preserving the count of each class.
+// classes are the distinct rows of Y. row i of Xr,Yr is drawn with replacement among the rows of the class of Y row i.
+// randomState may be nil to use the global random source
+func StratifiedResample(X, Y *mat.Dense, randomState rand.Source) (Xr, Yr *mat.Dense) {
+	nSamples, nFeatures := X.Dims()
+	yRows, nOutputs := Y.Dims()
+	if yRows != nSamples {
+		panic(fmt.Errorf("StratifiedResample: X has %d rows and Y has %d", nSamples, yRows))
+	}
+	var intn = rand.New(DefaultSource()).Intn
+	if randomState != rand.Source(nil) {
+		intn = rand.New(randomState).Intn
+	}
+	classOf := make([]string, nSamples)
+	members := make(map[string][]int)
+	for i := range classOf {
+		classOf[i] = fmt.Sprint(Y.RawRowView(i))
+		members[classOf[i]] = append(members[classOf[i]], i)
+	}
+	Xr, Yr = mat.NewDense(nSamples, nFeatures, nil), mat.NewDense(nSamples, nOutputs, nil)
+	for i, class := range classOf {
+		j := members[class][intn(len(members[class]))]
+		Xr.SetRow(i, X.RawRowView(j))
+		Yr.SetRow(i, Y.RawRowView(j))
+	}
+	return
+}
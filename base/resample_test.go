@@ -0,0 +1,34 @@
+package base
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestStratifiedResample(t *testing.T) {
+	const nSamples = 100
+	// X holds the sample index, 10% of the samples have class 1
+	X, Y := mat.NewDense(nSamples, 1, nil), mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		X.Set(i, 0, float64(i))
+		if i%10 == 3 {
+			Y.Set(i, 0, 1)
+		}
+	}
+	Xr, Yr := StratifiedResample(X, Y, NewSource(7))
+	if positives := mat.Sum(Yr); positives != 10 {
+		t.Errorf("expected 10 samples of class 1, got %g", positives)
+	}
+	drawn := make(map[int]int)
+	for i := 0; i < nSamples; i++ {
+		j := int(Xr.At(i, 0))
+		if Y.At(j, 0) != Yr.At(i, 0) {
+			t.Errorf("row %d: X and Y rows were drawn from different samples", i)
+		}
+		drawn[j]++
+	}
+	if len(drawn) == nSamples {
+		t.Error("expected some samples to be drawn more than once")
+	}
+}
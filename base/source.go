@@ -1,6 +1,8 @@
 package base
 
 import (
+	"fmt"
+	"math"
 	"sync"
 
 	"github.com/pa-m/randomkit"
@@ -19,6 +21,43 @@ type SourceCloner interface {
 // RandomState represents a bit more than random_state pythonic attribute. it's not only a seed but a source with a state as it's name states
 type RandomState = Source
 
+// ToRandomState converts v to a RandomState. v may be nil, a Source (returned as is),
+// or an integer seed (a float64 with an integer value is accepted as found in json) for a new LockedSource
+func ToRandomState(v interface{}) RandomState {
+	rs, ok := ConvertRandomState(v)
+	if !ok {
+		panic(fmt.Errorf("can't convert %T %v to RandomState", v, v))
+	}
+	return rs
+}
+
+// ConvertRandomState is like ToRandomState but returns false instead of panicking when v can't be converted
+func ConvertRandomState(v interface{}) (RandomState, bool) {
+	switch vv := v.(type) {
+	case nil:
+		return nil, true
+	case Source:
+		return vv, true
+	case int:
+		return NewLockedSource(uint64(vv)), true
+	case int32:
+		return NewLockedSource(uint64(vv)), true
+	case int64:
+		return NewLockedSource(uint64(vv)), true
+	case uint:
+		return NewLockedSource(uint64(vv)), true
+	case uint32:
+		return NewLockedSource(uint64(vv)), true
+	case uint64:
+		return NewLockedSource(vv), true
+	case float64:
+		if vv == math.Trunc(vv) {
+			return NewLockedSource(uint64(int64(vv))), true
+		}
+	}
+	return nil, false
+}
+
 // NewSource returns a new pseudo-random Source seeded with the given value.
 func NewSource(seed uint64) *randomkit.RKState {
 	var rng randomkit.RKState
@@ -26,6 +65,45 @@ func NewSource(seed uint64) *randomkit.RKState {
 	return &rng
 }
 
+// SeedGlobal seeds the global "golang.org/x/exp/rand" source, used by components whose RandomState is nil unless SetDefaultSource was called.
+// it is a convenience for tests; prefer injecting a RandomState
+func SeedGlobal(seed uint64) {
+	rand.Seed(seed)
+}
+
+// globalSource is a Source drawing from the global "golang.org/x/exp/rand" source
+type globalSource struct{}
+
+// Uint64 ...
+func (globalSource) Uint64() uint64 { return rand.Uint64() }
+
+// Seed ...
+func (globalSource) Seed(seed uint64) { rand.Seed(seed) }
+
+var defaultSource struct {
+	sync.RWMutex
+	src Source
+}
+
+// SetDefaultSource sets the source used by components whose RandomState is nil. src should be safe for concurrent use
+// (such as a LockedSource) when components run concurrently. nil restores the global "golang.org/x/exp/rand" source
+func SetDefaultSource(src Source) {
+	defaultSource.Lock()
+	defaultSource.src = src
+	defaultSource.Unlock()
+}
+
+// DefaultSource returns the source used by components whose RandomState is nil: the one set by SetDefaultSource,
+// or a Source drawing from the global "golang.org/x/exp/rand" source (see SeedGlobal)
+func DefaultSource() Source {
+	defaultSource.RLock()
+	defer defaultSource.RUnlock()
+	if defaultSource.src == nil {
+		return globalSource{}
+	}
+	return defaultSource.src
+}
+
 // LockedSource is an implementation of Source that is concurrency-safe.
 // It is just a standard Source with its operations protected by a sync.Mutex.
 type LockedSource struct {
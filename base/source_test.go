@@ -35,3 +35,62 @@ func TestSource(t *testing.T) {
 		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
 	}
 }
+
+func TestSeedGlobal(t *testing.T) {
+	var a, b [5]float64
+	SeedGlobal(7)
+	for i := range a {
+		a[i] = rand.Float64()
+	}
+	SeedGlobal(7)
+	for i := range b {
+		b[i] = rand.Float64()
+	}
+	if a != b {
+		t.Errorf("expected identical sequences after SeedGlobal, got %.8f and %.8f", a, b)
+	}
+}
+
+func TestToRandomState(t *testing.T) {
+	expected := NewLockedSource(7)
+	var a [5]uint64
+	for i := range a {
+		a[i] = expected.Uint64()
+	}
+	for _, seed := range []interface{}{7, uint64(7), 7.} {
+		s := ToRandomState(seed)
+		var b [5]uint64
+		for i := range b {
+			b[i] = s.Uint64()
+		}
+		if a != b {
+			t.Errorf("%T seed: expected %v, got %v", seed, a, b)
+		}
+	}
+	if s := NewSource(1); ToRandomState(s) != Source(s) {
+		t.Error("expected a Source to be returned as is")
+	}
+	if ToRandomState(nil) != nil {
+		t.Error("expected nil RandomState")
+	}
+	for _, v := range []interface{}{1.5, "seed"} {
+		if s, ok := ConvertRandomState(v); ok || s != nil {
+			t.Errorf("%T %v: expected no RandomState", v, v)
+		}
+	}
+}
+
+func TestDefaultSource(t *testing.T) {
+	s := NewLockedSource(7)
+	SetDefaultSource(s)
+	if DefaultSource() != Source(s) {
+		t.Error("expected DefaultSource to return the source set by SetDefaultSource")
+	}
+	SetDefaultSource(nil)
+	SeedGlobal(7)
+	a := rand.Uint64()
+	SeedGlobal(7)
+	if b := DefaultSource().Uint64(); a != b {
+		t.Errorf("expected DefaultSource to draw from the global source, got %d and %d", a, b)
+	}
+}
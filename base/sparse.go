@@ -0,0 +1,81 @@
+package base
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// CSR is a compressed sparse row matrix: the non-zero values of row i are Data[Indptr[i]:Indptr[i+1]],
+// in columns Indices[Indptr[i]:Indptr[i+1]]. it implements mat.Matrix
+type CSR struct {
+	r, c    int
+	Indptr  []int
+	Indices []int
+	Data    []float64
+}
+
+var _ mat.Matrix = &CSR{}
+
+// NewCSR returns a r x c *CSR from its scipy-like indptr, indices and data
+func NewCSR(r, c int, indptr, indices []int, data []float64) *CSR {
+	if len(indptr) != r+1 || len(indices) != len(data) || indptr[r] != len(data) {
+		panic(fmt.Errorf("NewCSR: inconsistent lengths: %d rows, %d indptr, %d indices, %d values", r, len(indptr), len(indices), len(data)))
+	}
+	return &CSR{r: r, c: c, Indptr: indptr, Indices: indices, Data: data}
+}
+
+// CSRFromDense returns the non-zero elements of m as a *CSR
+func CSRFromDense(m mat.Matrix) *CSR {
+	r, c := m.Dims()
+	s := &CSR{r: r, c: c, Indptr: make([]int, 1, r+1)}
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			if v := m.At(i, j); v != 0 {
+				s.Indices = append(s.Indices, j)
+				s.Data = append(s.Data, v)
+			}
+		}
+		s.Indptr = append(s.Indptr, len(s.Data))
+	}
+	return s
+}
+
+// Dims for CSR
+func (s *CSR) Dims() (r, c int) { return s.r, s.c }
+
+// At for CSR
+func (s *CSR) At(i, j int) float64 {
+	if i < 0 || i >= s.r || j < 0 || j >= s.c {
+		panic(mat.ErrIndexOutOfRange)
+	}
+	for k := s.Indptr[i]; k < s.Indptr[i+1]; k++ {
+		if s.Indices[k] == j {
+			return s.Data[k]
+		}
+	}
+	return 0
+}
+
+// T for CSR
+func (s *CSR) T() mat.Matrix { return mat.Transpose{Matrix: s} }
+
+// MulDense returns s·B, iterating over the non-zero elements of s only
+func (s *CSR) MulDense(B mat.Matrix) *mat.Dense {
+	br, bc := B.Dims()
+	if br != s.c {
+		panic(mat.ErrShape)
+	}
+	Bd := ToDense(B)
+	dst := mat.NewDense(s.r, bc, nil)
+	for i := 0; i < s.r; i++ {
+		row := dst.RawRowView(i)
+		for k := s.Indptr[i]; k < s.Indptr[i+1]; k++ {
+			v, brow := s.Data[k], Bd.RawRowView(s.Indices[k])
+			for o := range row {
+				row[o] += v * brow[o]
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,137 @@
+package base
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// PredictStream reads CSV rows of features from r, predicts them by batches of batchSize rows (default 100)
+// using preallocated buffers and writes the predictions as CSV rows to w.
+// all rows must have the same number of fields
+func PredictStream(predicter Predicter, r io.Reader, w io.Writer, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	writer := csv.NewWriter(w)
+	var X, Y *mat.Dense
+	var outRecord []string
+	nRows, nFeatures, nOutputs := 0, 0, predicter.GetNOutputs()
+	flush := func() error {
+		if nRows == 0 {
+			return nil
+		}
+		Xbatch, Ybatch := X.Slice(0, nRows, 0, nFeatures).(*mat.Dense), Y.Slice(0, nRows, 0, nOutputs).(*mat.Dense)
+		predicter.Predict(Xbatch, Ybatch)
+		for i := 0; i < nRows; i++ {
+			for j, v := range Ybatch.RawRowView(i) {
+				outRecord[j] = strconv.FormatFloat(v, 'g', -1, 64)
+			}
+			if err := writer.Write(outRecord); err != nil {
+				return err
+			}
+		}
+		nRows = 0
+		return nil
+	}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if X == nil {
+			nFeatures = len(record)
+			X, Y = mat.NewDense(batchSize, nFeatures, nil), mat.NewDense(batchSize, nOutputs, nil)
+			outRecord = make([]string, nOutputs)
+		}
+		row := X.RawRowView(nRows)
+		for j, field := range record {
+			if row[j], err = strconv.ParseFloat(field, 64); err != nil {
+				return fmt.Errorf("line %d: %s", line, err)
+			}
+		}
+		nRows++
+		if nRows == batchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WritePredictionsCSV writes to w a header and a CSV row per sample with its predicted labels (one column per output of predictions)
+// and, if proba is not nil, its per class probabilities in columns named after classes.
+// if predictions is nil, the predicted label is the class of highest probability
+func WritePredictionsCSV(w io.Writer, predictions *mat.Dense, classes []float64, proba *mat.Dense) error {
+	if predictions == nil && proba == nil {
+		return fmt.Errorf("WritePredictionsCSV: predictions and proba are nil")
+	}
+	nSamples, nClasses, nOutputs := 0, 0, 1
+	if proba != nil {
+		nSamples, nClasses = proba.Dims()
+		if nClasses != len(classes) {
+			return fmt.Errorf("WritePredictionsCSV: proba has %d columns for %d classes", nClasses, len(classes))
+		}
+	}
+	if predictions != nil {
+		var rows int
+		rows, nOutputs = predictions.Dims()
+		if proba != nil && rows != nSamples {
+			return fmt.Errorf("WritePredictionsCSV: %d predictions for %d proba rows", rows, nSamples)
+		}
+		nSamples = rows
+	}
+	formatFloat := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	record := make([]string, nOutputs+nClasses)
+	for o := 0; o < nOutputs; o++ {
+		record[o] = "label"
+		if nOutputs > 1 {
+			record[o] = fmt.Sprintf("label_%d", o)
+		}
+	}
+	for c, class := range classes[:nClasses] {
+		record[nOutputs+c] = "proba_" + formatFloat(class)
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+	for i := 0; i < nSamples; i++ {
+		if predictions != nil {
+			for o, v := range predictions.RawRowView(i) {
+				record[o] = formatFloat(v)
+			}
+		}
+		if proba != nil {
+			row := proba.RawRowView(i)
+			best := 0
+			for c, p := range row {
+				record[nOutputs+c] = formatFloat(p)
+				if p > row[best] {
+					best = c
+				}
+			}
+			if predictions == nil {
+				record[0] = formatFloat(classes[best])
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
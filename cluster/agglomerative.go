@@ -0,0 +1,173 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// AgglomerativeClustering recursively merges the pair of clusters that minimally increases the Linkage distance.
+// Linkage is one of "ward","complete","average","single". Distance is used for non-ward linkages (defaults to EuclideanDistance).
+// Fit builds the full merge tree: Children[i] are the nodes merged at step i, where nodes < NSamples are samples
+// and node NSamples+i is the cluster formed at step i. Distances[i] is the linkage distance of merge i.
+// Labels are obtained by cutting the tree at NClusters
+type AgglomerativeClustering struct {
+	NClusters int
+	Linkage   string
+	Distance  func(X, Y mat.Vector) float64
+
+	Children  [][2]int
+	Distances []float64
+	Labels    []int
+}
+
+// NewAgglomerativeClustering returns an *AgglomerativeClustering with ward linkage
+func NewAgglomerativeClustering(NClusters int) *AgglomerativeClustering {
+	return &AgglomerativeClustering{NClusters: NClusters, Linkage: "ward"}
+}
+
+// PredicterClone for AgglomerativeClustering
+func (m *AgglomerativeClustering) PredicterClone() base.Predicter {
+	clone := *m
+	return &clone
+}
+
+// IsClassifier returns true for AgglomerativeClustering
+func (m *AgglomerativeClustering) IsClassifier() bool { return true }
+
+// Fit builds the merge tree and computes Labels. Y is ignored
+func (m *AgglomerativeClustering) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	X := base.ToDense(Xmatrix)
+	NSamples, _ := X.Dims()
+	if m.NClusters < 1 || m.NClusters > NSamples {
+		panic(fmt.Errorf("NClusters must be in [1,%d], got %d", NSamples, m.NClusters))
+	}
+	distance := m.Distance
+	if distance == nil || m.Linkage == "ward" {
+		distance = EuclideanDistance
+	}
+	var update func(dik, djk, dij float64, ni, nj, nk int) float64
+	switch m.Linkage {
+	case "ward":
+		// Lance-Williams update on squared distances
+		update = func(dik, djk, dij float64, ni, nj, nk int) float64 {
+			return (float64(ni+nk)*dik + float64(nj+nk)*djk - float64(nk)*dij) / float64(ni+nj+nk)
+		}
+	case "complete":
+		update = func(dik, djk, dij float64, ni, nj, nk int) float64 { return math.Max(dik, djk) }
+	case "average":
+		update = func(dik, djk, dij float64, ni, nj, nk int) float64 {
+			return (float64(ni)*dik + float64(nj)*djk) / float64(ni+nj)
+		}
+	case "single":
+		update = func(dik, djk, dij float64, ni, nj, nk int) float64 { return math.Min(dik, djk) }
+	default:
+		panic(fmt.Errorf("unknown linkage %s", m.Linkage))
+	}
+	// D is the distance matrix between active clusters
+	D := mat.NewSymDense(NSamples, nil)
+	for i := 0; i < NSamples; i++ {
+		for j := 0; j < i; j++ {
+			d := distance(X.RowView(i), X.RowView(j))
+			if m.Linkage == "ward" {
+				d *= d
+			}
+			D.SetSym(i, j, d)
+		}
+	}
+	// node[i] is the tree node of active cluster i, size[i] its number of samples
+	node, size, active := make([]int, NSamples), make([]int, NSamples), make([]bool, NSamples)
+	for i := range node {
+		node[i], size[i], active[i] = i, 1, true
+	}
+	m.Children = make([][2]int, 0, NSamples-1)
+	m.Distances = make([]float64, 0, NSamples-1)
+	for step := 0; step < NSamples-1; step++ {
+		bi, bj, best := -1, -1, math.Inf(1)
+		for i := 0; i < NSamples; i++ {
+			if !active[i] {
+				continue
+			}
+			for j := 0; j < i; j++ {
+				if active[j] && D.At(i, j) < best {
+					bi, bj, best = i, j, D.At(i, j)
+				}
+			}
+		}
+		// merge bi into bj
+		for k := 0; k < NSamples; k++ {
+			if active[k] && k != bi && k != bj {
+				D.SetSym(bj, k, update(D.At(bi, k), D.At(bj, k), best, size[bi], size[bj], size[k]))
+			}
+		}
+		children := [2]int{node[bj], node[bi]}
+		if children[0] > children[1] {
+			children[0], children[1] = children[1], children[0]
+		}
+		if m.Linkage == "ward" {
+			best = math.Sqrt(best)
+		}
+		m.Children = append(m.Children, children)
+		m.Distances = append(m.Distances, best)
+		active[bi] = false
+		size[bj] += size[bi]
+		node[bj] = NSamples + step
+	}
+	m.Labels = m.cutTree(NSamples, m.NClusters)
+	return m
+}
+
+// cutTree returns labels of samples after the first NSamples-NClusters merges of Children.
+// labels are numbered in order of first sample appearance
+func (m *AgglomerativeClustering) cutTree(NSamples, NClusters int) []int {
+	parent := make([]int, 2*NSamples-1)
+	for i := range parent {
+		parent[i] = i
+	}
+	for step, children := range m.Children[:NSamples-NClusters] {
+		parent[children[0]], parent[children[1]] = NSamples+step, NSamples+step
+	}
+	root := func(i int) int {
+		for parent[i] != i {
+			i = parent[i]
+		}
+		return i
+	}
+	labels := make([]int, NSamples)
+	rootLabel := make(map[int]int)
+	for i := range labels {
+		r := root(i)
+		label, ok := rootLabel[r]
+		if !ok {
+			label = len(rootLabel)
+			rootLabel[r] = label
+		}
+		labels[i] = label
+	}
+	return labels
+}
+
+// GetNOutputs returns output columns number for Y to pass to predict
+func (m *AgglomerativeClustering) GetNOutputs() int { return 1 }
+
+// Predict for AgglomerativeClustering return Labels in Y. X must me the same passed to Fit
+func (m *AgglomerativeClustering) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsEmpty() {
+		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
+	}
+	ySamples, yCols := Y.Dims()
+	if nSamples != len(m.Labels) || ySamples != len(m.Labels) || yCols != 1 {
+		panic("X must me the same passed to Fit and Y must have size samples*1")
+	}
+	for i, label := range m.Labels {
+		Y.Set(i, 0, float64(label))
+	}
+	return base.FromDense(Ymutable, Y)
+}
+
+// Score for AgglomerativeClustering returns 1
+func (m *AgglomerativeClustering) Score(X, Y mat.Matrix) float64 { return 1 }
@@ -0,0 +1,55 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ base.Predicter = &AgglomerativeClustering{}
+
+func TestAgglomerativeClustering(t *testing.T) {
+	centers := mat.NewDense(3, 2, []float64{5, 5, -5, -5, 5, -5})
+	X, Y := datasets.MakeBlobs(&datasets.MakeBlobsConfig{NSamples: 150, Centers: centers, ClusterStd: .5, RandomState: base.NewLockedSource(7)})
+	for _, linkage := range []string{"ward", "complete", "average", "single"} {
+		m := NewAgglomerativeClustering(3)
+		m.Linkage = linkage
+		m.Fit(X, nil)
+		if len(m.Children) != 149 || len(m.Distances) != 149 {
+			t.Errorf("%s: expected 149 merges, got %d", linkage, len(m.Children))
+		}
+		// labels match ground truth up to a permutation
+		perm := map[float64]int{}
+		labels := map[int]bool{}
+		for i, label := range m.Labels {
+			y := Y.At(i, 0)
+			if l, ok := perm[y]; !ok {
+				perm[y] = label
+				labels[label] = true
+			} else if l != label {
+				t.Errorf("%s: sample %d of blob %g has label %d, expected %d", linkage, i, y, label, l)
+				break
+			}
+		}
+		if len(labels) != 3 {
+			t.Errorf("%s: expected 3 distinct labels, got %v", linkage, perm)
+		}
+	}
+}
+
+func ExampleAgglomerativeClustering() {
+	X := mat.NewDense(6, 2, []float64{1, 2, 1, 4, 1, 0, 4, 2, 4, 4, 4, 0})
+	m := NewAgglomerativeClustering(2)
+	m.Linkage = "single"
+	m.Fit(X, nil)
+	fmt.Println("Labels:", m.Labels)
+	fmt.Println("Children:", m.Children)
+	fmt.Println("Distances:", m.Distances)
+	// Output:
+	// Labels: [0 0 0 1 1 1]
+	// Children: [[0 1] [2 6] [3 4] [5 8] [7 9]]
+	// Distances: [2 2 2 2 3]
+}
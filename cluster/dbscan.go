@@ -111,9 +111,10 @@ func (m *DBSCAN) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 		m.Labels[i] = -1
 	}
 	// # A list of all core samples found.
+	// # as in scikit-learn, a core sample has at least MinSamples neighbors including itself
 	isCore := make([]bool, NSamples)
 	for sample := range NNeighbors {
-		if NNeighbors[sample] > m.MinSamples {
+		if NNeighbors[sample] >= m.MinSamples {
 			isCore[sample] = true
 			m.CoreSampleIndices = append(m.CoreSampleIndices, sample)
 		}
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/datasets"
 	"github.com/pa-m/sklearn/preprocessing"
 	"gonum.org/v1/gonum/mat"
@@ -118,3 +119,50 @@ func ExampleDBSCAN() {
 	// Output:
 	// Estimated number of clusters: 3
 }
+
+func TestDBSCANMoons(t *testing.T) {
+	X, Y := datasets.MakeMoons(200, .05, base.NewLockedSource(7))
+	// add sparse outliers far from the moons
+	outliers := mat.NewDense(3, 2, []float64{-3, 3, 4, -3, 3, 3})
+	Xall := &mat.Dense{}
+	Xall.Stack(X, outliers)
+	db := NewDBSCAN(&DBSCANConfig{Eps: .3, MinSamples: 5})
+	db.Fit(Xall, nil)
+	// each moon is one cluster
+	moonLabel := map[float64]int{}
+	for i := 0; i < 200; i++ {
+		y, label := Y.At(i, 0), db.Labels[i]
+		if label == -1 {
+			t.Errorf("sample %d of moon %g labeled as noise", i, y)
+			continue
+		}
+		if l, ok := moonLabel[y]; !ok {
+			moonLabel[y] = label
+		} else if l != label {
+			t.Errorf("sample %d of moon %g has label %d, expected %d", i, y, label, l)
+		}
+	}
+	if len(moonLabel) != 2 || moonLabel[0] == moonLabel[1] {
+		t.Errorf("expected 2 distinct clusters, got %v", moonLabel)
+	}
+	for i := 200; i < 203; i++ {
+		if db.Labels[i] != -1 {
+			t.Errorf("expected outlier %d to be labeled -1, got %d", i, db.Labels[i])
+		}
+	}
+}
+
+func TestDBSCANMinSamplesBoundary(t *testing.T) {
+	// the middle sample has exactly 3 neighbors within Eps, itself included, the others 2
+	X := mat.NewDense(3, 2, []float64{0, 0, .5, 0, 1, 0})
+	db := NewDBSCAN(&DBSCANConfig{Eps: .6, MinSamples: 3})
+	db.Fit(X, nil)
+	if len(db.CoreSampleIndices) != 1 || db.CoreSampleIndices[0] != 1 {
+		t.Errorf("expected the sample with exactly MinSamples neighbors to be the only core sample, got %v", db.CoreSampleIndices)
+	}
+	for i, label := range db.Labels {
+		if label != 0 {
+			t.Errorf("sample %d: expected cluster 0, got %d", i, label)
+		}
+	}
+}
@@ -67,3 +67,12 @@ func EuclideanDistance(a, b mat.Vector) float64 {
 	}
 	return math.Sqrt(d2)
 }
+
+// CosineDistance is a Distancer returning 1 - cosine similarity of a and b. it is 1 if a or b is zero
+func CosineDistance(a, b mat.Vector) float64 {
+	na, nb := mat.Norm(a, 2), mat.Norm(b, 2)
+	if na == 0 || nb == 0 {
+		return 1
+	}
+	return 1 - mat.Dot(a, b)/(na*nb)
+}
@@ -17,6 +17,9 @@ type KMeans struct {
 	// Optional members
 	NJobs    int
 	Distance func(X, Y mat.Vector) float64
+	// Metric is one of euclidean (default), manhattan, cosine. it sets Distance if nil.
+	// for manhattan and cosine, each centroid is updated to the medoid of its cluster: the sample with the lowest sum of distances to the others
+	Metric string
 	// Runtime filled members
 	Centroids *mat.Dense
 }
@@ -52,9 +55,24 @@ func (m *KMeans) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	if NSamples < m.NClusters {
 		panic(fmt.Errorf("NSamples<m.NClusters %d<%d", NSamples, m.NClusters))
 	}
-	if m.Distance == nil {
-		m.Distance = EuclideanDistance
-
+	medoids := false
+	switch m.Metric {
+	case "", "euclidean":
+		if m.Distance == nil {
+			m.Distance = EuclideanDistance
+		}
+	case "manhattan":
+		if m.Distance == nil {
+			m.Distance = MinkowskiDistance(1)
+		}
+		medoids = true
+	case "cosine":
+		if m.Distance == nil {
+			m.Distance = CosineDistance
+		}
+		medoids = true
+	default:
+		panic(fmt.Errorf("KMeans: unknown Metric %s", m.Metric))
 	}
 
 	m.Centroids = mat.NewDense(m.NClusters, NFeatures, nil)
@@ -79,19 +97,11 @@ func (m *KMeans) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 		// find nearest centroids
 		m.predict(X, NearestCentroid, CentroidCount, &changed)
 		// recompute centroids
-		m.Centroids.Sub(m.Centroids, m.Centroids)
-		var mu sync.Mutex // mu locks m.Centroids modifications
-		base.Parallelize(m.NJobs, NSamples, func(th, start, end int) {
-			row := make([]float64, NFeatures)
-			for sample := start; sample < end; sample++ {
-				ic := NearestCentroid[sample]
-				mu.Lock()
-				c := m.Centroids.RowView(ic)
-				mat.Row(row, sample, X)
-				c.(*mat.VecDense).AddScaledVec(c, 1./float64(CentroidCount[ic]), mat.NewVecDense(NFeatures, row))
-				mu.Unlock()
-			}
-		})
+		if medoids {
+			m.updateMedoids(X, NearestCentroid)
+		} else {
+			m.updateMeans(X, NearestCentroid, CentroidCount)
+		}
 		if changed {
 			unchangeCount = 0
 		} else {
@@ -101,6 +111,50 @@ func (m *KMeans) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	return m
 }
 
+// updateMeans sets each centroid to the mean of its cluster samples
+func (m *KMeans) updateMeans(X mat.Matrix, NearestCentroid, CentroidCount []int) {
+	NSamples, NFeatures := X.Dims()
+	m.Centroids.Sub(m.Centroids, m.Centroids)
+	var mu sync.Mutex // mu locks m.Centroids modifications
+	base.Parallelize(m.NJobs, NSamples, func(th, start, end int) {
+		row := make([]float64, NFeatures)
+		for sample := start; sample < end; sample++ {
+			ic := NearestCentroid[sample]
+			mu.Lock()
+			c := m.Centroids.RowView(ic)
+			mat.Row(row, sample, X)
+			c.(*mat.VecDense).AddScaledVec(c, 1./float64(CentroidCount[ic]), mat.NewVecDense(NFeatures, row))
+			mu.Unlock()
+		}
+	})
+}
+
+// updateMedoids sets each centroid to the sample of its cluster with the lowest sum of distances to the other samples of the cluster
+func (m *KMeans) updateMedoids(X mat.Matrix, NearestCentroid []int) {
+	X0 := mat.DenseCopyOf(X)
+	members := make([][]int, m.NClusters)
+	for sample, ic := range NearestCentroid {
+		members[ic] = append(members[ic], sample)
+	}
+	base.Parallelize(m.NJobs, m.NClusters, func(th, start, end int) {
+		for ic := start; ic < end; ic++ {
+			best, bestSum := -1, 0.
+			for _, i := range members[ic] {
+				sum := 0.
+				for _, j := range members[ic] {
+					sum += m.Distance(X0.RowView(i), X0.RowView(j))
+				}
+				if best < 0 || sum < bestSum {
+					best, bestSum = i, sum
+				}
+			}
+			if best >= 0 {
+				m.Centroids.SetRow(ic, X0.RawRowView(best))
+			}
+		}
+	})
+}
+
 // GetNOutputs returns output columns number for Y to pass to predict
 func (m *KMeans) GetNOutputs() int { return 1 }
 
@@ -3,12 +3,15 @@ package cluster
 import (
 	"fmt"
 	"image/color"
+	"math"
 	"os"
 	"os/exec"
+	"testing"
 	"time"
 
 	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/datasets"
+	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -97,3 +100,49 @@ func ExampleKMeans() {
 	}
 	// Output:
 }
+
+func TestKMeansMetric(t *testing.T) {
+	// two directional clusters, at angles 0 and 30 degrees, with radii spread over [1,10]
+	rnd := rand.New(base.NewSource(7))
+	const NSamples = 100
+	X, labels := mat.NewDense(NSamples, 2, nil), make([]int, NSamples)
+	for i := 0; i < NSamples; i++ {
+		labels[i] = i % 2
+		angle := (30*float64(labels[i]) + 3*rnd.NormFloat64()) * math.Pi / 180
+		radius := 1 + 9*rnd.Float64()
+		X.Set(i, 0, radius*math.Cos(angle))
+		X.Set(i, 1, radius*math.Sin(angle))
+	}
+	// accuracy up to a permutation of the two clusters
+	accuracy := func(Y *mat.Dense) float64 {
+		correct := 0
+		for i, label := range labels {
+			if int(Y.At(i, 0)) == label {
+				correct++
+			}
+		}
+		return math.Max(float64(correct), float64(NSamples-correct)) / NSamples
+	}
+	cosine := &KMeans{NClusters: 2, Metric: "cosine"}
+	cosine.Fit(X, nil)
+	if acc := accuracy(cosine.Predict(X, nil)); acc != 1 {
+		t.Errorf("expected cosine KMeans to recover the clusters, got accuracy %g", acc)
+	}
+	euclidean := &KMeans{NClusters: 2}
+	euclidean.Fit(X, nil)
+	if acc := accuracy(euclidean.Predict(X, nil)); acc > .8 {
+		t.Errorf("expected euclidean KMeans to fail on directional clusters, got accuracy %g", acc)
+	}
+	manhattan := &KMeans{NClusters: 2, Metric: "manhattan"}
+	manhattan.Fit(X, nil)
+	for ic := 0; ic < 2; ic++ {
+		// medoids are samples
+		found := false
+		for i := 0; i < NSamples; i++ {
+			found = found || mat.Equal(X.RowView(i), manhattan.Centroids.RowView(ic))
+		}
+		if !found {
+			t.Errorf("centroid %d is not a medoid", ic)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+// Package crossdecomposition contains PLSRegression
+package crossdecomposition
@@ -0,0 +1,191 @@
+package crossdecomposition
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// PLSRegression is a partial least squares regression (PLS2, NIPALS algorithm)
+// closely matching sklearn.cross_decomposition.PLSRegression
+// Scale scales X and Y columns to unit variance
+type PLSRegression struct {
+	NComponents int
+	Scale       bool
+	MaxIter     int
+	Tol         float64
+
+	XMean, XStd, YMean, YStd                                   []float64
+	XWeights, XLoadings, YWeights, YLoadings, XRotations, Coef *mat.Dense
+	NIter                                                      []int
+}
+
+// NewPLSRegression returns a *PLSRegression with Scale=true, MaxIter=500 and Tol=1e-6
+func NewPLSRegression(NComponents int) *PLSRegression {
+	return &PLSRegression{NComponents: NComponents, Scale: true, MaxIter: 500, Tol: 1e-6}
+}
+
+// IsClassifier returns false for PLSRegression
+func (*PLSRegression) IsClassifier() bool { return false }
+
+// PredicterClone for PLSRegression
+func (m *PLSRegression) PredicterClone() base.Predicter {
+	clone := *m
+	return &clone
+}
+
+// TransformerClone for PLSRegression
+func (m *PLSRegression) TransformerClone() base.Transformer {
+	clone := *m
+	return &clone
+}
+
+// GetNOutputs returns output columns number for Y to pass to predict
+func (m *PLSRegression) GetNOutputs() int {
+	return len(m.YMean)
+}
+
+// centerScale returns a centered (and scaled if scale is set) copy of X, with columns means and stds
+func centerScale(X mat.Matrix, scale bool) (Xout *mat.Dense, mean, std []float64) {
+	NSamples, NFeatures := X.Dims()
+	Xout = mat.DenseCopyOf(X)
+	mean, std = make([]float64, NFeatures), make([]float64, NFeatures)
+	col := make([]float64, NSamples)
+	for j := 0; j < NFeatures; j++ {
+		mat.Col(col, j, Xout)
+		mean[j], std[j] = stat.MeanStdDev(col, nil)
+		if !scale || std[j] == 0 || math.IsNaN(std[j]) {
+			std[j] = 1
+		}
+		for i := range col {
+			col[i] = (col[i] - mean[j]) / std[j]
+		}
+		Xout.SetCol(j, col)
+	}
+	return
+}
+
+// Fit computes NComponents latent components of X and Y and the regression coefficients
+func (m *PLSRegression) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	NSamples, NFeatures := Xmatrix.Dims()
+	_, NOutputs := Ymatrix.Dims()
+	if m.NComponents < 1 || m.NComponents > NFeatures {
+		panic(fmt.Errorf("NComponents must be in [1,%d], got %d", NFeatures, m.NComponents))
+	}
+	if m.MaxIter <= 0 {
+		m.MaxIter = 500
+	}
+	if m.Tol <= 0 {
+		m.Tol = 1e-6
+	}
+	var Xk, Yk *mat.Dense
+	Xk, m.XMean, m.XStd = centerScale(Xmatrix, m.Scale)
+	Yk, m.YMean, m.YStd = centerScale(Ymatrix, m.Scale)
+
+	nc := m.NComponents
+	m.XWeights, m.XLoadings = mat.NewDense(NFeatures, nc, nil), mat.NewDense(NFeatures, nc, nil)
+	m.YWeights, m.YLoadings = mat.NewDense(NOutputs, nc, nil), mat.NewDense(NOutputs, nc, nil)
+	m.NIter = make([]int, nc)
+	xWeights, xWeightsOld, yWeights := mat.NewVecDense(NFeatures, nil), mat.NewVecDense(NFeatures, nil), mat.NewVecDense(NOutputs, nil)
+	xScores, yScores := mat.NewVecDense(NSamples, nil), mat.NewVecDense(NSamples, nil)
+	xLoadings, yLoadings := mat.NewVecDense(NFeatures, nil), mat.NewVecDense(NOutputs, nil)
+	tmp := &mat.Dense{}
+	for k := 0; k < nc; k++ {
+		// NIPALS inner loop, starting with the first Y column having a non negligible variance
+		yCol := 0
+		for j := 0; j < NOutputs; j++ {
+			if mat.Norm(Yk.ColView(j), 2) > 1e-10 {
+				yCol = j
+				break
+			}
+		}
+		yScores.CopyVec(Yk.ColView(yCol))
+		xWeightsOld.Zero()
+		for it := 0; it < m.MaxIter; it++ {
+			m.NIter[k] = it + 1
+			xWeights.MulVec(Xk.T(), yScores)
+			xWeights.ScaleVec(1/math.Max(mat.Norm(xWeights, 2), 1e-16), xWeights)
+			xScores.MulVec(Xk, xWeights)
+			yWeights.MulVec(Yk.T(), xScores)
+			yWeights.ScaleVec(1/math.Max(mat.Dot(xScores, xScores), 1e-16), yWeights)
+			yScores.MulVec(Yk, yWeights)
+			yScores.ScaleVec(1/math.Max(mat.Dot(yWeights, yWeights), 1e-16), yScores)
+			xWeightsOld.SubVec(xWeights, xWeightsOld)
+			if mat.Dot(xWeightsOld, xWeightsOld) < m.Tol || NOutputs == 1 {
+				break
+			}
+			xWeightsOld.CopyVec(xWeights)
+		}
+		// deflation (regression mode)
+		xScores.MulVec(Xk, xWeights)
+		xss := mat.Dot(xScores, xScores)
+		xLoadings.MulVec(Xk.T(), xScores)
+		xLoadings.ScaleVec(1/xss, xLoadings)
+		tmp.Outer(1, xScores, xLoadings)
+		Xk.Sub(Xk, tmp)
+		yLoadings.MulVec(Yk.T(), xScores)
+		yLoadings.ScaleVec(1/xss, yLoadings)
+		tmp.Reset()
+		tmp.Outer(1, xScores, yLoadings)
+		Yk.Sub(Yk, tmp)
+		tmp.Reset()
+
+		m.XWeights.SetCol(k, xWeights.RawVector().Data)
+		m.XLoadings.SetCol(k, xLoadings.RawVector().Data)
+		m.YWeights.SetCol(k, yWeights.RawVector().Data)
+		m.YLoadings.SetCol(k, yLoadings.RawVector().Data)
+	}
+	// XRotations = XWeights (XLoadings' XWeights)^-1
+	PtW := &mat.Dense{}
+	PtW.Mul(m.XLoadings.T(), m.XWeights)
+	inv := &mat.Dense{}
+	if err := inv.Inverse(PtW); err != nil {
+		panic(err)
+	}
+	m.XRotations = &mat.Dense{}
+	m.XRotations.Mul(m.XWeights, inv)
+	// Coef is expressed in original (unscaled) units
+	m.Coef = &mat.Dense{}
+	m.Coef.Mul(m.XRotations, m.YLoadings.T())
+	m.Coef.Apply(func(i, j int, v float64) float64 { return v * m.YStd[j] / m.XStd[i] }, m.Coef)
+	return m
+}
+
+// Transform returns the projection of X on the latent X components. Y is returned unchanged
+func (m *PLSRegression) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	Xc := mat.DenseCopyOf(X)
+	Xc.Apply(func(i, j int, v float64) float64 { return (v - m.XMean[j]) / m.XStd[j] }, Xc)
+	Xout = &mat.Dense{}
+	Xout.Mul(Xc, m.XRotations)
+	return Xout, base.ToDense(Y)
+}
+
+// FitTransform fits the model then transforms X
+func (m *PLSRegression) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	m.Fit(X, Y)
+	return m.Transform(X, Y)
+}
+
+// Predict predicts Y for X using the latent components
+func (m *PLSRegression) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsEmpty() {
+		*Y = *mat.NewDense(nSamples, m.GetNOutputs(), nil)
+	}
+	Xc := mat.DenseCopyOf(X)
+	Xc.Apply(func(i, j int, v float64) float64 { return v - m.XMean[j] }, Xc)
+	Y.Mul(Xc, m.Coef)
+	Y.Apply(func(i, j int, v float64) float64 { return v + m.YMean[j] }, Y)
+	return base.FromDense(Ymutable, Y)
+}
+
+// Score returns R2 score of Predict
+func (m *PLSRegression) Score(X, Y mat.Matrix) float64 {
+	Ypred := m.Predict(X, nil)
+	return metrics.R2Score(Y, Ypred, nil, "").At(0, 0)
+}
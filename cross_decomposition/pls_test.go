@@ -0,0 +1,74 @@
+package crossdecomposition
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	linearmodel "github.com/pa-m/sklearn/linear_model"
+	"github.com/pa-m/sklearn/metrics"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ base.Predicter = &PLSRegression{}
+var _ base.Transformer = &PLSRegression{}
+
+// makeLatent returns X and Y sharing nLatent latent variables. X columns are noisy combinations of them, so they are collinear
+func makeLatent(rnd *rand.Rand, A, B *mat.Dense, nSamples int, noise float64) (X, Y *mat.Dense) {
+	nLatent, nFeatures := A.Dims()
+	_, nOutputs := B.Dims()
+	T := mat.NewDense(nSamples, nLatent, nil)
+	T.Apply(func(i, j int, v float64) float64 { return rnd.NormFloat64() }, T)
+	X, Y = mat.NewDense(nSamples, nFeatures, nil), mat.NewDense(nSamples, nOutputs, nil)
+	X.Mul(T, A)
+	X.Apply(func(i, j int, v float64) float64 { return v + noise*rnd.NormFloat64() }, X)
+	Y.Mul(T, B)
+	Y.Apply(func(i, j int, v float64) float64 { return v + noise*rnd.NormFloat64() }, Y)
+	return
+}
+
+func TestPLSRegression(t *testing.T) {
+	rnd := rand.New(base.NewLockedSource(7))
+	nLatent, nFeatures, nOutputs := 2, 20, 3
+	A, B := mat.NewDense(nLatent, nFeatures, nil), mat.NewDense(nLatent, nOutputs, nil)
+	A.Apply(func(i, j int, v float64) float64 { return rnd.NormFloat64() }, A)
+	B.Apply(func(i, j int, v float64) float64 { return rnd.NormFloat64() }, B)
+	Xtrain, Ytrain := makeLatent(rnd, A, B, 30, .5)
+	Xtest, Ytest := makeLatent(rnd, A, B, 200, .5)
+
+	pls := NewPLSRegression(nLatent)
+	pls.Fit(Xtrain, Ytrain)
+	ols := linearmodel.NewLinearRegression()
+	ols.Fit(Xtrain, Ytrain)
+
+	mse := func(m base.Predicter) float64 {
+		return metrics.MeanSquaredError(Ytest, m.Predict(Xtest, nil), nil, "").At(0, 0)
+	}
+	plsMSE, olsMSE := mse(pls), mse(ols)
+	if !(plsMSE < olsMSE) {
+		t.Errorf("expected PLS mse < OLS mse, got %g >= %g", plsMSE, olsMSE)
+	}
+	t.Logf("test mse PLS:%.4g OLS:%.4g", plsMSE, olsMSE)
+	if score := pls.Score(Xtest, Ytest); score < .6 {
+		t.Errorf("expected PLS R2 >= .6, got %g", score)
+	}
+	Xt, _ := pls.Transform(Xtest, nil)
+	if r, c := Xt.Dims(); r != 200 || c != nLatent {
+		t.Errorf("expected Transform dims 200,%d, got %d,%d", nLatent, r, c)
+	}
+}
+
+func TestPLSRegressionFullRank(t *testing.T) {
+	// with as many components as features, PLS is ordinary least squares
+	rnd := rand.New(base.NewLockedSource(7))
+	X, Y := mat.NewDense(20, 3, nil), mat.NewDense(20, 2, nil)
+	X.Apply(func(i, j int, v float64) float64 { return rnd.NormFloat64() }, X)
+	Y.Apply(func(i, j int, v float64) float64 { return rnd.NormFloat64() + X.At(i, j) }, Y)
+	pls := NewPLSRegression(3)
+	pls.Fit(X, Y)
+	ols := linearmodel.NewLinearRegression()
+	ols.Fit(X, Y)
+	if !mat.EqualApprox(pls.Predict(X, nil), ols.Predict(X, nil), 1e-8) {
+		t.Errorf("expected PLS with 3 components to match OLS")
+	}
+}
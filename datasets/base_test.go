@@ -4,10 +4,12 @@ import (
 
 	//"fmt"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/pa-m/sklearn/base"
 
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -146,3 +148,35 @@ func ExampleLoadWine() {
 	//13.16	2.36	2.67	18.6	101	2.8	3.24	0.3	2.81	5.68	1.03	3.17	1185	0
 
 }
+
+func TestDescribe(t *testing.T) {
+	ds := LoadBoston()
+	d := Describe(ds.X, ds.FeatureNames)
+	nSamples, _ := ds.X.Dims()
+	col := mat.Col(nil, 0, ds.X)
+	mean, min, max := floats.Sum(col)/float64(nSamples), floats.Min(col), floats.Max(col)
+	if d.FeatureNames[0] != "CRIM" {
+		t.Errorf("expected CRIM, got %s", d.FeatureNames[0])
+	}
+	if d.Count[0] != float64(nSamples) || math.Abs(d.Mean[0]-mean) > 1e-9 || d.Min[0] != min || d.Max[0] != max {
+		t.Errorf("CRIM: expected count %d mean %g min %g max %g, got %g %g %g %g", nSamples, mean, min, max, d.Count[0], d.Mean[0], d.Min[0], d.Max[0])
+	}
+	if !(d.Min[0] <= d.P25[0] && d.P25[0] <= d.P50[0] && d.P50[0] <= d.P75[0] && d.P75[0] <= d.Max[0]) {
+		t.Errorf("CRIM: quartiles are not ordered %s", d)
+	}
+}
+
+func ExampleDescribe() {
+	X := mat.NewDense(4, 2, []float64{1, 10, 2, 20, 3, 30, 4, 40})
+	fmt.Print(Describe(X, []string{"a", "b"}))
+	// Output:
+	//        a        b
+	// count  4        4
+	// mean   2.5      25
+	// std    1.29099  12.9099
+	// min    1        10
+	// 25%    1.75     17.5
+	// 50%    2.5      25
+	// 75%    3.25     32.5
+	// max    4        40
+}
@@ -0,0 +1,88 @@
+package datasets
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Description holds per-column statistics returned by Describe
+type Description struct {
+	FeatureNames                              []string
+	Count, Mean, Std, Min, P25, P50, P75, Max []float64
+}
+
+// Describe computes per-column count, mean, std (with 1 degree of freedom, like pandas), min, quartiles and max of X.
+// Quartiles use linear interpolation like numpy.percentile.
+// featureNames may be nil, in which case columns are named by their index
+func Describe(X *mat.Dense, featureNames []string) *Description {
+	nSamples, nFeatures := X.Dims()
+	if featureNames == nil {
+		featureNames = make([]string, nFeatures)
+		for j := range featureNames {
+			featureNames[j] = fmt.Sprint(j)
+		}
+	}
+	if len(featureNames) != nFeatures {
+		panic(fmt.Errorf("Describe: X has %d columns but %d feature names", nFeatures, len(featureNames)))
+	}
+	d := &Description{FeatureNames: featureNames}
+	for _, s := range []*[]float64{&d.Count, &d.Mean, &d.Std, &d.Min, &d.P25, &d.P50, &d.P75, &d.Max} {
+		*s = make([]float64, nFeatures)
+	}
+	col := make([]float64, nSamples)
+	quantile := func(p float64) float64 {
+		pos := p * float64(nSamples-1)
+		lo := math.Floor(pos)
+		if int(lo)+1 >= nSamples {
+			return col[nSamples-1]
+		}
+		return col[int(lo)] + (pos-lo)*(col[int(lo)+1]-col[int(lo)])
+	}
+	for j := 0; j < nFeatures; j++ {
+		mat.Col(col, j, X)
+		sort.Float64s(col)
+		d.Count[j] = float64(nSamples)
+		if nSamples == 0 {
+			d.Mean[j], d.Std[j], d.Min[j], d.P25[j], d.P50[j], d.P75[j], d.Max[j] = math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()
+			continue
+		}
+		sum := 0.
+		for _, v := range col {
+			sum += v
+		}
+		mean := sum / float64(nSamples)
+		ss := 0.
+		for _, v := range col {
+			ss += (v - mean) * (v - mean)
+		}
+		d.Mean[j] = mean
+		d.Std[j] = math.Sqrt(ss / float64(nSamples-1))
+		d.Min[j], d.Max[j] = col[0], col[nSamples-1]
+		d.P25[j], d.P50[j], d.P75[j] = quantile(.25), quantile(.5), quantile(.75)
+	}
+	return d
+}
+
+// String returns a table with one row per statistic and one column per feature
+func (d *Description) String() string {
+	b := &strings.Builder{}
+	w := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\t%s\n", strings.Join(d.FeatureNames, "\t"))
+	for _, row := range []struct {
+		name   string
+		values []float64
+	}{{"count", d.Count}, {"mean", d.Mean}, {"std", d.Std}, {"min", d.Min}, {"25%", d.P25}, {"50%", d.P50}, {"75%", d.P75}, {"max", d.Max}} {
+		cells := make([]string, len(row.values))
+		for j, v := range row.values {
+			cells[j] = fmt.Sprintf("%.6g", v)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", row.name, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+	return b.String()
+}
@@ -1,6 +1,7 @@
 package datasets
 
 import (
+	"fmt"
 	"math"
 	"runtime"
 	"sort"
@@ -26,7 +27,7 @@ import (
 // coef : boolean. the coefficients of the underlying linear model are returned regardless its value.
 // random_state : *math.Rand optional (default=nil)
 func MakeRegression(kwargs map[string]interface{}) (X, y, Coef *mat.Dense) {
-	rnd := func() float64 { return rand.NormFloat64() }
+	rnd := rand.New(base.DefaultSource()).NormFloat64
 	var nSamples, nFeatures, nInformative, nTargets, Shuffle = 100, 100, 10, 1, true
 	if v, ok := kwargs["n_samples"]; ok {
 		nSamples = v.(int)
@@ -99,7 +100,67 @@ func MakeRegression(kwargs map[string]interface{}) (X, y, Coef *mat.Dense) {
 	return
 }
 
-// TODO sklearn.datasets.make_classification(n_samples=100, n_features=20, n_informative=2, n_redundant=2, n_repeated=0, n_classes=2, n_clusters_per_class=2, weights=None, flip_y=0.01, class_sep=1.0, hypercube=True, shift=0.0, scale=1.0, shuffle=True, random_state=None)[source]
+// MakeClassificationConfig is the struct of MakeClassification params
+type MakeClassificationConfig struct {
+	NSamples     int
+	NFeatures    int
+	NInformative int
+	NClasses     int
+	ClassSep     float64
+	RandomState  base.RandomState
+}
+
+// MakeClassification generates a random n-class classification problem.
+// it is a simplified sklearn.datasets.make_classification with one cluster per class, no redundant nor repeated features and no shuffle:
+// the NInformative first columns of X are gaussian around a vertex of an hypercube of side 2*ClassSep, one per class,
+// and other columns are gaussian noise.
+// defaults are NSamples:100 NFeatures:20 NInformative:2 NClasses:2 ClassSep:1
+func MakeClassification(config *MakeClassificationConfig) (X, Y *mat.Dense) {
+	if config == nil {
+		config = &MakeClassificationConfig{}
+	}
+	if config.NSamples <= 0 {
+		config.NSamples = 100
+	}
+	if config.NFeatures <= 0 {
+		config.NFeatures = 20
+	}
+	if config.NInformative <= 0 {
+		config.NInformative = 2
+	}
+	if config.NClasses <= 0 {
+		config.NClasses = 2
+	}
+	if config.ClassSep <= 0 {
+		config.ClassSep = 1
+	}
+	if config.NInformative > config.NFeatures || (config.NInformative < 30 && config.NClasses > 1<<uint(config.NInformative)) {
+		panic(fmt.Errorf("NInformative=%d is too small for %d classes or too large for %d features", config.NInformative, config.NClasses, config.NFeatures))
+	}
+	randNormFloat64 := rand.New(base.DefaultSource()).NormFloat64
+	if normFloat64er, ok := config.RandomState.(base.NormFloat64er); ok {
+		randNormFloat64 = normFloat64er.NormFloat64
+	}
+	X = mat.NewDense(config.NSamples, config.NFeatures, nil)
+	Y = mat.NewDense(config.NSamples, 1, nil)
+	for i := 0; i < config.NSamples; i++ {
+		class := i % config.NClasses
+		Y.Set(i, 0, float64(class))
+		row := X.RawRowView(i)
+		for j := range row {
+			row[j] = randNormFloat64()
+			if j < config.NInformative {
+				// vertex of class: bit j of class number sets the sign of informative feature j
+				if class>>uint(j)&1 == 1 {
+					row[j] += config.ClassSep
+				} else {
+					row[j] -= config.ClassSep
+				}
+			}
+		}
+	}
+	return
+}
 
 // MakeBlobsConfig is the struct of MakeBlobs params
 type MakeBlobsConfig struct {
@@ -145,8 +206,9 @@ func MakeBlobs(config *MakeBlobsConfig) (X, Y *mat.Dense) {
 	if config.CenterBox == nil {
 		config.CenterBox = []float64{-10, 10}
 	}
-	randNormFloat64 := rand.NormFloat64
-	randIntn := rand.Intn
+	defaultRnd := rand.New(base.DefaultSource())
+	randNormFloat64 := defaultRnd.NormFloat64
+	randIntn := defaultRnd.Intn
 	if config.RandomState != nil {
 		if normFloat64er, ok := config.RandomState.(base.NormFloat64er); ok {
 			randNormFloat64 = normFloat64er.NormFloat64
@@ -195,3 +257,43 @@ func MakeBlobs(config *MakeBlobsConfig) (X, Y *mat.Dense) {
 	}
 	return
 }
+
+// MakeMoons makes two interleaving half circles. Y is 0 for the outer moon and 1 for the inner one. noise is the std of gaussian noise added to X
+// randomState may be nil
+func MakeMoons(NSamples int, noise float64, randomState base.RandomState) (X, Y *mat.Dense) {
+	if NSamples <= 0 {
+		NSamples = 100
+	}
+	randNormFloat64 := rand.New(base.DefaultSource()).NormFloat64
+	if normFloat64er, ok := randomState.(base.NormFloat64er); ok {
+		randNormFloat64 = normFloat64er.NormFloat64
+	}
+	NOut := NSamples / 2
+	NIn := NSamples - NOut
+	X = mat.NewDense(NSamples, 2, nil)
+	Y = mat.NewDense(NSamples, 1, nil)
+	linspace := func(i, n int) float64 {
+		if n == 1 {
+			return 0
+		}
+		return math.Pi * float64(i) / float64(n-1)
+	}
+	for i := 0; i < NOut; i++ {
+		t := linspace(i, NOut)
+		X.Set(i, 0, math.Cos(t))
+		X.Set(i, 1, math.Sin(t))
+	}
+	for i := 0; i < NIn; i++ {
+		t := linspace(i, NIn)
+		X.Set(NOut+i, 0, 1-math.Cos(t))
+		X.Set(NOut+i, 1, 1-math.Sin(t)-.5)
+		Y.Set(NOut+i, 0, 1)
+	}
+	if noise > 0 {
+		xr := X.RawMatrix()
+		for i := range xr.Data {
+			xr.Data[i] += noise * randNormFloat64()
+		}
+	}
+	return
+}
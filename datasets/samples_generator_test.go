@@ -2,6 +2,8 @@ package datasets
 
 import (
 	"fmt"
+
+	"gonum.org/v1/gonum/mat"
 )
 
 func ExampleMakeRegression() {
@@ -27,3 +29,23 @@ func ExampleMakeBlobs() {
 	// Output:
 	// rx=100 cx=2 ry=100 cy=1
 }
+
+func ExampleMakeMoons() {
+	X, Y := MakeMoons(5, 0, nil)
+	fmt.Printf("%.3f\n%g\n", mat.Formatted(X), mat.Formatted(Y.T()))
+	// Output:
+	// ⎡ 1.000   0.000⎤
+	// ⎢-1.000   0.000⎥
+	// ⎢ 0.000   0.500⎥
+	// ⎢ 1.000  -0.500⎥
+	// ⎣ 2.000   0.500⎦
+	// [0  0  1  1  1]
+}
+
+func ExampleMakeClassification() {
+	X, Y := MakeClassification(&MakeClassificationConfig{NSamples: 200, NFeatures: 5, NInformative: 2, NClasses: 3})
+	rx, cx := X.Dims()
+	fmt.Printf("rx=%d cx=%d classes=%g\n", rx, cx, Y.RawMatrix().Data[:4])
+	// Output:
+	// rx=200 cx=5 classes=[0 1 2 0]
+}
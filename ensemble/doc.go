@@ -0,0 +1,2 @@
+// Package ensemble contains meta-estimators combining several estimators: StackingClassifier
+package ensemble
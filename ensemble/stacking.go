@@ -0,0 +1,140 @@
+package ensemble
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	linearmodel "github.com/pa-m/sklearn/linear_model"
+	"github.com/pa-m/sklearn/metrics"
+	modelselection "github.com/pa-m/sklearn/model_selection"
+	"gonum.org/v1/gonum/mat"
+)
+
+// StackingClassifier fits a FinalEstimator on meta-features made of out-of-fold predictions of Estimators,
+// obtained by modelselection.CrossValPredict with CV (defaults to 5 folds RepeatedKFold with NRepeats=1).
+// predictions are class probabilities for estimators implementing PredictProbas or PredictProba, Predict outputs otherwise.
+// Estimators are then refitted on all samples to compute the meta-features of Predict.
+// FinalEstimator defaults to LogisticRegression. with Passthrough, X columns are appended to meta-features
+type StackingClassifier struct {
+	Estimators     []base.Predicter
+	FinalEstimator base.Predicter
+	CV             modelselection.Splitter
+	Passthrough    bool
+
+	FittedEstimators     []base.Predicter
+	FittedFinalEstimator base.Predicter
+	NOutputs             int
+}
+
+// NewStackingClassifier returns a *StackingClassifier
+func NewStackingClassifier(estimators []base.Predicter, finalEstimator base.Predicter) *StackingClassifier {
+	return &StackingClassifier{Estimators: estimators, FinalEstimator: finalEstimator}
+}
+
+// IsClassifier returns true for StackingClassifier
+func (*StackingClassifier) IsClassifier() bool { return true }
+
+// PredicterClone returns an unfitted clone
+func (m *StackingClassifier) PredicterClone() base.Predicter {
+	clone := &StackingClassifier{Estimators: make([]base.Predicter, len(m.Estimators)), Passthrough: m.Passthrough}
+	for i, estimator := range m.Estimators {
+		clone.Estimators[i] = estimator.PredicterClone()
+	}
+	if m.FinalEstimator != nil {
+		clone.FinalEstimator = m.FinalEstimator.PredicterClone()
+	}
+	if m.CV != nil {
+		clone.CV = m.CV.SplitterClone()
+	}
+	return clone
+}
+
+// GetNOutputs returns output columns number for Y to pass to predict
+func (m *StackingClassifier) GetNOutputs() int { return m.NOutputs }
+
+// Fit fits FinalEstimator on out-of-fold predictions of Estimators, then refits Estimators on X,Y
+func (m *StackingClassifier) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	if len(m.Estimators) == 0 {
+		panic(fmt.Errorf("StackingClassifier: no estimators"))
+	}
+	_, m.NOutputs = Y.Dims()
+	cv := m.CV
+	if cv == nil {
+		cv = &modelselection.RepeatedKFold{NSplits: 5, NRepeats: 1}
+	}
+	predictions := make([]*mat.Dense, len(m.Estimators))
+	for i, estimator := range m.Estimators {
+		predictions[i] = modelselection.CrossValPredict(estimator, X, Y, cv.SplitterClone(), metaFeatures)
+	}
+	final := m.FinalEstimator
+	if final == nil {
+		final = linearmodel.NewLogisticRegression()
+	}
+	m.FittedFinalEstimator = final.PredicterClone()
+	m.FittedFinalEstimator.Fit(m.stack(X, predictions), Y)
+	m.FittedEstimators = make([]base.Predicter, len(m.Estimators))
+	for i, estimator := range m.Estimators {
+		m.FittedEstimators[i] = estimator.PredicterClone()
+		m.FittedEstimators[i].Fit(X, Y)
+	}
+	return m
+}
+
+// Predict predicts with FittedFinalEstimator from meta-features of FittedEstimators
+func (m *StackingClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	predictions := make([]*mat.Dense, len(m.FittedEstimators))
+	for i, estimator := range m.FittedEstimators {
+		predictions[i] = metaFeatures(estimator, X)
+	}
+	return m.FittedFinalEstimator.Predict(m.stack(X, predictions), Ymutable)
+}
+
+// Score returns the accuracy
+func (m *StackingClassifier) Score(X, Y mat.Matrix) float64 {
+	return metrics.AccuracyScore(Y, m.Predict(X, nil), true, nil)
+}
+
+type probasPredicter interface {
+	PredictProbas(X mat.Matrix, Y mat.Mutable) *mat.Dense
+}
+
+type probaPredicter interface {
+	PredictProba(X mat.Matrix, Y mat.Mutable) *mat.Dense
+}
+
+// metaFeatures returns class probabilities of estimator for X if available, its predictions otherwise
+func metaFeatures(estimator base.Predicter, X mat.Matrix) *mat.Dense {
+	switch e := estimator.(type) {
+	case probasPredicter:
+		return e.PredictProbas(X, nil)
+	case probaPredicter:
+		return e.PredictProba(X, nil)
+	default:
+		return estimator.Predict(X, nil)
+	}
+}
+
+// stack returns the columns of predictions side by side, followed by X columns with Passthrough
+func (m *StackingClassifier) stack(X mat.Matrix, predictions []*mat.Dense) *mat.Dense {
+	nSamples, nFeatures := X.Dims()
+	nCols := 0
+	for _, p := range predictions {
+		_, c := p.Dims()
+		nCols += c
+	}
+	if m.Passthrough {
+		nCols += nFeatures
+	}
+	meta := mat.NewDense(nSamples, nCols, nil)
+	col := 0
+	for _, p := range predictions {
+		_, c := p.Dims()
+		meta.Slice(0, nSamples, col, col+c).(*mat.Dense).Copy(p)
+		col += c
+	}
+	if m.Passthrough {
+		meta.Slice(0, nSamples, col, nCols).(*mat.Dense).Copy(X)
+	}
+	return meta
+}
@@ -0,0 +1,44 @@
+package ensemble
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	modelselection "github.com/pa-m/sklearn/model_selection"
+	naivebayes "github.com/pa-m/sklearn/naive_bayes"
+	"github.com/pa-m/sklearn/neighbors"
+	neuralnetwork "github.com/pa-m/sklearn/neural_network"
+	"github.com/pa-m/sklearn/preprocessing"
+)
+
+var _ base.Predicter = &StackingClassifier{}
+
+func TestStackingClassifier(t *testing.T) {
+	ds := datasets.LoadIris()
+	X, Y := preprocessing.NewStandardScaler().FitTransform(ds.X, ds.Y)
+	Xtrain, Xtest, Ytrain, Ytest := modelselection.TrainTestSplit(X, Y, .3, 7)
+	mlp := neuralnetwork.NewMLPClassifier([]int{5}, "relu", "adam", 1e-4)
+	mlp.RandomState = base.NewLockedSource(7)
+	mlp.BatchSize = 20
+	mlp.MaxIter = 100
+	estimators := []base.Predicter{mlp, neighbors.NewKNeighborsClassifier(5, "uniform"), naivebayes.NewGaussianNB(nil, 1e-9)}
+	stacking := NewStackingClassifier(estimators, nil)
+	stacking.CV = &modelselection.RepeatedKFold{NSplits: 5, NRepeats: 1, RandomState: base.NewLockedSource(7)}
+	stacking.Fit(Xtrain, Ytrain)
+	if len(stacking.FittedEstimators) != 3 {
+		t.Fatalf("expected 3 fitted estimators, got %d", len(stacking.FittedEstimators))
+	}
+	best := 0.
+	for _, estimator := range stacking.FittedEstimators {
+		if score := estimator.Score(Xtest, Ytest); score > best {
+			best = score
+		}
+	}
+	if accuracy := stacking.Score(Xtest, Ytest); accuracy < best {
+		t.Errorf("expected stacking accuracy >= best member accuracy %g, got %g", best, accuracy)
+	}
+	if clone := stacking.PredicterClone().(*StackingClassifier); clone.FittedEstimators != nil || len(clone.Estimators) != 3 {
+		t.Error("expected unfitted clone")
+	}
+}
@@ -0,0 +1,2 @@
+// Package featureselection contains feature selection transformers: SelectKBest, VarianceThreshold, RFE
+package featureselection
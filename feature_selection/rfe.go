@@ -0,0 +1,134 @@
+package featureselection
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// RFE is a recursive feature elimination transformer.
+// Estimator is fitted on remaining features and the Step features with the lowest importance are removed,
+// until NFeaturesToSelect features remain (defaults to half of the features).
+// Importances returns features importances of a fitted estimator. it defaults to CoefImportances.
+// after Fit, Support holds the sorted indices of selected features, Ranking is 1 for selected features and increases
+// for features eliminated earlier, and Estimator is fitted on selected features
+type RFE struct {
+	Estimator         base.Predicter
+	NFeaturesToSelect int
+	Step              int
+	Importances       func(estimator base.Predicter) []float64
+
+	Support []int
+	Ranking []int
+}
+
+// NewRFE returns a *RFE
+func NewRFE(estimator base.Predicter, nFeaturesToSelect, step int) *RFE {
+	return &RFE{Estimator: estimator, NFeaturesToSelect: nFeaturesToSelect, Step: step}
+}
+
+// CoefImportances returns the L1 norm over outputs of the rows of estimator Coef field.
+// estimator Coef must be a *mat.Dense of shape (NFeatures,NOutputs) like in linear_model
+func CoefImportances(estimator base.Predicter) []float64 {
+	var coef *mat.Dense
+	if v := reflect.Indirect(reflect.ValueOf(estimator)); v.Kind() == reflect.Struct {
+		if field := v.FieldByName("Coef"); field.IsValid() {
+			coef, _ = field.Interface().(*mat.Dense)
+		}
+	}
+	if coef == nil {
+		panic(fmt.Errorf("%T has no fitted Coef *mat.Dense field", estimator))
+	}
+	r, c := coef.Dims()
+	importances := make([]float64, r)
+	for i := range importances {
+		for o := 0; o < c; o++ {
+			importances[i] += math.Abs(coef.At(i, o))
+		}
+	}
+	return importances
+}
+
+// TransformerClone ...
+func (m *RFE) TransformerClone() base.Transformer {
+	clone := *m
+	clone.Estimator = m.Estimator.PredicterClone()
+	return &clone
+}
+
+// Fit recursively eliminates features
+func (m *RFE) Fit(X, Y mat.Matrix) base.Fiter {
+	_, NFeatures := X.Dims()
+	nFeaturesToSelect := m.NFeaturesToSelect
+	if nFeaturesToSelect <= 0 {
+		nFeaturesToSelect = NFeatures / 2
+	}
+	if nFeaturesToSelect > NFeatures {
+		nFeaturesToSelect = NFeatures
+	}
+	step := m.Step
+	if step <= 0 {
+		step = 1
+	}
+	importances := m.Importances
+	if importances == nil {
+		importances = CoefImportances
+	}
+	m.Support = make([]int, NFeatures)
+	m.Ranking = make([]int, NFeatures)
+	for i := range m.Support {
+		m.Support[i] = i
+		m.Ranking[i] = 1
+	}
+	for len(m.Support) > nFeaturesToSelect {
+		m.Estimator.Fit(selectColumns(X, m.Support), Y)
+		imp := importances(m.Estimator)
+		order := make([]int, len(m.Support))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return imp[order[a]] < imp[order[b]] })
+		nEliminated := step
+		if nEliminated > len(m.Support)-nFeaturesToSelect {
+			nEliminated = len(m.Support) - nFeaturesToSelect
+		}
+		kept := make([]int, 0, len(m.Support)-nEliminated)
+		for _, i := range order[nEliminated:] {
+			kept = append(kept, m.Support[i])
+		}
+		sort.Ints(kept)
+		// every feature eliminated so far gets one rank more
+		isKept := make(map[int]bool, len(kept))
+		for _, j := range kept {
+			isKept[j] = true
+		}
+		for j := range m.Ranking {
+			if !isKept[j] {
+				m.Ranking[j]++
+			}
+		}
+		m.Support = kept
+	}
+	m.Estimator.Fit(selectColumns(X, m.Support), Y)
+	return m
+}
+
+// Transform returns the Support columns of X
+func (m *RFE) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	return selectColumns(X, m.Support), base.ToDense(Y)
+}
+
+// FitTransform fits then transforms X
+func (m *RFE) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	m.Fit(X, Y)
+	return m.Transform(X, Y)
+}
+
+// Predict predicts Y from X using the Estimator fitted on selected features
+func (m *RFE) Predict(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	return m.Estimator.Predict(selectColumns(X, m.Support), Y)
+}
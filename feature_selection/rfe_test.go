@@ -0,0 +1,38 @@
+package featureselection
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	linearmodel "github.com/pa-m/sklearn/linear_model"
+	"golang.org/x/exp/rand"
+)
+
+var _ base.Transformer = &RFE{}
+
+func TestRFE(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 10, "n_informative": 4, "random_state": rand.New(base.NewSource(7))})
+	m := NewRFE(linearmodel.NewLinearRegression(), 4, 2)
+	Xt, _ := m.FitTransform(X, Y)
+	if fmt.Sprint(m.Support) != "[0 1 2 3]" {
+		t.Errorf("expected informative features [0 1 2 3] to be selected, got %v", m.Support)
+	}
+	if _, c := Xt.Dims(); c != 4 {
+		t.Errorf("expected 4 columns, got %d", c)
+	}
+	for j, rank := range m.Ranking {
+		if (j < 4) != (rank == 1) {
+			t.Errorf("wrong ranking %v", m.Ranking)
+			break
+		}
+		if rank > 4 {
+			t.Errorf("expected ranks <= 4 with 6 features eliminated 2 by 2, got %v", m.Ranking)
+			break
+		}
+	}
+	if score := m.Estimator.Score(Xt, Y); score < .999 {
+		t.Errorf("expected R2 ~ 1 on selected features, got %g", score)
+	}
+}
@@ -0,0 +1,223 @@
+package featureselection
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ScoreFuncs are the univariate scoring functions usable by SelectKBest.
+// they return a score (higher is better) and a p-value (NaN if not applicable) per feature of X for a single column Y
+var ScoreFuncs = map[string]func(X, Y mat.Matrix) (scores, pvalues []float64){
+	"f_classif":           FClassif,
+	"f_regression":        FRegression,
+	"mutual_info_classif": MutualInfoClassif,
+}
+
+// FClassif computes the ANOVA F-value of each feature of X for class labels Y
+func FClassif(X, Y mat.Matrix) (scores, pvalues []float64) {
+	NSamples, NFeatures := X.Dims()
+	labels := classIndices(Y)
+	NClasses := 0
+	for _, c := range labels {
+		if c >= NClasses {
+			NClasses = c + 1
+		}
+	}
+	scores, pvalues = make([]float64, NFeatures), make([]float64, NFeatures)
+	dfBetween, dfWithin := float64(NClasses-1), float64(NSamples-NClasses)
+	count := make([]float64, NClasses)
+	for _, c := range labels {
+		count[c]++
+	}
+	sum := make([]float64, NClasses)
+	for j := 0; j < NFeatures; j++ {
+		for c := range sum {
+			sum[c] = 0
+		}
+		total := 0.
+		for i, c := range labels {
+			sum[c] += X.At(i, j)
+			total += X.At(i, j)
+		}
+		mean := total / float64(NSamples)
+		ssBetween, ssWithin := 0., 0.
+		for c := range sum {
+			d := sum[c]/count[c] - mean
+			ssBetween += count[c] * d * d
+		}
+		for i, c := range labels {
+			d := X.At(i, j) - sum[c]/count[c]
+			ssWithin += d * d
+		}
+		scores[j] = (ssBetween / dfBetween) / (ssWithin / dfWithin)
+		pvalues[j] = distuv.F{D1: dfBetween, D2: dfWithin}.Survival(scores[j])
+	}
+	return
+}
+
+// FRegression computes the univariate linear regression F-value of each feature of X for target Y
+func FRegression(X, Y mat.Matrix) (scores, pvalues []float64) {
+	NSamples, NFeatures := X.Dims()
+	y := mat.Col(nil, 0, Y)
+	x := make([]float64, NSamples)
+	scores, pvalues = make([]float64, NFeatures), make([]float64, NFeatures)
+	df := float64(NSamples - 2)
+	for j := 0; j < NFeatures; j++ {
+		mat.Col(x, j, X)
+		r := stat.Correlation(x, y, nil)
+		scores[j] = r * r / (1 - r*r) * df
+		pvalues[j] = distuv.F{D1: 1, D2: df}.Survival(scores[j])
+	}
+	return
+}
+
+// MutualInfoClassif estimates the mutual information (in nats) between each feature of X and class labels Y.
+// unlike scikit-learn's nearest neighbors estimator, features are discretized in sqrt(NSamples) quantile bins.
+// pvalues are NaN
+func MutualInfoClassif(X, Y mat.Matrix) (scores, pvalues []float64) {
+	NSamples, NFeatures := X.Dims()
+	labels := classIndices(Y)
+	NClasses := 0
+	for _, c := range labels {
+		if c >= NClasses {
+			NClasses = c + 1
+		}
+	}
+	NBins := int(math.Ceil(math.Sqrt(float64(NSamples))))
+	scores, pvalues = make([]float64, NFeatures), make([]float64, NFeatures)
+	order := make([]int, NSamples)
+	joint := make([]float64, NBins*NClasses)
+	for j := 0; j < NFeatures; j++ {
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return X.At(order[a], j) < X.At(order[b], j) })
+		for i := range joint {
+			joint[i] = 0
+		}
+		for rank, i := range order {
+			joint[rank*NBins/NSamples*NClasses+labels[i]]++
+		}
+		mi := 0.
+		classCount := make([]float64, NClasses)
+		for _, c := range labels {
+			classCount[c]++
+		}
+		n := float64(NSamples)
+		for b := 0; b < NBins; b++ {
+			binCount := 0.
+			for c := 0; c < NClasses; c++ {
+				binCount += joint[b*NClasses+c]
+			}
+			for c := 0; c < NClasses; c++ {
+				if pxy := joint[b*NClasses+c] / n; pxy > 0 {
+					mi += pxy * math.Log(pxy/(binCount/n*classCount[c]/n))
+				}
+			}
+		}
+		scores[j], pvalues[j] = mi, math.NaN()
+	}
+	return
+}
+
+// classIndices returns the index of the class of each sample of Y first column, classes being sorted
+func classIndices(Y mat.Matrix) []int {
+	y := mat.Col(nil, 0, Y)
+	classes := append([]float64{}, y...)
+	sort.Float64s(classes)
+	uniq := classes[:0]
+	for i, c := range classes {
+		if i == 0 || c != classes[i-1] {
+			uniq = append(uniq, c)
+		}
+	}
+	indices := make([]int, len(y))
+	for i, v := range y {
+		indices[i] = sort.SearchFloat64s(uniq, v)
+	}
+	return indices
+}
+
+// SelectKBest selects the K features having the highest scores.
+// ScoreFunc is a key of ScoreFuncs (defaults to "f_classif")
+// Support holds the sorted indices of selected features after Fit
+type SelectKBest struct {
+	ScoreFunc string
+	K         int
+
+	Scores, PValues []float64
+	Support         []int
+}
+
+// NewSelectKBest returns a *SelectKBest
+func NewSelectKBest(scoreFunc string, K int) *SelectKBest {
+	return &SelectKBest{ScoreFunc: scoreFunc, K: K}
+}
+
+// TransformerClone ...
+func (m *SelectKBest) TransformerClone() base.Transformer {
+	clone := *m
+	return &clone
+}
+
+// Fit computes features scores and Support
+func (m *SelectKBest) Fit(X, Y mat.Matrix) base.Fiter {
+	if m.ScoreFunc == "" {
+		m.ScoreFunc = "f_classif"
+	}
+	scoreFunc, ok := ScoreFuncs[m.ScoreFunc]
+	if !ok {
+		panic(fmt.Errorf("unknown ScoreFunc %s", m.ScoreFunc))
+	}
+	m.Scores, m.PValues = scoreFunc(X, Y)
+	NFeatures := len(m.Scores)
+	K := m.K
+	if K <= 0 || K > NFeatures {
+		K = NFeatures
+	}
+	idx := make([]int, NFeatures)
+	for i := range idx {
+		idx[i] = i
+	}
+	// NaN scores are ranked last
+	score := func(i int) float64 {
+		if math.IsNaN(m.Scores[i]) {
+			return math.Inf(-1)
+		}
+		return m.Scores[i]
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return score(idx[a]) > score(idx[b]) })
+	m.Support = append([]int{}, idx[:K]...)
+	sort.Ints(m.Support)
+	return m
+}
+
+// Transform returns the Support columns of X
+func (m *SelectKBest) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	return selectColumns(X, m.Support), base.ToDense(Y)
+}
+
+// FitTransform fits then transforms X
+func (m *SelectKBest) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	m.Fit(X, Y)
+	return m.Transform(X, Y)
+}
+
+// selectColumns returns a new matrix with columns of X listed in support
+func selectColumns(X mat.Matrix, support []int) *mat.Dense {
+	NSamples, _ := X.Dims()
+	Xout := mat.NewDense(NSamples, len(support), nil)
+	for i := 0; i < NSamples; i++ {
+		row := Xout.RawRowView(i)
+		for jo, j := range support {
+			row[jo] = X.At(i, j)
+		}
+	}
+	return Xout
+}
@@ -0,0 +1,40 @@
+package featureselection
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ base.Transformer = &SelectKBest{}
+
+func TestSelectKBest(t *testing.T) {
+	X, Y := datasets.MakeClassification(&datasets.MakeClassificationConfig{NSamples: 600, NFeatures: 10, NInformative: 3, NClasses: 8, ClassSep: 2, RandomState: base.NewLockedSource(7)})
+	for _, scoreFunc := range []string{"f_classif", "mutual_info_classif", "f_regression"} {
+		m := NewSelectKBest(scoreFunc, 3)
+		Xt, _ := m.FitTransform(X, Y)
+		if fmt.Sprint(m.Support) != "[0 1 2]" {
+			t.Errorf("%s: expected informative features [0 1 2] to be selected, got %v, scores %.3g", scoreFunc, m.Support, m.Scores)
+		}
+		if _, c := Xt.Dims(); c != 3 {
+			t.Errorf("%s: expected 3 columns, got %d", scoreFunc, c)
+		}
+		if !mat.Equal(Xt.ColView(1), X.ColView(m.Support[1])) {
+			t.Errorf("%s: wrong transformed column", scoreFunc)
+		}
+	}
+}
+
+func TestFClassif(t *testing.T) {
+	// scipy.stats.f_oneway([1,2,3],[4,5,6]) F=13.5 p=0.02131
+	X := mat.NewDense(6, 1, []float64{1, 2, 3, 4, 5, 6})
+	Y := mat.NewDense(6, 1, []float64{0, 0, 0, 1, 1, 1})
+	scores, pvalues := FClassif(X, Y)
+	if math.Abs(scores[0]-13.5) > 1e-12 || math.Abs(pvalues[0]-0.021311641128756727) > 1e-9 {
+		t.Errorf("expected F=13.5 p=0.0213, got %g %g", scores[0], pvalues[0])
+	}
+}
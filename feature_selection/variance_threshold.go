@@ -0,0 +1,54 @@
+package featureselection
+
+import (
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// VarianceThreshold removes the features whose (population) variance is not greater than Threshold.
+// Support holds the sorted indices of kept features after Fit
+type VarianceThreshold struct {
+	Threshold float64
+
+	Variances []float64
+	Support   []int
+}
+
+// NewVarianceThreshold returns a *VarianceThreshold
+func NewVarianceThreshold(threshold float64) *VarianceThreshold {
+	return &VarianceThreshold{Threshold: threshold}
+}
+
+// TransformerClone ...
+func (m *VarianceThreshold) TransformerClone() base.Transformer {
+	clone := *m
+	return &clone
+}
+
+// Fit computes features variances and Support
+func (m *VarianceThreshold) Fit(X, Y mat.Matrix) base.Fiter {
+	NSamples, NFeatures := X.Dims()
+	m.Variances = make([]float64, NFeatures)
+	m.Support = nil
+	x := make([]float64, NSamples)
+	for j := 0; j < NFeatures; j++ {
+		mat.Col(x, j, X)
+		m.Variances[j] = stat.Variance(x, nil) * float64(NSamples-1) / float64(NSamples)
+		if m.Variances[j] > m.Threshold {
+			m.Support = append(m.Support, j)
+		}
+	}
+	return m
+}
+
+// Transform returns the Support columns of X
+func (m *VarianceThreshold) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	return selectColumns(X, m.Support), base.ToDense(Y)
+}
+
+// FitTransform fits then transforms X
+func (m *VarianceThreshold) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	m.Fit(X, Y)
+	return m.Transform(X, Y)
+}
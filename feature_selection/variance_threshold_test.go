@@ -0,0 +1,34 @@
+package featureselection
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ base.Transformer = &VarianceThreshold{}
+
+func TestVarianceThreshold(t *testing.T) {
+	// column 1 is constant, column 2 has variance .0016
+	X := mat.NewDense(4, 3, []float64{
+		0, 2, 0.1,
+		1, 2, 0.1,
+		2, 2, 0.18,
+		3, 2, 0.1,
+	})
+	m := NewVarianceThreshold(0)
+	Xt, _ := m.FitTransform(X, nil)
+	if fmt.Sprint(m.Support) != "[0 2]" {
+		t.Errorf("expected constant column to be removed, got support %v", m.Support)
+	}
+	if _, c := Xt.Dims(); c != 2 || Xt.At(2, 1) != 0.18 {
+		t.Errorf("wrong transformed X %v", mat.Formatted(Xt))
+	}
+	m = NewVarianceThreshold(.01)
+	m.Fit(X, nil)
+	if fmt.Sprint(m.Support) != "[0]" {
+		t.Errorf("expected low variance column to be removed, got support %v variances %g", m.Support, m.Variances)
+	}
+}
@@ -0,0 +1,2 @@
+// Package inspection contains model inspection tools: PartialDependence
+package inspection
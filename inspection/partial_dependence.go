@@ -0,0 +1,57 @@
+package inspection
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// PartialDependence computes the partial dependence of estimator predictions (first output) on feature:
+// for each value of a grid, feature is set to this value for all samples of X and predictions are averaged over the samples.
+// like in scikit-learn, the grid is made of the unique values of feature if there are less than gridResolution of them,
+// otherwise of gridResolution equally spaced values between the 5th and 95th percentiles of feature
+func PartialDependence(estimator base.Predicter, X *mat.Dense, feature int, gridResolution int) (gridValues, averaged []float64) {
+	nSamples, nFeatures := X.Dims()
+	if feature < 0 || feature >= nFeatures {
+		panic(fmt.Errorf("PartialDependence: feature %d out of range [0,%d)", feature, nFeatures))
+	}
+	if gridResolution < 2 {
+		panic(fmt.Errorf("PartialDependence: gridResolution must be >= 2, got %d", gridResolution))
+	}
+	gridValues = grid(mat.Col(nil, feature, X), gridResolution)
+	averaged = make([]float64, len(gridValues))
+	Xg := mat.DenseCopyOf(X)
+	Ypred := mat.NewDense(nSamples, estimator.GetNOutputs(), nil)
+	for g, v := range gridValues {
+		for i := 0; i < nSamples; i++ {
+			Xg.Set(i, feature, v)
+		}
+		estimator.Predict(Xg, Ypred)
+		averaged[g] = stat.Mean(mat.Col(nil, 0, Ypred), nil)
+	}
+	return
+}
+
+// grid returns the sorted unique values of x if there are less than gridResolution of them,
+// or gridResolution values between 5th and 95th percentiles of x
+func grid(x []float64, gridResolution int) []float64 {
+	sort.Float64s(x)
+	unique := x[:0:0]
+	for i, v := range x {
+		if i == 0 || v != x[i-1] {
+			unique = append(unique, v)
+		}
+	}
+	if len(unique) < gridResolution {
+		return unique
+	}
+	lo, hi := stat.Quantile(.05, stat.LinInterp, x, nil), stat.Quantile(.95, stat.LinInterp, x, nil)
+	values := make([]float64, gridResolution)
+	for g := range values {
+		values[g] = lo + (hi-lo)*float64(g)/float64(gridResolution-1)
+	}
+	return values
+}
@@ -0,0 +1,45 @@
+package inspection
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	nn "github.com/pa-m/sklearn/neural_network"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestPartialDependence(t *testing.T) {
+	X, Y, coef := datasets.MakeRegression(map[string]interface{}{"n_samples": 200, "n_features": 3, "n_informative": 2, "random_state": rand.New(base.NewSource(7))})
+	mlp := nn.NewMLPRegressor([]int{10}, "relu", "lbfgs", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 200
+	mlp.Fit(X, Y)
+	// informative features are the first ones
+	nInformative, _ := coef.Dims()
+	for feature := 0; feature < nInformative; feature++ {
+		c := coef.At(feature, 0)
+		gridValues, averaged := PartialDependence(mlp, X, feature, 10)
+		if len(gridValues) != 10 || len(averaged) != 10 {
+			t.Fatalf("expected 10 grid values, got %d,%d", len(gridValues), len(averaged))
+		}
+		for g := 1; g < len(gridValues); g++ {
+			if gridValues[g] <= gridValues[g-1] {
+				t.Errorf("grid is not increasing: %g", gridValues)
+			}
+			if (averaged[g]-averaged[g-1])*c <= 0 {
+				t.Errorf("feature %d with coef %g: partial dependence is not monotonic: %g", feature, c, averaged)
+				break
+			}
+		}
+	}
+	// a feature with few unique values gives a grid of those values
+	Xb := mat.DenseCopyOf(X)
+	for i := 0; i < 200; i++ {
+		Xb.Set(i, 0, float64(i%3))
+	}
+	if gridValues, _ := PartialDependence(mlp, Xb, 0, 10); len(gridValues) != 3 || gridValues[0] != 0 || gridValues[2] != 2 {
+		t.Errorf("expected grid [0 1 2], got %g", gridValues)
+	}
+}
@@ -0,0 +1,2 @@
+// Package kernelapproximation contains explicit approximate kernel feature maps: RBFSampler, Nystroem
+package kernelapproximation
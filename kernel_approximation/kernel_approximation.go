@@ -0,0 +1,189 @@
+package kernelapproximation
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+// RBFSampler approximates the feature map of the RBF kernel exp(-Gamma*||x-y||²) by random Fourier features
+// Gamma defaults to 1 and NComponents to 100
+type RBFSampler struct {
+	Gamma       float64
+	NComponents int
+	RandomState base.RandomState
+
+	RandomWeights *mat.Dense // (NFeatures,NComponents)
+	RandomOffset  []float64
+}
+
+// NewRBFSampler returns a *RBFSampler
+func NewRBFSampler(gamma float64, nComponents int) *RBFSampler {
+	return &RBFSampler{Gamma: gamma, NComponents: nComponents}
+}
+
+// TransformerClone ...
+func (m *RBFSampler) TransformerClone() base.Transformer {
+	clone := *m
+	if sourceCloner, ok := clone.RandomState.(base.SourceCloner); ok && sourceCloner != base.SourceCloner(nil) {
+		clone.RandomState = sourceCloner.SourceClone()
+	}
+	return &clone
+}
+
+// Fit draws RandomWeights from a normal distribution of variance 2*Gamma and RandomOffset uniformly in [0,2π)
+func (m *RBFSampler) Fit(X, Y mat.Matrix) base.Fiter {
+	if m.Gamma <= 0 {
+		m.Gamma = 1
+	}
+	if m.NComponents <= 0 {
+		m.NComponents = 100
+	}
+	defaultRnd := rand.New(base.DefaultSource())
+	rndNormFloat64, rndFloat64 := defaultRnd.NormFloat64, defaultRnd.Float64
+	if m.RandomState != base.Source(nil) {
+		rnd := rand.New(m.RandomState)
+		rndNormFloat64, rndFloat64 = rnd.NormFloat64, rnd.Float64
+	}
+	_, NFeatures := X.Dims()
+	m.RandomWeights = mat.NewDense(NFeatures, m.NComponents, nil)
+	w := m.RandomWeights.RawMatrix().Data
+	for i := range w {
+		w[i] = math.Sqrt(2*m.Gamma) * rndNormFloat64()
+	}
+	m.RandomOffset = make([]float64, m.NComponents)
+	for i := range m.RandomOffset {
+		m.RandomOffset[i] = 2 * math.Pi * rndFloat64()
+	}
+	return m
+}
+
+// Transform maps X to sqrt(2/NComponents)*cos(X*RandomWeights+RandomOffset)
+func (m *RBFSampler) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	NSamples, _ := X.Dims()
+	Xout = mat.NewDense(NSamples, m.NComponents, nil)
+	Xout.Mul(X, m.RandomWeights)
+	scale := math.Sqrt(2 / float64(m.NComponents))
+	Xout.Apply(func(_, j int, v float64) float64 { return scale * math.Cos(v+m.RandomOffset[j]) }, Xout)
+	return Xout, base.ToDense(Y)
+}
+
+// FitTransform fits then transforms X
+func (m *RBFSampler) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	m.Fit(X, Y)
+	return m.Transform(X, Y)
+}
+
+// Nystroem approximates a kernel feature map using NComponents samples of the training data as basis.
+// Kernel is one of "rbf" (default), "poly", "linear". Gamma defaults to 1/NFeatures, Degree to 3 and NComponents to 100
+// (or the number of training samples if it's smaller)
+type Nystroem struct {
+	Kernel       string
+	Gamma        float64
+	Degree       float64
+	Coef0        float64
+	NComponents  int
+	RandomState  base.RandomState
+	Components   *mat.Dense // basis samples
+	ComponentIdx []int
+	// NormalizationMatrix is K^-1/2 where K is the kernel matrix of Components
+	NormalizationMatrix *mat.Dense
+}
+
+// NewNystroem returns a *Nystroem
+func NewNystroem(kernel string, nComponents int) *Nystroem {
+	return &Nystroem{Kernel: kernel, NComponents: nComponents, Degree: 3, Coef0: 1}
+}
+
+// TransformerClone ...
+func (m *Nystroem) TransformerClone() base.Transformer {
+	clone := *m
+	if sourceCloner, ok := clone.RandomState.(base.SourceCloner); ok && sourceCloner != base.SourceCloner(nil) {
+		clone.RandomState = sourceCloner.SourceClone()
+	}
+	return &clone
+}
+
+// kernel returns the kernel matrix between rows of A and B
+func (m *Nystroem) kernel(A, B mat.Matrix) *mat.Dense {
+	ra, c := A.Dims()
+	rb, _ := B.Dims()
+	K := mat.NewDense(ra, rb, nil)
+	switch m.Kernel {
+	case "", "rbf":
+		K.Apply(func(i, j int, _ float64) float64 {
+			d2 := 0.
+			for k := 0; k < c; k++ {
+				e := A.At(i, k) - B.At(j, k)
+				d2 += e * e
+			}
+			return math.Exp(-m.Gamma * d2)
+		}, K)
+	case "linear", "poly":
+		K.Mul(A, B.T())
+		if m.Kernel == "poly" {
+			K.Apply(func(_, _ int, v float64) float64 { return math.Pow(m.Gamma*v+m.Coef0, m.Degree) }, K)
+		}
+	default:
+		panic(fmt.Errorf("unknown kernel %s", m.Kernel))
+	}
+	return K
+}
+
+// Fit samples Components from X and computes NormalizationMatrix
+func (m *Nystroem) Fit(X, Y mat.Matrix) base.Fiter {
+	NSamples, NFeatures := X.Dims()
+	if m.Gamma <= 0 {
+		m.Gamma = 1 / float64(NFeatures)
+	}
+	if m.Degree == 0 {
+		m.Degree = 3
+	}
+	nComponents := m.NComponents
+	if nComponents <= 0 {
+		nComponents = 100
+	}
+	if nComponents > NSamples {
+		nComponents = NSamples
+	}
+	perm := rand.New(base.DefaultSource()).Perm
+	if m.RandomState != base.Source(nil) {
+		perm = rand.New(m.RandomState).Perm
+	}
+	m.ComponentIdx = perm(NSamples)[:nComponents]
+	m.Components = mat.NewDense(nComponents, NFeatures, nil)
+	for i, idx := range m.ComponentIdx {
+		for j := 0; j < NFeatures; j++ {
+			m.Components.Set(i, j, X.At(idx, j))
+		}
+	}
+	// K = U S Vt, K^-1/2 = U S^-1/2 Vt, singular values are clipped to 1e-12
+	var svd mat.SVD
+	if !svd.Factorize(m.kernel(m.Components, m.Components), mat.SVDFull) {
+		panic(fmt.Errorf("Nystroem: SVD failed"))
+	}
+	var U, V mat.Dense
+	svd.UTo(&U)
+	svd.VTo(&V)
+	S := svd.Values(nil)
+	U.Apply(func(_, j int, v float64) float64 { return v / math.Sqrt(math.Max(S[j], 1e-12)) }, &U)
+	m.NormalizationMatrix = &mat.Dense{}
+	m.NormalizationMatrix.Mul(&U, V.T())
+	return m
+}
+
+// Transform maps X to kernel(X,Components)*NormalizationMatrix'
+func (m *Nystroem) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	Xout = &mat.Dense{}
+	Xout.Mul(m.kernel(X, m.Components), m.NormalizationMatrix.T())
+	return Xout, base.ToDense(Y)
+}
+
+// FitTransform fits then transforms X
+func (m *Nystroem) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	m.Fit(X, Y)
+	return m.Transform(X, Y)
+}
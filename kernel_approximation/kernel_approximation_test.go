@@ -0,0 +1,74 @@
+package kernelapproximation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	neuralnetwork "github.com/pa-m/sklearn/neural_network"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ = []base.Transformer{&RBFSampler{}, &Nystroem{}}
+
+// linearAccuracy fits a linear (no hidden layer) classifier and returns its training accuracy
+func linearAccuracy(X, Y mat.Matrix) float64 {
+	mlp := neuralnetwork.NewMLPClassifier([]int{}, "identity", "adam", 0)
+	mlp.RandomState = base.NewLockedSource(7)
+	mlp.LearningRateInit = .05
+	mlp.MaxIter = 300
+	mlp.Fit(X, Y)
+	return mlp.Score(X, Y)
+}
+
+func TestRBFSamplerMoons(t *testing.T) {
+	X, Y := datasets.MakeMoons(200, .05, base.NewLockedSource(7))
+	m := NewRBFSampler(2, 100)
+	m.RandomState = base.NewLockedSource(7)
+	Xt, _ := m.FitTransform(X, Y)
+	accPlain, accRBF := linearAccuracy(X, Y), linearAccuracy(Xt, Y)
+	if accPlain > .92 || accRBF < .98 {
+		t.Errorf("expected linear model to separate moons only on RBFSampler features, got accuracy %g on X, %g on features", accPlain, accRBF)
+	}
+}
+
+func TestNystroemMoons(t *testing.T) {
+	X, Y := datasets.MakeMoons(200, .05, base.NewLockedSource(7))
+	m := NewNystroem("rbf", 50)
+	m.Gamma = 2
+	m.RandomState = base.NewLockedSource(7)
+	Xt, _ := m.FitTransform(X, Y)
+	if acc := linearAccuracy(Xt, Y); acc < .98 {
+		t.Errorf("expected linear model to separate moons on Nystroem features, got accuracy %g", acc)
+	}
+}
+
+func TestKernelApproximation(t *testing.T) {
+	X, _ := datasets.MakeMoons(40, .05, base.NewLockedSource(7))
+	gamma := 2.
+	K := mat.NewDense(40, 40, nil)
+	K.Apply(func(i, j int, _ float64) float64 {
+		dx, dy := X.At(i, 0)-X.At(j, 0), X.At(i, 1)-X.At(j, 1)
+		return math.Exp(-gamma * (dx*dx + dy*dy))
+	}, K)
+	maxErr := func(Xt *mat.Dense) float64 {
+		var approx mat.Dense
+		approx.Mul(Xt, Xt.T())
+		approx.Sub(&approx, K)
+		return math.Max(mat.Max(&approx), -mat.Min(&approx))
+	}
+	// with all samples as basis, Nystroem is exact
+	nystroem := NewNystroem("rbf", 40)
+	nystroem.Gamma = gamma
+	Xt, _ := nystroem.FitTransform(X, nil)
+	if e := maxErr(Xt); e > 1e-6 {
+		t.Errorf("expected exact Nystroem kernel, got error %g", e)
+	}
+	sampler := NewRBFSampler(gamma, 5000)
+	sampler.RandomState = base.NewLockedSource(7)
+	Xt, _ = sampler.FitTransform(X, nil)
+	if e := maxErr(Xt); e > .1 {
+		t.Errorf("expected RBFSampler kernel error < .1, got %g", e)
+	}
+}
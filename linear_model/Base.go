@@ -86,7 +86,12 @@ func (regr *LinearRegression) PredicterClone() base.Predicter {
 }
 
 // Fit fits Coef for a LinearRegression
+// X may be a *base.CSR, which is not densified (see fitCSR)
 func (regr *LinearRegression) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	if csr, ok := Xmatrix.(*base.CSR); ok {
+		regr.fitCSR(csr, base.ToDense(Ymatrix))
+		return regr
+	}
 	X0, Y0 := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
 	var X, Y, YOffset *mat.Dense
 	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, Y0, regr.FitIntercept, regr.Normalize, nil)
@@ -97,6 +102,55 @@ func (regr *LinearRegression) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	return regr
 }
 
+// fitCSR solves the normal equations XᵀX·Coef = XᵀY, accumulated over the non-zero elements of X.
+// centering and normalization are applied to XᵀX and XᵀY instead of X, using
+// (X-1·XOffset)ᵀ(X-1·XOffset) = XᵀX - nSamples·XOffsetᵀ·XOffset, so that memory is nFeatures² instead of nSamples·nFeatures
+func (regr *LinearRegression) fitCSR(X *base.CSR, Y *mat.Dense) {
+	nSamples, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	gram, xty := mat.NewDense(nFeatures, nFeatures, nil), mat.NewDense(nFeatures, nOutputs, nil)
+	xsum := make([]float64, nFeatures)
+	for i := 0; i < nSamples; i++ {
+		yrow := Y.RawRowView(i)
+		for k := X.Indptr[i]; k < X.Indptr[i+1]; k++ {
+			j, v := X.Indices[k], X.Data[k]
+			xsum[j] += v
+			grow, xtyrow := gram.RawRowView(j), xty.RawRowView(j)
+			for l := X.Indptr[i]; l < X.Indptr[i+1]; l++ {
+				grow[X.Indices[l]] += v * X.Data[l]
+			}
+			for o, y := range yrow {
+				xtyrow[o] += v * y
+			}
+		}
+	}
+	n := float64(nSamples)
+	regr.XOffset, regr.XScale = mat.NewDense(1, nFeatures, nil), mat.NewDense(1, nFeatures, nil)
+	YOffset := mat.NewDense(1, nOutputs, nil)
+	if regr.FitIntercept {
+		for j, sum := range xsum {
+			regr.XOffset.Set(0, j, sum/n)
+		}
+		for o := 0; o < nOutputs; o++ {
+			YOffset.Set(0, o, mat.Sum(Y.ColView(o))/n)
+		}
+		gram.Apply(func(j, l int, g float64) float64 { return g - n*regr.XOffset.At(0, j)*regr.XOffset.At(0, l) }, gram)
+		xty.Apply(func(j, o int, v float64) float64 { return v - n*regr.XOffset.At(0, j)*YOffset.At(0, o) }, xty)
+	}
+	for j := 0; j < nFeatures; j++ {
+		scale := 1.
+		if norm := math.Sqrt(gram.At(j, j)); regr.Normalize && norm != 0 {
+			scale = norm
+		}
+		regr.XScale.Set(0, j, scale)
+	}
+	gram.Apply(func(j, l int, g float64) float64 { return g / regr.XScale.At(0, j) / regr.XScale.At(0, l) }, gram)
+	xty.Apply(func(j, o int, v float64) float64 { return v / regr.XScale.At(0, j) }, xty)
+	regr.Coef = &mat.Dense{}
+	regr.Coef.Solve(gram, xty)
+	regr.LinearModel.setIntercept(regr.XOffset, YOffset, regr.XScale)
+}
+
 // GetNOutputs returns output columns number for Y to pass to predict
 func (regr *LinearModel) GetNOutputs() int {
 	_, nOutputs := regr.Coef.Dims()
@@ -305,6 +359,8 @@ type LinFitOptions struct {
 	Recorder                            optimize.Recorder
 	PerOutputFit                        bool
 	DisableRegularizationOfFirstFeature bool
+	// RandomState is used for Theta initialization. the global source is used if nil
+	RandomState base.RandomState
 }
 
 // LinFitResult is the result or LinFit
@@ -347,8 +403,12 @@ func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 	if opts.ThetaInitializer != nil {
 		opts.ThetaInitializer(Theta)
 	} else {
+		uniform := rand.New(base.DefaultSource()).Float64
+		if opts.RandomState != base.RandomState(nil) {
+			uniform = rand.New(opts.RandomState).Float64
+		}
 		Theta.Apply(func(i, j int, v float64) float64 {
-			return 0.01 * rand.Float64()
+			return 0.01 * uniform()
 		}, Theta)
 	}
 
@@ -473,8 +533,12 @@ func LinFitGOM(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 
 	theta := make([]float64, nFeatures*nOutputs)
 	thetaM := mat.NewDense(nFeatures, nOutputs, theta)
+	normFloat64 := rand.New(base.DefaultSource()).NormFloat64
+	if opts.RandomState != base.RandomState(nil) {
+		normFloat64 = rand.New(opts.RandomState).NormFloat64
+	}
 	for j := 0; j < len(theta); j++ {
-		theta[j] = 0.01 * rand.NormFloat64()
+		theta[j] = 0.01 * normFloat64()
 	}
 	var ret *optimize.Result
 	var err error
@@ -570,9 +634,18 @@ func (regr *LinearModel) setIntercept(XOffset, YOffset, XScale mat.Matrix) {
 }
 
 // DecisionFunction fills Y with X dot Coef+Intercept
+// X may be a *base.CSR, in which case the product is computed sparsely
 func (regr *LinearModel) DecisionFunction(X mat.Matrix, Ymutable mat.Mutable) {
 	Y := base.ToDense(Ymutable)
-	Y.Mul(X, regr.Coef)
+	if csr, ok := X.(*base.CSR); ok {
+		if Y.IsEmpty() {
+			*Y = *csr.MulDense(regr.Coef)
+		} else {
+			Y.Copy(csr.MulDense(regr.Coef))
+		}
+	} else {
+		Y.Mul(X, regr.Coef)
+	}
 	Y.Apply(func(j int, o int, v float64) float64 {
 
 		return v + regr.Intercept.At(0, o)
@@ -380,3 +380,82 @@ func _ExampleSetIntercept() {
 	// [10.00  10.00]
 
 }
+
+func TestLinearRegressionSparseInput(t *testing.T) {
+	// 3 categorical features with 5 categories each, one-hot encoded without first category
+	nSamples, nCategories := 200, 5
+	rnd := rand.New(base.NewSource(7))
+	Xdense := mat.NewDense(nSamples, 3*(nCategories-1), nil)
+	Y := mat.NewDense(nSamples, 2, nil)
+	for i := 0; i < nSamples; i++ {
+		for f := 0; f < 3; f++ {
+			if c := rnd.Intn(nCategories); c > 0 {
+				Xdense.Set(i, f*(nCategories-1)+c-1, 1)
+				Y.Set(i, 0, Y.At(i, 0)+float64(c*(f+1)))
+				Y.Set(i, 1, Y.At(i, 1)-float64(c))
+			}
+		}
+	}
+	Xsparse := base.CSRFromDense(Xdense)
+	if !mat.Equal(Xsparse, Xdense) {
+		t.Fatal("CSR differs from dense matrix")
+	}
+	dense, sparse := NewLinearRegression(), NewLinearRegression()
+	dense.Fit(Xdense, Y)
+	sparse.Fit(Xsparse, Y)
+	Ydense, Ysparse := dense.Predict(Xdense, nil), sparse.Predict(Xsparse, nil)
+	if !mat.EqualApprox(Ydense, Ysparse, 1e-10) {
+		t.Error("sparse and dense predictions differ")
+	}
+	if !mat.EqualApprox(Y, Ysparse, 1e-8) {
+		t.Error("expected exact fit")
+	}
+	if score := sparse.Score(Xsparse, Y); score < 1-1e-10 {
+		t.Errorf("expected R2=1, got %g", score)
+	}
+	for _, options := range []struct{ FitIntercept, Normalize bool }{{false, false}, {true, true}} {
+		dense.FitIntercept, dense.Normalize = options.FitIntercept, options.Normalize
+		sparse.FitIntercept, sparse.Normalize = options.FitIntercept, options.Normalize
+		dense.Fit(Xdense, Y)
+		sparse.Fit(Xsparse, Y)
+		if !mat.EqualApprox(dense.Predict(Xdense, nil), sparse.Predict(Xsparse, nil), 1e-8) {
+			t.Errorf("%+v: sparse and dense predictions differ", options)
+		}
+	}
+}
+
+func TestLinearRegressionLargeSparseInput(t *testing.T) {
+	// 500000 samples, 999 one-hot columns: 4GB if densified
+	nSamples, nCategories := 500000, 334
+	nFeatures := 3 * (nCategories - 1)
+	rnd := rand.New(base.NewSource(7))
+	effects := make([]float64, nFeatures)
+	for j := range effects {
+		effects[j] = rnd.NormFloat64()
+	}
+	indptr, indices, data := []int{0}, []int{}, []float64{}
+	Y := mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		y := 5.
+		for f := 0; f < 3; f++ {
+			if c := rnd.Intn(nCategories); c > 0 {
+				j := f*(nCategories-1) + c - 1
+				indices, data = append(indices, j), append(data, 1)
+				y += effects[j]
+			}
+		}
+		indptr = append(indptr, len(data))
+		Y.Set(i, 0, y)
+	}
+	X := base.NewCSR(nSamples, nFeatures, indptr, indices, data)
+	regr := NewLinearRegression()
+	regr.Fit(X, Y)
+	if math.Abs(regr.Intercept.At(0, 0)-5) > 1e-6 {
+		t.Errorf("expected intercept 5, got %g", regr.Intercept.At(0, 0))
+	}
+	for j, effect := range effects {
+		if math.Abs(regr.Coef.At(j, 0)-effect) > 1e-6 {
+			t.Errorf("feature %d: expected coef %g, got %g", j, effect, regr.Coef.At(j, 0))
+		}
+	}
+}
@@ -62,16 +62,15 @@ func enetCoordinateDescent(w *mat.VecDense, alpha, beta float64, X *mat.Dense, Y
 		return -1
 	}
 
+	if random && rng == nil {
+		rng = rand.New(base.DefaultSource())
+	}
 	for nIter = 0; nIter < maxIter; nIter++ {
 		wmax, dwmax = 0., 0.
 		var ii int
 		for fIter := 0; fIter < NFeatures; fIter++ {
 			if random {
-				if rng != nil {
-					ii = rng.Intn(NFeatures)
-				} else {
-					ii = rand.Intn(NFeatures)
-				}
+				ii = rng.Intn(NFeatures)
 			} else {
 				ii = fIter
 			}
@@ -197,16 +196,15 @@ func enetCoordinateDescentMultiTask(w *mat.Dense, l1reg, l2reg float64, X *mat.D
 	Y2.MulElem(Y, Y)
 	tol *= mat.Sum(Y2)
 
+	if random && rng == nil {
+		rng = rand.New(base.DefaultSource())
+	}
 	for nIter = 0; nIter < maxIter; nIter++ {
 		wmax, dwmax = 0., 0.
 
 		for fIter = 0; fIter < NFeatures; fIter++ {
 			if random {
-				if rng != nil {
-					ii = rng.Intn(NFeatures)
-				} else {
-					ii = rand.Intn(NFeatures)
-				}
+				ii = rng.Intn(NFeatures)
 			} else {
 				ii = fIter
 			}
@@ -1,7 +1,9 @@
 package linearmodel
 
 import (
+	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"sync"
 
@@ -174,3 +176,41 @@ func LassoPath(X, Y *mat.Dense, eps float64, NAlphas int, Alphas *[]float64, ver
 	alphas, coefs, dualGaps, nIters = EnetPath(X, Y, 1., eps, NAlphas, Alphas, verbose, positive)
 	return
 }
+
+// RidgePath returns the coefficients (one row per alpha, one column per feature) of ridge regressions of single output Y on X
+// minimizing ||y-Xw||² + alpha*||w||², fitted with an intercept by coordinate descent.
+// alphas are visited in decreasing order, each fit being warm-started from the previous solution
+func RidgePath(X, Y *mat.Dense, alphas []float64) *mat.Dense {
+	NSamples, _ := X.Dims()
+	return warmStartedPath("RidgePath", X, Y, alphas, 0, 1/float64(NSamples))
+}
+
+// LassoCoefPath is like RidgePath for lasso regressions with parameter alpha (see Lasso).
+// unlike LassoPath, it fits an intercept and warm-starts each alpha from the previous solution
+func LassoCoefPath(X, Y *mat.Dense, alphas []float64) *mat.Dense {
+	return warmStartedPath("LassoCoefPath", X, Y, alphas, 1, 1)
+}
+
+// warmStartedPath fits an ElasticNet for each alpha*alphaScale by decreasing alpha, reusing the coefficients of the previous fit
+func warmStartedPath(op string, X, Y *mat.Dense, alphas []float64, L1Ratio, alphaScale float64) *mat.Dense {
+	_, NFeatures := X.Dims()
+	if _, NOutputs := Y.Dims(); NOutputs != 1 {
+		panic(fmt.Errorf("%s: Y must have a single column, got %d", op, NOutputs))
+	}
+	order := make([]int, len(alphas))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return alphas[order[i]] > alphas[order[j]] })
+	coefs := mat.NewDense(len(alphas), NFeatures, nil)
+	m := NewElasticNet()
+	m.L1Ratio = L1Ratio
+	m.WarmStart = true
+	m.Coef = mat.NewDense(NFeatures, 1, nil)
+	for _, ialpha := range order {
+		m.Alpha = alphas[ialpha] * alphaScale
+		m.Fit(X, Y)
+		coefs.SetRow(ialpha, m.Coef.RawMatrix().Data)
+	}
+	return coefs
+}
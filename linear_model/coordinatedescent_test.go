@@ -7,8 +7,11 @@ import (
 	"math"
 	"os"
 	"os/exec"
+	"testing"
 	"time"
 
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -215,3 +218,41 @@ func ExampleLassoPath() {
 	// [0.474  0.235]
 
 }
+
+func TestRidgeLassoCoefPath(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 6, "n_informative": 6, "random_state": rand.New(base.NewSource(7))})
+	alphas := []float64{.01, .1, 1, 10, 100}
+	l1Norm := func(coefs *mat.Dense, i int) float64 { return mat.Norm(coefs.RowView(i), 1) }
+	zeros := func(coefs *mat.Dense, i int) (n int) {
+		for _, c := range coefs.RawRowView(i) {
+			if math.Abs(c) < 1e-8 {
+				n++
+			}
+		}
+		return
+	}
+	ridge, lasso := RidgePath(X, Y, alphas), LassoCoefPath(X, Y, alphas)
+	if r, c := lasso.Dims(); r != len(alphas) || c != 6 {
+		t.Fatalf("expected %dx6 coefs, got %dx%d", len(alphas), r, c)
+	}
+	for i := 1; i < len(alphas); i++ {
+		if l1Norm(ridge, i) >= l1Norm(ridge, i-1) {
+			t.Errorf("ridge coefs did not shrink from alpha %g to %g", alphas[i-1], alphas[i])
+		}
+		if l1Norm(lasso, i) > l1Norm(lasso, i-1) {
+			t.Errorf("lasso coefs did not shrink from alpha %g to %g", alphas[i-1], alphas[i])
+		}
+	}
+	earlier := false
+	for i := range alphas {
+		if zeros(ridge, i) > 0 {
+			t.Errorf("ridge zeroed a feature at alpha %g", alphas[i])
+		}
+		if zeros(lasso, i) > zeros(ridge, i) {
+			earlier = true
+		}
+	}
+	if !earlier || zeros(lasso, len(alphas)-1) != 6 {
+		t.Errorf("expected lasso to zero features, got %v", mat.Formatted(lasso))
+	}
+}
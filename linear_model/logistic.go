@@ -5,7 +5,6 @@ import (
 	"math"
 	"strings"
 	"sync"
-	"time"
 
 	"gonum.org/v1/gonum/floats"
 
@@ -36,6 +35,8 @@ type LogisticRegression struct {
 	Tol           float64          `json:"tol"`
 	Verbose       bool             `json:"verbose"`
 	NIterNoChange int              `json:"n_iter_no_change"`
+	// Epsilon clips probabilities to [Epsilon,1-Epsilon] in the loss. if 0, the smallest margin representable is used
+	Epsilon float64 `json:"epsilon"`
 
 	// Outputs
 	NLayers       int
@@ -65,7 +66,13 @@ var logregActivation = map[string]func(z blas64.General){
 	"logistic": func(z blas64.General) {
 		for row, zpos := 0, 0; row < z.Rows; row, zpos = row+1, zpos+z.Stride {
 			for col := 0; col < z.Cols; col++ {
-				z.Data[zpos+col] = 1 / (1 + math.Exp(-z.Data[zpos+col]))
+				// exp is taken of a non-positive value to avoid overflow
+				e := math.Exp(-math.Abs(z.Data[zpos+col]))
+				if z.Data[zpos+col] >= 0 {
+					z.Data[zpos+col] = 1 / (1 + e)
+				} else {
+					z.Data[zpos+col] = e / (1 + e)
+				}
 			}
 		}
 	},
@@ -80,10 +87,14 @@ var logregActivation = map[string]func(z blas64.General){
 	},
 	"softmax": func(z blas64.General) {
 		for row, zpos := 0, 0; row < z.Rows; row, zpos = row+1, zpos+z.Stride {
+			// subtract row max to avoid overflow
+			max := math.Inf(-1)
+			for col := 0; col < z.Cols; col++ {
+				max = math.Max(max, z.Data[zpos+col])
+			}
 			sum := float64(0)
 			for col := 0; col < z.Cols; col++ {
-
-				z.Data[zpos+col] = math.Exp(z.Data[zpos+col])
+				z.Data[zpos+col] = math.Exp(z.Data[zpos+col] - max)
 				sum += z.Data[zpos+col]
 			}
 			for col := 0; col < z.Cols; col++ {
@@ -93,11 +104,19 @@ var logregActivation = map[string]func(z blas64.General){
 	},
 }
 
-// logregLossFunctions is a map for loss functions
-var logregLossFunctions = map[string]func(y, h blas64.General) float64{
-	"log_loss": func(y, h blas64.General) float64 {
+// clipBounds returns the bounds probabilities are clipped to in loss functions
+func clipBounds(eps float64) (hmin, hmax float64) {
+	if eps > 0 {
+		return eps, 1 - eps
+	}
+	return math.Nextafter(0, 1), math.Nextafter(1, 0)
+}
+
+// logregLossFunctions is a map for loss functions. probabilities h are clipped to [eps,1-eps]
+var logregLossFunctions = map[string]func(y, h blas64.General, eps float64) float64{
+	"log_loss": func(y, h blas64.General, eps float64) float64 {
 		sum := float64(0)
-		hmin, hmax := math.Nextafter(0, 1), math.Nextafter(1, 0)
+		hmin, hmax := clipBounds(eps)
 		for row, hpos, ypos := 0, 0, 0; row < y.Rows; row, hpos, ypos = row+1, hpos+h.Stride, ypos+y.Stride {
 			for col := 0; col < y.Cols; col++ {
 				hval := h.Data[hpos+col]
@@ -113,9 +132,9 @@ var logregLossFunctions = map[string]func(y, h blas64.General) float64{
 		}
 		return sum / float64(h.Rows)
 	},
-	"binary_log_loss": func(y, h blas64.General) float64 {
+	"binary_log_loss": func(y, h blas64.General, eps float64) float64 {
 		sum := float64(0)
-		hmin, hmax := math.Nextafter(0, 1), math.Nextafter(1, 0)
+		hmin, hmax := clipBounds(eps)
 		for row, hpos, ypos := 0, 0, 0; row < y.Rows; row, hpos, ypos = row+1, hpos+h.Stride, ypos+y.Stride {
 			for col := 0; col < y.Cols; col++ {
 				hval := h.Data[hpos+col]
@@ -234,7 +253,7 @@ func (m *LogisticRegression) backprop(X, y blas64.General, activations []blas64.
 		lossFuncName = "binary_log_loss"
 	}
 	// y may have less rows than activations il last batch
-	loss := logregLossFunctions[lossFuncName](y, activations[len(activations)-1])
+	loss := logregLossFunctions[lossFuncName](y, activations[len(activations)-1], m.Epsilon)
 	// # Add L2 regularization term to loss
 	loss += (0.5 * m.Alpha) * m.sumCoefSquares() / float64(nSamples)
 
@@ -294,7 +313,7 @@ func (m *LogisticRegression) initialize(yCols int, layerUnits []int, isMultiClas
 
 	off = 0
 	if m.RandomState == (base.RandomState)(nil) {
-		m.RandomState = base.NewLockedSource(uint64(time.Now().UnixNano()))
+		m.RandomState = base.DefaultSource()
 	}
 	type Float64er interface {
 		Float64() float64
@@ -361,7 +380,7 @@ func (m *LogisticRegression) Fit(X, Y mat.Matrix) base.Fiter {
 	layerUnits = append(layerUnits, m.NOutputs)
 
 	if m.RandomState == nil {
-		m.RandomState = rand.New(base.NewLockedSource(uint64(time.Now().UnixNano())))
+		m.RandomState = base.DefaultSource()
 	}
 	m.initialize(y.Cols, layerUnits, y.Cols > 1)
 
@@ -8,10 +8,12 @@ import (
 	"math"
 	"os"
 	"os/exec"
+	"testing"
 	"time"
 
 	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/blas/blas64"
 	"gonum.org/v1/gonum/diff/fd"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/optimize"
@@ -144,3 +146,37 @@ func ExampleLogisticRegression() {
 	// Output:
 	// ok
 }
+
+func TestLogregActivationsStability(t *testing.T) {
+	netInputs := []float64{1000, -1000, 0, -1000, 1000, 999}
+	eps := 1e-15
+	hmin, hmax := clipBounds(eps)
+	y := blas64.General{Rows: 2, Cols: 3, Stride: 3, Data: []float64{0, 1, 0, 1, 0, 0}}
+	for _, activation := range []string{"logistic", "softmax"} {
+		z := blas64.General{Rows: 2, Cols: 3, Stride: 3, Data: append([]float64{}, netInputs...)}
+		logregActivation[activation](z)
+		for _, h := range z.Data {
+			if math.IsNaN(h) || h < 0 || h > 1 {
+				t.Errorf("%s: got %g", activation, h)
+			}
+			// probabilities used by the loss are in (0,1)
+			if h = math.Max(hmin, math.Min(hmax, h)); h <= 0 || h >= 1 {
+				t.Errorf("%s: clipped probability %g not in (0,1)", activation, h)
+			}
+		}
+		if activation == "softmax" {
+			if sum := z.Data[0] + z.Data[1] + z.Data[2]; math.Abs(sum-1) > 1e-12 {
+				t.Errorf("softmax: expected row sum 1, got %g", sum)
+			}
+			if z.Data[4] < .5 || z.Data[5] <= 0 {
+				t.Errorf("softmax: expected second row [%g %g %g] to favor col 1 and keep col 2", z.Data[3], z.Data[4], z.Data[5])
+			}
+		}
+		for lossName, loss := range logregLossFunctions {
+			// wrong predictions with probability 1 cost -log(eps) instead of Inf
+			if J := loss(y, z, eps); math.IsNaN(J) || math.IsInf(J, 0) || J > -math.Log(eps)*3 {
+				t.Errorf("%s %s: got loss %g", activation, lossName, J)
+			}
+		}
+	}
+}
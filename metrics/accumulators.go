@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// AccuracyAccumulator computes AccuracyScore over batches without concatenating them
+type AccuracyAccumulator struct {
+	Correct, Count float64
+}
+
+// Update adds the correctly predicted samples of a batch
+func (a *AccuracyAccumulator) Update(yTrue, yPred *mat.Dense) {
+	nSamples, _ := yTrue.Dims()
+	a.Correct += AccuracyScore(yTrue, yPred, false, nil)
+	a.Count += float64(nSamples)
+}
+
+// Result returns the accuracy over all batches seen so far
+func (a *AccuracyAccumulator) Result() float64 {
+	return a.Correct / a.Count
+}
+
+// MSEAccumulator computes MeanSquaredError (uniform average over outputs) over batches without concatenating them
+type MSEAccumulator struct {
+	SumSquares []float64
+	Count      float64
+}
+
+// Update adds the squared errors of a batch
+func (a *MSEAccumulator) Update(yTrue, yPred *mat.Dense) {
+	nSamples, nOutputs := yTrue.Dims()
+	if a.SumSquares == nil {
+		a.SumSquares = make([]float64, nOutputs)
+	}
+	for i := 0; i < nSamples; i++ {
+		for o := 0; o < nOutputs; o++ {
+			e := yPred.At(i, o) - yTrue.At(i, o)
+			a.SumSquares[o] += e * e
+		}
+	}
+	a.Count += float64(nSamples)
+}
+
+// Result returns the mean squared error over all batches seen so far
+func (a *MSEAccumulator) Result() float64 {
+	sum := 0.
+	for _, s := range a.SumSquares {
+		sum += s / a.Count
+	}
+	return sum / float64(len(a.SumSquares))
+}
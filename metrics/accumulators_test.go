@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestAccumulators(t *testing.T) {
+	rnd := rand.New(base.NewSource(7))
+	yTrue, yPred := mat.NewDense(50, 2, nil), mat.NewDense(50, 2, nil)
+	yTrue.Apply(func(_, _ int, _ float64) float64 { return float64(rnd.Intn(3)) }, yTrue)
+	yPred.Apply(func(_, _ int, _ float64) float64 { return float64(rnd.Intn(3)) }, yPred)
+	var acc AccuracyAccumulator
+	var mse MSEAccumulator
+	// 5 batches of unequal sizes
+	for _, bounds := range [][2]int{{0, 7}, {7, 20}, {20, 21}, {21, 40}, {40, 50}} {
+		yt, yp := yTrue.Slice(bounds[0], bounds[1], 0, 2).(*mat.Dense), yPred.Slice(bounds[0], bounds[1], 0, 2).(*mat.Dense)
+		acc.Update(yt, yp)
+		mse.Update(yt, yp)
+	}
+	if expected, actual := AccuracyScore(yTrue, yPred, true, nil), acc.Result(); math.Abs(expected-actual) > 1e-12 {
+		t.Errorf("expected accuracy %g, got %g", expected, actual)
+	}
+	if expected, actual := MeanSquaredError(yTrue, yPred, nil, "").At(0, 0), mse.Result(); math.Abs(expected-actual) > 1e-12 {
+		t.Errorf("expected mse %g, got %g", expected, actual)
+	}
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"gonum.org/v1/gonum/blas/blas32"
+	"gonum.org/v1/gonum/blas/blas64"
+)
+
+// R2Score32 is the R2Score of float32 yPred for yTrue, uniformly averaged over outputs.
+// for a constant output of yTrue, the score is 1 if yPred is exact and 0 otherwise
+func R2Score32(yTrue, yPred blas32.General) float32 {
+	var r2acc float32
+	for c := 0; c < yTrue.Cols; c++ {
+		var yTrueAvg, yNum, yDen float32
+		for r, ypos := 0, 0; r < yTrue.Rows; r, ypos = r+1, ypos+yTrue.Stride {
+			yTrueAvg += yTrue.Data[ypos+c]
+		}
+		yTrueAvg /= float32(yTrue.Rows)
+		for r, ypos, hpos := 0, 0, 0; r < yTrue.Rows; r, ypos, hpos = r+1, ypos+yTrue.Stride, hpos+yPred.Stride {
+			t := yPred.Data[hpos+c] - yTrue.Data[ypos+c]
+			yNum += t * t
+			t = yTrue.Data[ypos+c] - yTrueAvg
+			yDen += t * t
+		}
+		if yDen == 0 {
+			// constant yTrue: as scikit-learn, a perfect prediction scores 1 and others 0
+			if yNum == 0 {
+				r2acc++
+			}
+			continue
+		}
+		r2acc += 1 - yNum/yDen
+	}
+	return r2acc / float32(yTrue.Cols)
+}
+
+// R2Score64 is the float64 version of R2Score32
+func R2Score64(yTrue, yPred blas64.General) float64 {
+	var r2acc float64
+	for c := 0; c < yTrue.Cols; c++ {
+		var yTrueAvg, yNum, yDen float64
+		for r, ypos := 0, 0; r < yTrue.Rows; r, ypos = r+1, ypos+yTrue.Stride {
+			yTrueAvg += yTrue.Data[ypos+c]
+		}
+		yTrueAvg /= float64(yTrue.Rows)
+		for r, ypos, hpos := 0, 0, 0; r < yTrue.Rows; r, ypos, hpos = r+1, ypos+yTrue.Stride, hpos+yPred.Stride {
+			t := yPred.Data[hpos+c] - yTrue.Data[ypos+c]
+			yNum += t * t
+			t = yTrue.Data[ypos+c] - yTrueAvg
+			yDen += t * t
+		}
+		if yDen == 0 {
+			// constant yTrue: as scikit-learn, a perfect prediction scores 1 and others 0
+			if yNum == 0 {
+				r2acc++
+			}
+			continue
+		}
+		r2acc += 1 - yNum/yDen
+	}
+	return r2acc / float64(yTrue.Cols)
+}
+
+// AccuracyScore32 is the fraction of rows of float32 yPred exactly equal to the rows of yTrue
+func AccuracyScore32(yTrue, yPred blas32.General) float32 {
+	n := 0
+	for i, ypos, hpos := 0, 0, 0; i < yTrue.Rows; i, ypos, hpos = i+1, ypos+yTrue.Stride, hpos+yPred.Stride {
+		rowEq := true
+		for c := 0; c < yTrue.Cols; c++ {
+			rowEq = rowEq && yPred.Data[hpos+c] == yTrue.Data[ypos+c]
+		}
+		if rowEq {
+			n++
+		}
+	}
+	return float32(n) / float32(yTrue.Rows)
+}
+
+// AccuracyScore64 is the float64 version of AccuracyScore32
+func AccuracyScore64(yTrue, yPred blas64.General) float64 {
+	n := 0
+	for i, ypos, hpos := 0, 0, 0; i < yTrue.Rows; i, ypos, hpos = i+1, ypos+yTrue.Stride, hpos+yPred.Stride {
+		rowEq := true
+		for c := 0; c < yTrue.Cols; c++ {
+			rowEq = rowEq && yPred.Data[hpos+c] == yTrue.Data[ypos+c]
+		}
+		if rowEq {
+			n++
+		}
+	}
+	return float64(n) / float64(yTrue.Rows)
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/blas/blas32"
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestR2Score32(t *testing.T) {
+	for _, tc := range []struct {
+		yTrue, yPred []float32
+		expected     float64
+	}{
+		{[]float32{3, -0.5, 2, 7}, []float32{2.5, 0.0, 2, 8}, 0.948},
+		{[]float32{1, 2, 3}, []float32{1, 2, 3}, 1},
+		{[]float32{1, 2, 3}, []float32{2, 2, 2}, 0},
+		{[]float32{1, 2, 3}, []float32{3, 2, 1}, -3},
+	} {
+		n := len(tc.yTrue)
+		yTrue, yPred := blas32.General{Rows: n, Cols: 1, Stride: 1, Data: tc.yTrue}, blas32.General{Rows: n, Cols: 1, Stride: 1, Data: tc.yPred}
+		yTrue64, yPred64 := mat.NewDense(n, 1, nil), mat.NewDense(n, 1, nil)
+		for i := 0; i < n; i++ {
+			yTrue64.Set(i, 0, float64(tc.yTrue[i]))
+			yPred64.Set(i, 0, float64(tc.yPred[i]))
+		}
+		r2 := R2Score32(yTrue, yPred)
+		if math.Abs(float64(r2)-tc.expected) > 1e-3 {
+			t.Errorf("expected %g, got %g", tc.expected, r2)
+		}
+		if expected := R2Score(yTrue64, yPred64, nil, "").At(0, 0); math.Abs(float64(r2)-expected) > 1e-6 {
+			t.Errorf("expected R2Score %g, got %g", expected, r2)
+		}
+		if r2_64 := R2Score64(yTrue64.RawMatrix(), yPred64.RawMatrix()); math.Abs(float64(r2)-r2_64) > 1e-6 {
+			t.Errorf("expected R2Score64 %g, got %g", r2_64, r2)
+		}
+	}
+}
+
+func TestR2Score32ConstantTarget(t *testing.T) {
+	yTrue := blas32.General{Rows: 3, Cols: 2, Stride: 2, Data: []float32{1, 1, 2, 1, 3, 1}}
+	yPred := blas32.General{Rows: 3, Cols: 2, Stride: 2, Data: []float32{1, 1, 2, 1, 3, 1}}
+	if r2 := R2Score32(yTrue, yPred); r2 != 1 {
+		t.Errorf("expected 1 for exact predictions of a constant output, got %g", r2)
+	}
+	yPred.Data[1] = 2
+	if r2 := R2Score32(yTrue, yPred); r2 != .5 {
+		t.Errorf("expected .5, got %g", r2)
+	}
+	yTrue64 := blas64.General{Rows: 2, Cols: 1, Stride: 1, Data: []float64{4, 4}}
+	if r2 := R2Score64(yTrue64, blas64.General{Rows: 2, Cols: 1, Stride: 1, Data: []float64{4, 5}}); r2 != 0 || math.IsNaN(r2) {
+		t.Errorf("expected 0, got %g", r2)
+	}
+}
+
+func TestAccuracyScore32(t *testing.T) {
+	for _, tc := range []struct {
+		rows, cols   int
+		yTrue, yPred []float32
+	}{
+		{4, 1, []float32{0, 1, 2, 3}, []float32{0, 2, 1, 3}},
+		{2, 2, []float32{1, 1, 1, 1}, []float32{0, 1, 1, 1}},
+	} {
+		yTrue, yPred := blas32.General{Rows: tc.rows, Cols: tc.cols, Stride: tc.cols, Data: tc.yTrue}, blas32.General{Rows: tc.rows, Cols: tc.cols, Stride: tc.cols, Data: tc.yPred}
+		yTrue64, yPred64 := blas64.General{Rows: tc.rows, Cols: tc.cols, Stride: tc.cols, Data: make([]float64, len(tc.yTrue))}, blas64.General{Rows: tc.rows, Cols: tc.cols, Stride: tc.cols, Data: make([]float64, len(tc.yPred))}
+		for i := range tc.yTrue {
+			yTrue64.Data[i], yPred64.Data[i] = float64(tc.yTrue[i]), float64(tc.yPred[i])
+		}
+		accuracy := AccuracyScore32(yTrue, yPred)
+		if accuracy != .5 {
+			t.Errorf("expected .5, got %g", accuracy)
+		}
+		if expected := AccuracyScore(mat.NewDense(tc.rows, tc.cols, yTrue64.Data), mat.NewDense(tc.rows, tc.cols, yPred64.Data), true, nil); float64(accuracy) != expected {
+			t.Errorf("expected AccuracyScore %g, got %g", expected, accuracy)
+		}
+		if accuracy64 := AccuracyScore64(yTrue64, yPred64); float64(accuracy) != accuracy64 {
+			t.Errorf("expected AccuracyScore64 %g, got %g", accuracy64, accuracy)
+		}
+	}
+}
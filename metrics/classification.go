@@ -2,20 +2,36 @@ package metrics
 
 import (
 	"fmt"
+	"log"
+	"math"
+	"sort"
 
 	"github.com/pa-m/sklearn/preprocessing"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/stat"
 )
 
 // AccuracyScore reports (weighted) true values/nSamples
+// a sample is correctly predicted if all its outputs are equal.
+// if normalize is true, it returns the fraction (sum of weights of correctly predicted samples / sum of weights),
+// otherwise it returns the (weighted) count of correctly predicted samples.
+// sampleWeight may be nil (unit weights), a column (nSamples,1) or a row (1,nSamples)
 func AccuracyScore(Ytrue, Ypred mat.Matrix, normalize bool, sampleWeight *mat.Dense) float64 {
 	nSamples, nOutputs := Ytrue.Dims()
 	N, D := 0., 0.
 	w := 1.
+	weightAt := func(i int) float64 { return sampleWeight.At(i, 0) }
+	if sampleWeight != nil {
+		if r, c := sampleWeight.Dims(); r == 1 && c == nSamples {
+			weightAt = func(i int) float64 { return sampleWeight.At(0, i) }
+		} else if r != nSamples {
+			panic(fmt.Errorf("AccuracyScore: sampleWeight has dims %d,%d, expected %d weights", r, c, nSamples))
+		}
+	}
 	for i := 0; i < nSamples; i++ {
 		if sampleWeight != nil {
-			w = sampleWeight.At(i, 0)
+			w = weightAt(i)
 		}
 		var eq = true
 		for j := 0; j < nOutputs; j++ {
@@ -176,3 +192,199 @@ func internalConfusionMatrix(YTrue, YPred *mat.Dense, sampleWeight []float64) (*
 	}
 	return cm, yt, yp, le
 }
+
+// ClassLikelihoodRatios computes the positive and negative likelihood ratios of a binary classification
+// LR+ = sensitivity/(1-specificity), LR- = (1-sensitivity)/specificity
+// operate only on 1st Y column. positive class is 1, other values are negative.
+// like scikit-learn, undefined ratios (division by zero) are returned as NaN with a warning
+func ClassLikelihoodRatios(yTrue, yPred *mat.Dense) (lrPlus, lrMinus float64) {
+	var tp, fp, tn, fn float64
+	nSamples, _ := yTrue.Dims()
+	for i := 0; i < nSamples; i++ {
+		switch t, p := yTrue.At(i, 0) == 1, yPred.At(i, 0) == 1; {
+		case t && p:
+			tp++
+		case t:
+			fn++
+		case p:
+			fp++
+		default:
+			tn++
+		}
+	}
+	if tp+fn == 0 {
+		log.Println("Warning: ClassLikelihoodRatios: no positive samples in yTrue, likelihood ratios are undefined")
+		return math.NaN(), math.NaN()
+	}
+	// 1-sensitivity = fn/(tp+fn) and 1-specificity = fp/(tn+fp)
+	sensitivity, specificity := tp/(tp+fn), tn/(tn+fp)
+	if fp == 0 {
+		log.Println("Warning: ClassLikelihoodRatios: no false positives, lrPlus is undefined")
+		lrPlus = math.NaN()
+	} else {
+		lrPlus = sensitivity / (fp / (tn + fp))
+	}
+	if tn == 0 {
+		log.Println("Warning: ClassLikelihoodRatios: no true negatives, lrMinus is undefined")
+		lrMinus = math.NaN()
+	} else {
+		lrMinus = (fn / (tp + fn)) / specificity
+	}
+	return
+}
+
+// PerClassAccuracy returns, for each label, the fraction of samples of this true class predicted as this class
+// (recall of the class, the diagonal of the row-normalized confusion matrix). operate only on 1st Y column.
+// labels defaults to the sorted classes of yTrue. the accuracy of a label without true samples is NaN
+func PerClassAccuracy(yTrue, yPred *mat.Dense, labels []float64) map[float64]float64 {
+	nSamples, _ := yTrue.Dims()
+	support, correct := make(map[float64]float64), make(map[float64]float64)
+	for i := 0; i < nSamples; i++ {
+		t := yTrue.At(i, 0)
+		support[t]++
+		if yPred.At(i, 0) == t {
+			correct[t]++
+		}
+	}
+	if labels == nil {
+		for label := range support {
+			labels = append(labels, label)
+		}
+		sort.Float64s(labels)
+	}
+	accuracies := make(map[float64]float64, len(labels))
+	for _, label := range labels {
+		accuracies[label] = math.NaN()
+		if support[label] > 0 {
+			accuracies[label] = correct[label] / support[label]
+		}
+	}
+	return accuracies
+}
+
+// TuneThresholds sweeps decision thresholds on each column of Yproba and returns, for each column,
+// the threshold maximizing metric when predicting positive class for Yproba>=threshold.
+// Ytrue must be binarized (0/1) with the same shape as Yproba
+// metric is one of "f1", "balanced_accuracy". scores are the metric values at the returned thresholds
+func TuneThresholds(Ytrue, Yproba mat.Matrix, metric string) (thresholds, scores []float64) {
+	var score func(tp, fp, pos, neg float64) float64
+	switch metric {
+	case "f1":
+		score = func(tp, fp, pos, neg float64) float64 {
+			if tp == 0 {
+				return 0
+			}
+			return 2 * tp / (tp + fp + pos)
+		}
+	case "balanced_accuracy":
+		score = func(tp, fp, pos, neg float64) float64 {
+			tpr, tnr := 0., 0.
+			if pos > 0 {
+				tpr = tp / pos
+			}
+			if neg > 0 {
+				tnr = (neg - fp) / neg
+			}
+			return (tpr + tnr) / 2
+		}
+	default:
+		panic(fmt.Errorf("TuneThresholds: unknown metric %s", metric))
+	}
+	nSamples, nOutputs := Yproba.Dims()
+	thresholds, scores = make([]float64, nOutputs), make([]float64, nOutputs)
+	idx := make([]int, nSamples)
+	for o := 0; o < nOutputs; o++ {
+		pos := 0.
+		for i := range idx {
+			idx[i] = i
+			if Ytrue.At(i, o) == 1 {
+				pos++
+			}
+		}
+		neg := float64(nSamples) - pos
+		sort.Slice(idx, func(a, b int) bool { return Yproba.At(idx[a], o) > Yproba.At(idx[b], o) })
+		thresholds[o], scores[o] = math.Inf(1), math.Inf(-1)
+		tp, fp := 0., 0.
+		for ii, i := range idx {
+			if Ytrue.At(i, o) == 1 {
+				tp++
+			} else {
+				fp++
+			}
+			// evaluate only once all samples with the same probability are predicted positive
+			if ii+1 < nSamples && Yproba.At(idx[ii+1], o) == Yproba.At(i, o) {
+				continue
+			}
+			if s := score(tp, fp, pos, neg); s > scores[o] {
+				thresholds[o], scores[o] = Yproba.At(i, o), s
+			}
+		}
+	}
+	return
+}
+
+// PredictWithCosts returns, for each row of probas (one column per class, ie PredictProba), the index of the class
+// minimizing the expected cost. costMatrix[i][j] is the cost of predicting class j when true class is i, like ConfusionMatrix layout
+func PredictWithCosts(probas, costMatrix *mat.Dense) *mat.Dense {
+	nSamples, nClasses := probas.Dims()
+	if r, c := costMatrix.Dims(); r != nClasses || c != nClasses {
+		panic(fmt.Errorf("PredictWithCosts: costMatrix must be %dx%d, got %dx%d", nClasses, nClasses, r, c))
+	}
+	expectedCosts := &mat.Dense{}
+	expectedCosts.Mul(probas, costMatrix)
+	Ypred := mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		Ypred.Set(i, 0, float64(floats.MinIdx(expectedCosts.RawRowView(i))))
+	}
+	return Ypred
+}
+
+// CalibrationCurve computes reliability diagram points of a binary classifier from the 1st columns of yTrue and probPos.
+// positive class is 1, other values are negative. probPos are the predicted probabilities of positive class, in [0,1].
+// strategy is "uniform" for nBins bins of identical widths, or "quantile" for bins with the same number of samples.
+// like scikit-learn, empty bins are omitted. fractionOfPositives and meanPredicted have one value per non-empty bin
+func CalibrationCurve(yTrue, probPos *mat.Dense, nBins int, strategy string) (fractionOfPositives, meanPredicted []float64) {
+	nSamples, _ := yTrue.Dims()
+	if nBins < 1 {
+		panic(fmt.Errorf("CalibrationCurve: nBins must be >= 1, got %d", nBins))
+	}
+	probs := make([]float64, nSamples)
+	for i := range probs {
+		probs[i] = probPos.At(i, 0)
+		if probs[i] < 0 || probs[i] > 1 {
+			panic(fmt.Errorf("CalibrationCurve: probPos has values outside [0,1]: %g", probs[i]))
+		}
+	}
+	// inner bin edges
+	edges := make([]float64, nBins-1)
+	switch strategy {
+	case "uniform":
+		for b := range edges {
+			edges[b] = float64(b+1) / float64(nBins)
+		}
+	case "quantile":
+		sorted := append([]float64{}, probs...)
+		sort.Float64s(sorted)
+		for b := range edges {
+			edges[b] = stat.Quantile(float64(b+1)/float64(nBins), stat.LinInterp, sorted, nil)
+		}
+	default:
+		panic(fmt.Errorf("CalibrationCurve: unknown strategy %q, expected uniform or quantile", strategy))
+	}
+	sumTrue, sumProb, count := make([]float64, nBins), make([]float64, nBins), make([]float64, nBins)
+	for i, p := range probs {
+		b := sort.SearchFloat64s(edges, p)
+		if yTrue.At(i, 0) == 1 {
+			sumTrue[b]++
+		}
+		sumProb[b] += p
+		count[b]++
+	}
+	for b := range count {
+		if count[b] > 0 {
+			fractionOfPositives = append(fractionOfPositives, sumTrue[b]/count[b])
+			meanPredicted = append(meanPredicted, sumProb[b]/count[b])
+		}
+	}
+	return
+}
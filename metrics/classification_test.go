@@ -2,7 +2,11 @@ package metrics
 
 import (
 	"fmt"
+	"math"
+	"testing"
 
+	"github.com/pa-m/sklearn/base"
+	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -13,9 +17,16 @@ func ExampleAccuracyScore() {
 	Ypred, Ytrue := mat.NewDense(4, 1, []float64{0, 2, 1, 3}), mat.NewDense(4, 1, []float64{0, 1, 2, 3})
 	fmt.Println(AccuracyScore(Ytrue, Ypred, normalize, sampleWeight))
 	fmt.Println(AccuracyScore(mat.NewDense(2, 2, []float64{0, 1, 1, 1}), mat.NewDense(2, 2, []float64{1, 1, 1, 1}), normalize, sampleWeight))
+	// weighting correctly predicted samples 0 and 3 more heavily raises accuracy
+	sampleWeight = mat.NewDense(4, 1, []float64{3, 1, 1, 3})
+	fmt.Println(AccuracyScore(Ytrue, Ypred, normalize, sampleWeight))
+	// count of correctly predicted samples, weighted
+	fmt.Println(AccuracyScore(Ytrue, Ypred, false, mat.NewDense(1, 4, []float64{3, 1, 1, 3})))
 	// Output:
 	// 0.5
 	// 0.5
+	// 0.75
+	// 6
 }
 
 func ExampleConfusionMatrix() {
@@ -29,6 +40,19 @@ func ExampleConfusionMatrix() {
 	// ⎣1  0  2⎦
 }
 
+func ExampleClassLikelihoodRatios() {
+	// adapted from example in https://scikit-learn.org/stable/modules/generated/sklearn.metrics.class_likelihood_ratios.html
+	// sensitivity=1/2 specificity=2/3
+	YTrue := mat.NewDense(5, 1, []float64{0, 1, 0, 1, 0})
+	YPred := mat.NewDense(5, 1, []float64{1, 1, 0, 0, 0})
+	fmt.Println(ClassLikelihoodRatios(YTrue, YPred))
+	// no false positive: lrPlus is undefined
+	fmt.Println(ClassLikelihoodRatios(YTrue, YTrue))
+	// Output:
+	// 1.5 0.75
+	// NaN 0
+}
+
 func ExamplePrecisionScore() {
 	// adapted from example in https://github.com/scikit-learn/scikit-learn/blob/0.19.1/sklearn/metrics/classification.py
 	Ytrue, Ypred := mat.NewDense(6, 1, []float64{0, 1, 2, 0, 1, 2}), mat.NewDense(6, 1, []float64{0, 2, 1, 0, 0, 1})
@@ -99,3 +123,107 @@ func ExamplePrecisionRecallFScoreSupport() {
 	// weighted [0.22 0.33 0.27 0.00]
 
 }
+
+func ExampleTuneThresholds() {
+	Ytrue := mat.NewDense(6, 1, []float64{0, 0, 0, 0, 1, 1})
+	Yproba := mat.NewDense(6, 1, []float64{.1, .2, .3, .45, .4, .6})
+	thresholds, scores := TuneThresholds(Ytrue, Yproba, "f1")
+	fmt.Printf("f1: threshold %.2f score %.3f\n", thresholds[0], scores[0])
+	thresholds, scores = TuneThresholds(Ytrue, Yproba, "balanced_accuracy")
+	fmt.Printf("balanced_accuracy: threshold %.2f score %.3f\n", thresholds[0], scores[0])
+	// Output:
+	// f1: threshold 0.40 score 0.800
+	// balanced_accuracy: threshold 0.40 score 0.875
+}
+
+func ExampleCalibrationCurve() {
+	// adapted from example in https://scikit-learn.org/stable/modules/generated/sklearn.calibration.calibration_curve.html
+	yTrue := mat.NewDense(9, 1, []float64{0, 0, 0, 0, 1, 1, 1, 1, 1})
+	yProb := mat.NewDense(9, 1, []float64{.1, .2, .3, .4, .65, .7, .8, .9, 1})
+	fractionOfPositives, meanPredicted := CalibrationCurve(yTrue, yProb, 3, "uniform")
+	fmt.Printf("%.3f %.3f\n", fractionOfPositives, meanPredicted)
+	// Output:
+	// [0.000 0.500 1.000] [0.200 0.525 0.850]
+}
+
+func TestCalibrationCurve(t *testing.T) {
+	rnd := rand.New(base.NewSource(7))
+	const nSamples = 10000
+	yTrue, calibrated, overconfident := mat.NewDense(nSamples, 1, nil), mat.NewDense(nSamples, 1, nil), mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		p := rnd.Float64()
+		if rnd.Float64() < p {
+			yTrue.Set(i, 0, 1)
+		}
+		calibrated.Set(i, 0, p)
+		// push probabilities toward 0 and 1
+		overconfident.Set(i, 0, .5+.5*math.Copysign(math.Sqrt(math.Abs(2*p-1)), p-.5))
+	}
+	for _, strategy := range []string{"uniform", "quantile"} {
+		fraction, mean := CalibrationCurve(yTrue, calibrated, 10, strategy)
+		if len(fraction) != 10 {
+			t.Errorf("%s: expected 10 bins, got %d", strategy, len(fraction))
+		}
+		for b := range fraction {
+			if math.Abs(fraction[b]-mean[b]) > .05 {
+				t.Errorf("%s: calibrated predictor bin %d is off the diagonal: %.3f %.3f", strategy, b, fraction[b], mean[b])
+			}
+		}
+		fraction, mean = CalibrationCurve(yTrue, overconfident, 10, strategy)
+		maxDeviation := 0.
+		for b := range fraction {
+			maxDeviation = math.Max(maxDeviation, math.Abs(fraction[b]-mean[b]))
+		}
+		if maxDeviation < .1 {
+			t.Errorf("%s: expected overconfident predictor to deviate from the diagonal, got max deviation %.3f", strategy, maxDeviation)
+		}
+	}
+}
+
+func ExamplePredictWithCosts() {
+	probas := mat.NewDense(4, 2, []float64{.9, .1, .7, .3, .4, .6, .8, .2})
+	// zero cost for correct predictions, a false negative costs 5 times a false positive
+	costMatrix := mat.NewDense(2, 2, []float64{0, 1, 5, 0})
+	fmt.Println("argmax:", mat.Formatted(PredictWithCosts(probas, mat.NewDense(2, 2, []float64{0, 1, 1, 0})).T()))
+	fmt.Println("costs: ", mat.Formatted(PredictWithCosts(probas, costMatrix).T()))
+	// Output:
+	// argmax: [0  0  1  0]
+	// costs:  [0  1  1  1]
+}
+
+func TestPerClassAccuracy(t *testing.T) {
+	// imbalanced gaussian classes with means 0, 2, 4, predicted with class priors
+	rnd := rand.New(base.NewSource(7))
+	counts, means := []int{800, 150, 50}, []float64{0, 2, 4}
+	var yt, yp []float64
+	for class, count := range counts {
+		for i := 0; i < count; i++ {
+			x := means[class] + rnd.NormFloat64()
+			best, bestScore := 0, math.Inf(-1)
+			for c := range counts {
+				if score := math.Log(float64(counts[c])) - (x-means[c])*(x-means[c])/2; score > bestScore {
+					best, bestScore = c, score
+				}
+			}
+			yt, yp = append(yt, float64(class)), append(yp, float64(best))
+		}
+	}
+	yTrue, yPred := mat.NewDense(len(yt), 1, yt), mat.NewDense(len(yp), 1, yp)
+	accuracies := PerClassAccuracy(yTrue, yPred, nil)
+	if len(accuracies) != 3 {
+		t.Fatalf("expected 3 classes, got %v", accuracies)
+	}
+	if accuracies[2] >= accuracies[0] || accuracies[1] >= accuracies[0] {
+		t.Errorf("expected minority classes to have lower accuracy than majority class, got %v", accuracies)
+	}
+	// recall is the diagonal of the row-normalized confusion matrix
+	cm := ConfusionMatrix(yTrue, yPred, nil)
+	for c := 0; c < 3; c++ {
+		if expected := cm.At(c, c) / mat.Sum(cm.RowView(c)); math.Abs(accuracies[float64(c)]-expected) > 1e-12 {
+			t.Errorf("class %d: expected %g, got %g", c, expected, accuracies[float64(c)])
+		}
+	}
+	if a := PerClassAccuracy(yTrue, yPred, []float64{0, 3}); len(a) != 2 || !math.IsNaN(a[3]) {
+		t.Errorf("expected NaN for a label without samples, got %v", a)
+	}
+}
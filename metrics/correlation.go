@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// CorrelationMatrix returns the nFeatures x nFeatures Pearson correlation matrix of the columns of X.
+// entries involving a constant column are NaN
+func CorrelationMatrix(X *mat.Dense) *mat.Dense {
+	_, nFeatures := X.Dims()
+	corr := mat.NewSymDense(nFeatures, nil)
+	stat.CorrelationMatrix(corr, X, nil)
+	return mat.DenseCopyOf(corr)
+}
+
+// SpearmanCorrelation returns the Spearman rank correlation matrix of the columns of X,
+// ie the Pearson correlation of their ranks. tied values get their average rank
+func SpearmanCorrelation(X *mat.Dense) *mat.Dense {
+	nSamples, nFeatures := X.Dims()
+	ranks := mat.NewDense(nSamples, nFeatures, nil)
+	idx := make([]int, nSamples)
+	for j := 0; j < nFeatures; j++ {
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.Slice(idx, func(a, b int) bool { return X.At(idx[a], j) < X.At(idx[b], j) })
+		for start := 0; start < nSamples; {
+			end := start + 1
+			for end < nSamples && X.At(idx[end], j) == X.At(idx[start], j) {
+				end++
+			}
+			// ranks are 1-based
+			rank := float64(start+end+1) / 2
+			for _, i := range idx[start:end] {
+				ranks.Set(i, j, rank)
+			}
+			start = end
+		}
+	}
+	return CorrelationMatrix(ranks)
+}
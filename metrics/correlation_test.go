@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestCorrelationMatrix(t *testing.T) {
+	// column 1 is 2*column 0+1, column 2 is column 0 cubed, column 3 is unrelated
+	X := mat.NewDense(6, 4, nil)
+	for i, v := range []float64{-2, -1, 0, 1, 2, 3} {
+		X.SetRow(i, []float64{v, 2*v + 1, v * v * v, float64(i % 2)})
+	}
+	corr := CorrelationMatrix(X)
+	if r, c := corr.Dims(); r != 4 || c != 4 {
+		t.Fatalf("expected 4x4 matrix, got %dx%d", r, c)
+	}
+	for j := 0; j < 4; j++ {
+		if math.Abs(corr.At(j, j)-1) > 1e-12 {
+			t.Errorf("expected 1 on diagonal, got %g", corr.At(j, j))
+		}
+	}
+	if math.Abs(corr.At(0, 1)-1) > 1e-12 || corr.At(0, 1) != corr.At(1, 0) {
+		t.Errorf("expected correlation 1 of perfectly correlated columns, got %g and %g", corr.At(0, 1), corr.At(1, 0))
+	}
+	if corr.At(0, 2) >= 1-1e-6 {
+		t.Errorf("expected Pearson correlation <1 for a non linear relation, got %g", corr.At(0, 2))
+	}
+	spearman := SpearmanCorrelation(X)
+	if math.Abs(spearman.At(0, 1)-1) > 1e-12 || math.Abs(spearman.At(0, 2)-1) > 1e-12 {
+		t.Errorf("expected Spearman correlation 1 of monotonic columns, got %g and %g", spearman.At(0, 1), spearman.At(0, 2))
+	}
+	if math.Abs(spearman.At(0, 3)) >= 1 {
+		t.Errorf("expected Spearman correlation <1 for unrelated columns, got %g", spearman.At(0, 3))
+	}
+}
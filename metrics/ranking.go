@@ -71,8 +71,8 @@ func binaryClfCurve(Ytrue, Yscore *mat.Dense, posLabel float64, sampleWeight []f
 func ROCCurve(Ytrue, Yscore *mat.Dense, posLabel float64, sampleWeight []float64) (fpr, tpr, thresholds []float64) {
 	var tps, fps []float64
 	fps, tps, thresholds = binaryClfCurve(Ytrue, Yscore, posLabel, sampleWeight)
-	if len(tps) == 0 || fps[0] != 0. {
-		// Add an extra threshold position if necessary
+	if len(tps) == 0 || fps[0] != 0. || tps[0] != 0. {
+		// Add an extra threshold position to make sure that the curve starts at (0, 0)
 		fps = append([]float64{0.}, fps...)
 		tps = append([]float64{0.}, tps...)
 		thresholds = append([]float64{thresholds[0] + 1.}, thresholds...)
@@ -102,6 +102,26 @@ func ROCCurve(Ytrue, Yscore *mat.Dense, posLabel float64, sampleWeight []float64
 	return
 }
 
+// ROCCurvePerClass computes one ROC curve per column of Yscore, for plotting multiclass or multilabel scores.
+// Ytrue is either a binary indicator matrix with the columns of Yscore, or a single column of class indices
+// (class c being the positive label of column c)
+func ROCCurvePerClass(Ytrue, Yscore *mat.Dense, sampleWeight []float64) (fpr, tpr, thresholds [][]float64) {
+	nSamples, nClasses := Yscore.Dims()
+	_, yCols := Ytrue.Dims()
+	if yCols != 1 && yCols != nClasses {
+		panic(fmt.Errorf("ROCCurvePerClass: Ytrue has %d columns, expected 1 or %d", yCols, nClasses))
+	}
+	fpr, tpr, thresholds = make([][]float64, nClasses), make([][]float64, nClasses), make([][]float64, nClasses)
+	for c := 0; c < nClasses; c++ {
+		yt, posLabel := Ytrue, float64(c)
+		if yCols > 1 {
+			yt, posLabel = Ytrue.Slice(0, nSamples, c, c+1).(*mat.Dense), 1
+		}
+		fpr[c], tpr[c], thresholds[c] = ROCCurve(yt, Yscore.Slice(0, nSamples, c, c+1).(*mat.Dense), posLabel, sampleWeight)
+	}
+	return
+}
+
 // AUC Compute Area Under the Curve (AUC) using the trapezoidal rule
 func AUC(fpr, tpr []float64) float64 {
 	auc := 0.
@@ -220,3 +240,123 @@ func AveragePrecisionScore(Ytrue, Yscore *mat.Dense, average string, sampleWeigh
 	}
 	return averageBinaryScore(binaryUninterpolatedAveragePrecision, Ytrue, Yscore, average, sampleWeight)
 }
+
+// dcgRow returns the discounted cumulative gain of the k highest scored items of row i.
+// if ideal is true, items are ordered by true relevance instead of score
+func dcgRow(yTrue, yScore *mat.Dense, i, k int, ideal bool) float64 {
+	_, nItems := yTrue.Dims()
+	if k <= 0 || k > nItems {
+		k = nItems
+	}
+	order := make([]int, nItems)
+	for j := range order {
+		order[j] = j
+	}
+	ranking := yScore
+	if ideal {
+		ranking = yTrue
+	}
+	sort.SliceStable(order, func(a, b int) bool { return ranking.At(i, order[a]) > ranking.At(i, order[b]) })
+	dcg := 0.
+	for rank, j := range order[:k] {
+		dcg += yTrue.At(i, j) / math.Log2(float64(rank+2))
+	}
+	return dcg
+}
+
+// DCGScore compute Discounted Cumulative Gain
+// Sum the true scores ranked in the order induced by the predicted scores,
+// after applying a logarithmic discount.
+// y_true : array, shape = [n_samples, n_labels]
+// True relevance of each item for each query (row)
+// y_score : array, shape = [n_samples, n_labels]
+// Target scores, can either be probability estimates, confidence values,
+// or non-thresholded measure of decisions
+// k : only consider the k highest scores in the ranking. k<=0 or k>n_labels means all items
+// Returns the mean of per-row DCG
+func DCGScore(yTrue, yScore *mat.Dense, k int) float64 {
+	nSamples, _ := yTrue.Dims()
+	sum := 0.
+	for i := 0; i < nSamples; i++ {
+		sum += dcgRow(yTrue, yScore, i, k, false)
+	}
+	return sum / float64(nSamples)
+}
+
+// NDCGScore compute Normalized Discounted Cumulative Gain
+// per-row DCG is divided by the DCG of the ideal ordering (by true relevance),
+// so that scores are between 0 and 1. rows with an ideal DCG of 0 score 0.
+// parameters are the same as in DCGScore
+// Returns the mean of per-row NDCG
+func NDCGScore(yTrue, yScore *mat.Dense, k int) float64 {
+	nSamples, _ := yTrue.Dims()
+	sum := 0.
+	for i := 0; i < nSamples; i++ {
+		if idcg := dcgRow(yTrue, yScore, i, k, true); idcg > 0 {
+			sum += dcgRow(yTrue, yScore, i, k, false) / idcg
+		}
+	}
+	return sum / float64(nSamples)
+}
+
+// CoverageError computes how far we need to go through the ranked scores to cover all true labels.
+// yTrue is the binary indicator of relevant labels, yScore the target scores (ie PredictProba output).
+// ties are broken by counting all labels with a score greater or equal to the lowest scored relevant label.
+// rows without relevant labels have a coverage of 0.
+// Returns the mean of per-row coverage
+func CoverageError(yTrue, yScore *mat.Dense) float64 {
+	nSamples, nLabels := yTrue.Dims()
+	sum := 0.
+	for i := 0; i < nSamples; i++ {
+		minRelevant := math.Inf(1)
+		for j := 0; j < nLabels; j++ {
+			if yTrue.At(i, j) > 0 && yScore.At(i, j) < minRelevant {
+				minRelevant = yScore.At(i, j)
+			}
+		}
+		for j := 0; j < nLabels; j++ {
+			if yScore.At(i, j) >= minRelevant {
+				sum++
+			}
+		}
+	}
+	return sum / float64(nSamples)
+}
+
+// LabelRankingAveragePrecisionScore computes label ranking average precision (LRAP).
+// for each relevant label of a row, it is the ratio of relevant labels among the labels scored
+// greater or equal, averaged over relevant labels.
+// like in scikit-learn, rows where all or none of the labels are relevant score 1.
+// yTrue is the binary indicator of relevant labels, yScore the target scores (ie PredictProba output).
+// Returns the mean of per-row scores
+func LabelRankingAveragePrecisionScore(yTrue, yScore *mat.Dense) float64 {
+	nSamples, nLabels := yTrue.Dims()
+	sum := 0.
+	for i := 0; i < nSamples; i++ {
+		var relevant []int
+		for j := 0; j < nLabels; j++ {
+			if yTrue.At(i, j) > 0 {
+				relevant = append(relevant, j)
+			}
+		}
+		if len(relevant) == 0 || len(relevant) == nLabels {
+			sum++
+			continue
+		}
+		aux := 0.
+		for _, j := range relevant {
+			rank, L := 0., 0.
+			for k := 0; k < nLabels; k++ {
+				if yScore.At(i, k) >= yScore.At(i, j) {
+					rank++
+					if yTrue.At(i, k) > 0 {
+						L++
+					}
+				}
+			}
+			aux += L / rank
+		}
+		sum += aux / float64(len(relevant))
+	}
+	return sum / float64(nSamples)
+}
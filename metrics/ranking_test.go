@@ -2,6 +2,8 @@ package metrics
 
 import (
 	"fmt"
+	"math"
+	"testing"
 
 	"gonum.org/v1/gonum/mat"
 )
@@ -15,9 +17,9 @@ func ExampleROCCurve() {
 	fmt.Println("tpr:", tpr)
 	fmt.Println("thresholds:", thresholds)
 	// Output:
-	// fpr: [0 0.5 0.5 1]
-	// tpr: [0.5 0.5 1 1]
-	// thresholds: [0.8 0.4 0.35 0.1]
+	// fpr: [0 0 0.5 0.5 1]
+	// tpr: [0 0.5 0.5 1 1]
+	// thresholds: [1.8 0.8 0.4 0.35 0.1]
 }
 
 func ExampleAUC() {
@@ -83,3 +85,91 @@ func ExampleAveragePrecisionScore() {
 	// AveragePrecisionScore micro: 0.636
 
 }
+
+func ExampleNDCGScore() {
+	// adapted from https://scikit-learn.org/stable/modules/generated/sklearn.metrics.ndcg_score.html
+	trueRelevance := mat.NewDense(1, 5, []float64{10, 0, 0, 1, 5})
+	scores := mat.NewDense(1, 5, []float64{.1, .2, .3, 4, 70})
+	fmt.Printf("DCG: %.2f\n", DCGScore(trueRelevance, scores, 0))
+	fmt.Printf("NDCG: %.2f\n", NDCGScore(trueRelevance, scores, 0))
+	// hand computed NDCG@3: DCG@3=5/log2(2)+1/log2(3)+0/log2(4), IDCG@3=10/log2(2)+5/log2(3)+1/log2(4)
+	fmt.Printf("NDCG@3: %.4f\n", NDCGScore(trueRelevance, scores, 3))
+	fmt.Printf("NDCG@10: %.2f\n", NDCGScore(trueRelevance, scores, 10))
+	// Output:
+	// DCG: 9.50
+	// NDCG: 0.70
+	// NDCG@3: 0.4124
+	// NDCG@10: 0.70
+}
+
+func ExampleCoverageError() {
+	// adapted from https://scikit-learn.org/stable/modules/model_evaluation.html#coverage-error
+	yTrue := mat.NewDense(2, 3, []float64{1, 0, 0, 0, 0, 1})
+	yScore := mat.NewDense(2, 3, []float64{.75, .5, 1, 1, .2, .1})
+	fmt.Printf("%.2f\n", CoverageError(yTrue, yScore))
+	// with all-relevant and all-irrelevant rows
+	yTrue = mat.NewDense(4, 3, []float64{1, 0, 0, 0, 0, 1, 1, 1, 1, 0, 0, 0})
+	yScore = mat.NewDense(4, 3, []float64{.75, .5, 1, 1, .2, .1, .1, .2, .3, .3, .2, .1})
+	fmt.Printf("%.2f\n", CoverageError(yTrue, yScore))
+	// Output:
+	// 2.50
+	// 2.00
+}
+
+func ExampleLabelRankingAveragePrecisionScore() {
+	// adapted from https://scikit-learn.org/stable/modules/model_evaluation.html#label-ranking-average-precision
+	yTrue := mat.NewDense(2, 3, []float64{1, 0, 0, 0, 0, 1})
+	yScore := mat.NewDense(2, 3, []float64{.75, .5, 1, 1, .2, .1})
+	fmt.Printf("%.4f\n", LabelRankingAveragePrecisionScore(yTrue, yScore))
+	// with all-relevant and all-irrelevant rows, which score 1
+	yTrue = mat.NewDense(4, 3, []float64{1, 0, 0, 0, 0, 1, 1, 1, 1, 0, 0, 0})
+	yScore = mat.NewDense(4, 3, []float64{.75, .5, 1, 1, .2, .1, .1, .2, .3, .3, .2, .1})
+	fmt.Printf("%.4f\n", LabelRankingAveragePrecisionScore(yTrue, yScore))
+	// ties count as ranked before: rank of label 0 is 3
+	yTrue = mat.NewDense(1, 3, []float64{1, 0, 0})
+	yScore = mat.NewDense(1, 3, []float64{.5, .5, .5})
+	fmt.Printf("%.4f\n", LabelRankingAveragePrecisionScore(yTrue, yScore))
+	// Output:
+	// 0.4167
+	// 0.7083
+	// 0.3333
+}
+
+func TestROCCurvePerClass(t *testing.T) {
+	Ylabels := mat.NewDense(8, 1, []float64{0, 1, 2, 2, 1, 0, 0, 2})
+	Yscore := mat.NewDense(8, 3, []float64{
+		.7, .2, .1,
+		.3, .4, .3,
+		.1, .5, .4,
+		.2, .2, .6,
+		.3, .6, .1,
+		.4, .5, .1,
+		.5, .3, .2,
+		.3, .3, .4,
+	})
+	Yindicator := mat.NewDense(8, 3, nil)
+	for i := 0; i < 8; i++ {
+		Yindicator.Set(i, int(Ylabels.At(i, 0)), 1)
+	}
+	for _, Ytrue := range []*mat.Dense{Ylabels, Yindicator} {
+		fpr, tpr, thresholds := ROCCurvePerClass(Ytrue, Yscore, nil)
+		sum := 0.
+		for c := range fpr {
+			n := len(fpr[c])
+			if len(tpr[c]) != n || len(thresholds[c]) != n {
+				t.Fatalf("class %d: lengths differ", c)
+			}
+			if fpr[c][0] != 0 || tpr[c][0] != 0 || fpr[c][n-1] != 1 || tpr[c][n-1] != 1 {
+				t.Errorf("class %d: expected curve from (0,0) to (1,1), got fpr %g tpr %g", c, fpr[c], tpr[c])
+			}
+			auc := AUC(fpr[c], tpr[c])
+			if expected := ROCAUCScore(Yindicator.Slice(0, 8, c, c+1).(*mat.Dense), Yscore.Slice(0, 8, c, c+1).(*mat.Dense), "", nil); math.Abs(expected-auc) > 1e-12 {
+				t.Errorf("class %d: expected auc %g, got %g", c, expected, auc)
+			}
+			sum += auc
+		}
+		if expected := ROCAUCScore(Yindicator, Yscore, "macro", nil); math.Abs(expected-sum/3) > 1e-12 {
+			t.Errorf("expected macro auc %g, got %g", expected, sum/3)
+		}
+	}
+}
@@ -1,7 +1,7 @@
 package metrics
 
 import (
-	//	"fmt"
+	"fmt"
 	"math"
 
 	"github.com/pa-m/sklearn/base"
@@ -242,3 +242,59 @@ func MeanAbsoluteError(yTrue, yPred mat.Matrix, sampleWeight *mat.Dense, multiou
 		return mat.NewDense(1, 1, []float64{mat.Sum(tmp) / float64(nOutputs)})
 	}
 }
+
+// MeanTweedieDeviance returns the mean Tweedie deviance regression loss over all elements of yTrue and yPred.
+// power=0 is the squared error, power=1 the Poisson deviance and power=2 the Gamma deviance.
+// power in (0,1) is not supported. yPred must be >0 for power<0 or power>=1,
+// yTrue must be >=0 for 1<=power<2 and >0 for power>=2
+func MeanTweedieDeviance(yTrue, yPred *mat.Dense, power float64) float64 {
+	nSamples, nOutputs := yTrue.Dims()
+	if r, c := yPred.Dims(); r != nSamples || c != nOutputs {
+		panic(fmt.Errorf("yTrue and yPred dims mismatch: %d,%d != %d,%d", nSamples, nOutputs, r, c))
+	}
+	if power > 0 && power < 1 {
+		panic(fmt.Errorf("Tweedie deviance is only defined for power<=0 and power>=1, got %g", power))
+	}
+	dev := 0.
+	for i := 0; i < nSamples; i++ {
+		for j := 0; j < nOutputs; j++ {
+			y, mu := yTrue.At(i, j), yPred.At(i, j)
+			switch {
+			case power == 0:
+			case power < 1:
+				if mu <= 0 {
+					panic(fmt.Errorf("MeanTweedieDeviance with power=%g requires strictly positive yPred", power))
+				}
+			case power >= 2:
+				if mu <= 0 || y <= 0 {
+					panic(fmt.Errorf("MeanTweedieDeviance with power=%g requires strictly positive yTrue and yPred", power))
+				}
+			default:
+				if mu <= 0 || y < 0 {
+					panic(fmt.Errorf("MeanTweedieDeviance with power=%g requires strictly positive yPred and non-negative yTrue", power))
+				}
+			}
+			switch power {
+			case 0:
+				dev += (y - mu) * (y - mu)
+			case 1:
+				d := mu - y
+				if y > 0 {
+					d += y * math.Log(y/mu)
+				}
+				dev += 2 * d
+			case 2:
+				dev += 2 * (math.Log(mu/y) + y/mu - 1)
+			default:
+				dev += 2 * (math.Pow(math.Max(y, 0), 2-power)/((1-power)*(2-power)) - y*math.Pow(mu, 1-power)/(1-power) + math.Pow(mu, 2-power)/(2-power))
+			}
+		}
+	}
+	return dev / float64(nSamples*nOutputs)
+}
+
+// MeanPoissonDeviance is MeanTweedieDeviance with power=1
+func MeanPoissonDeviance(yTrue, yPred *mat.Dense) float64 { return MeanTweedieDeviance(yTrue, yPred, 1) }
+
+// MeanGammaDeviance is MeanTweedieDeviance with power=2
+func MeanGammaDeviance(yTrue, yPred *mat.Dense) float64 { return MeanTweedieDeviance(yTrue, yPred, 2) }
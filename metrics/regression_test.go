@@ -158,3 +158,34 @@ func TestMeanAbsoluteError(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestMeanTweedieDeviance(t *testing.T) {
+	// expected values from scikit-learn docs for mean_poisson_deviance, mean_gamma_deviance and mean_tweedie_deviance
+	yTrue := mat.NewDense(4, 1, []float64{2, 0, 1, 4})
+	yPred := mat.NewDense(4, 1, []float64{.5, .5, 2, 2})
+	for _, tc := range []struct {
+		power, expected float64
+	}{{0, 1.875}, {1, 1.4260151319598084}, {1.5, 1.7781745930520232}} {
+		if actual := MeanTweedieDeviance(yTrue, yPred, tc.power); math.Abs(tc.expected-actual) > 1e-12 {
+			t.Errorf("power=%g expected %g, got %g", tc.power, tc.expected, actual)
+		}
+	}
+	if actual := MeanPoissonDeviance(yTrue, yPred); math.Abs(1.4260151319598084-actual) > 1e-12 {
+		t.Errorf("expected Poisson deviance 1.4260, got %g", actual)
+	}
+	yTrue = mat.NewDense(4, 1, []float64{2, .5, 1, 4})
+	if actual := MeanGammaDeviance(yTrue, yPred); math.Abs(1.0568528194400546-actual) > 1e-12 {
+		t.Errorf("expected Gamma deviance 1.0568, got %g", actual)
+	}
+	expectPanic := func(name string, f func()) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: expected panic", name)
+			}
+		}()
+		f()
+	}
+	expectPanic("gamma yTrue=0", func() { MeanGammaDeviance(mat.NewDense(1, 1, []float64{0}), mat.NewDense(1, 1, []float64{1})) })
+	expectPanic("poisson yPred=0", func() { MeanPoissonDeviance(mat.NewDense(1, 1, []float64{1}), mat.NewDense(1, 1, []float64{0})) })
+	expectPanic("power=.5", func() { MeanTweedieDeviance(yTrue, yPred, .5) })
+}
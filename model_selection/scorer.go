@@ -0,0 +1,52 @@
+package modelselection
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Scorer is a func(Ytrue,Ypred) float64 returning a higher score when Ypred is better
+type Scorer = func(Ytrue, Ypred mat.Matrix) float64
+
+// Scorers are the predefined scorers usable by name with CrossValidateMulti (see GetScorers)
+var Scorers = map[string]Scorer{
+	"accuracy": func(Ytrue, Ypred mat.Matrix) float64 { return metrics.AccuracyScore(Ytrue, Ypred, true, nil) },
+	"f1_macro": func(Ytrue, Ypred mat.Matrix) float64 {
+		return metrics.F1Score(base.ToDense(Ytrue), base.ToDense(Ypred), "macro", nil)
+	},
+	"f1_micro": func(Ytrue, Ypred mat.Matrix) float64 {
+		return metrics.F1Score(base.ToDense(Ytrue), base.ToDense(Ypred), "micro", nil)
+	},
+	"f1_weighted": func(Ytrue, Ypred mat.Matrix) float64 {
+		return metrics.F1Score(base.ToDense(Ytrue), base.ToDense(Ypred), "weighted", nil)
+	},
+	"precision_macro": func(Ytrue, Ypred mat.Matrix) float64 {
+		return metrics.PrecisionScore(base.ToDense(Ytrue), base.ToDense(Ypred), "macro", nil)
+	},
+	"recall_macro": func(Ytrue, Ypred mat.Matrix) float64 {
+		return metrics.RecallScore(base.ToDense(Ytrue), base.ToDense(Ypred), "macro", nil)
+	},
+	"r2": func(Ytrue, Ypred mat.Matrix) float64 { return metrics.R2Score(Ytrue, Ypred, nil, "").At(0, 0) },
+	"neg_mean_squared_error": func(Ytrue, Ypred mat.Matrix) float64 {
+		return -metrics.MeanSquaredError(Ytrue, Ypred, nil, "").At(0, 0)
+	},
+	"neg_mean_absolute_error": func(Ytrue, Ypred mat.Matrix) float64 {
+		return -metrics.MeanAbsoluteError(Ytrue, Ypred, nil, "").At(0, 0)
+	},
+}
+
+// GetScorers returns the predefined Scorers for names. it panics for an unknown name
+func GetScorers(names ...string) map[string]Scorer {
+	scorers := make(map[string]Scorer, len(names))
+	for _, name := range names {
+		scorer, ok := Scorers[name]
+		if !ok {
+			panic(fmt.Errorf("unknown scorer %s", name))
+		}
+		scorers[name] = scorer
+	}
+	return scorers
+}
@@ -2,7 +2,9 @@ package modelselection
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/pa-m/sklearn/base"
@@ -44,10 +46,14 @@ func ParameterGrid(paramGrid map[string][]interface{}) (out []map[string]interfa
 // Estimator is the base estimator. it must implement base.Predicter
 // Scorer is a function  __returning a higher score when Ypred is better__
 // CV is a splitter (defaults to KFold)
+// Scoring, if set, replaces Scorer by several named scorers (see Scorers). all are stored in CVResults
+// under "score_"+name, and Refit names the one selecting BestParams (it may be omitted for a single scorer)
 type GridSearchCV struct {
 	Estimator          base.Predicter
 	ParamGrid          map[string][]interface{}
 	Scorer             func(Ytrue, Ypred mat.Matrix) float64
+	Scoring            map[string]Scorer
+	Refit              string
 	CV                 Splitter
 	Verbose            bool
 	NJobs              int
@@ -61,6 +67,9 @@ type GridSearchCV struct {
 	BestParams    map[string]interface{}
 	BestIndex     int
 	NOutputs      int
+
+	// paramArray, if set, is used instead of ParameterGrid(ParamGrid)
+	paramArray []map[string]interface{}
 }
 
 // PredicterClone ...
@@ -106,7 +115,10 @@ func (gscv *GridSearchCV) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	estCloner := gscv.Estimator
 	// get seed for all estimator clone
 
-	paramArray := ParameterGrid(gscv.ParamGrid)
+	paramArray := gscv.paramArray
+	if paramArray == nil {
+		paramArray = ParameterGrid(gscv.ParamGrid)
+	}
 
 	if gscv.RandomState == rand.Source(nil) {
 		gscv.RandomState = base.NewSource(0)
@@ -115,10 +127,28 @@ func (gscv *GridSearchCV) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 		gscv.CV = &KFold{NSplits: 3, Shuffle: true, RandomState: gscv.RandomState}
 	}
 	gscv.CVResults = make(map[string][]interface{})
-	for k := range gscv.ParamGrid {
-		gscv.CVResults[k] = make([]interface{}, len(paramArray))
+	for _, params := range paramArray {
+		for k := range params {
+			if _, ok := gscv.CVResults[k]; !ok {
+				gscv.CVResults[k] = make([]interface{}, len(paramArray))
+			}
+		}
 	}
 	gscv.CVResults["score"] = make([]interface{}, len(paramArray))
+	scorers, refit := gscv.Scoring, gscv.Refit
+	if scorers == nil {
+		scorers, refit = map[string]Scorer{"score": gscv.Scorer}, "score"
+	} else {
+		for name := range scorers {
+			if refit == "" && len(scorers) == 1 {
+				refit = name
+			}
+			gscv.CVResults["score_"+name] = make([]interface{}, len(paramArray))
+		}
+		if _, ok := scorers[refit]; !ok {
+			panic(fmt.Errorf("GridSearchCV: Refit %q is not a key of Scoring", refit))
+		}
+	}
 
 	type structIn struct {
 		index     int
@@ -126,11 +156,16 @@ func (gscv *GridSearchCV) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 		estimator base.Predicter
 		cv        Splitter
 		score     float64
+		scores    map[string]float64
 	}
 	dowork := func(sin *structIn) {
-		cvres := CrossValidate(sin.estimator, X, Y, nil, gscv.Scorer, sin.cv, gscv.NJobs)
-		sin.score = floats.Sum(cvres.TestScore) / float64(len(cvres.TestScore))
-		bestFold := bestIdx(cvres.TestScore)
+		cvres := CrossValidateMulti(sin.estimator, X, Y, nil, scorers, sin.cv, gscv.NJobs)
+		sin.scores = make(map[string]float64, len(scorers))
+		for name, scores := range cvres.TestScores {
+			sin.scores[name] = floats.Sum(scores) / float64(len(scores))
+		}
+		sin.score = sin.scores[refit]
+		bestFold := bestIdx(cvres.TestScores[refit])
 		sin.estimator = cvres.Estimator[bestFold]
 	}
 	gscv.BestIndex = -1
@@ -150,6 +185,11 @@ func (gscv *GridSearchCV) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 					gscv.CVResults[k][i] = v
 				}
 				gscv.CVResults["score"][i] = sin[i].score
+				if gscv.Scoring != nil {
+					for name, score := range sin[i].scores {
+						gscv.CVResults["score_"+name][i] = score
+					}
+				}
 			}
 		})
 		for i, sout := range sin {
@@ -180,6 +220,77 @@ func (gscv *GridSearchCV) Predict(X mat.Matrix, Y mat.Mutable) *mat.Dense {
 	return gscv.BestEstimator.(base.Predicter).Predict(X, Y)
 }
 
+// Distribution is a distribution of values for a parameter in RandomizedSearchCV
+type Distribution interface {
+	Sample(rnd *rand.Rand) interface{}
+}
+
+// Uniform is the uniform distribution of float64 in [Low,High)
+type Uniform struct{ Low, High float64 }
+
+// Sample returns a random float64 in [Low,High)
+func (d Uniform) Sample(rnd *rand.Rand) interface{} { return d.Low + (d.High-d.Low)*rnd.Float64() }
+
+// LogUniform is the distribution of float64 in [Low,High) whose log is uniform. Low must be >0
+type LogUniform struct{ Low, High float64 }
+
+// Sample returns a random float64 in [Low,High)
+func (d LogUniform) Sample(rnd *rand.Rand) interface{} {
+	lo, hi := math.Log(d.Low), math.Log(d.High)
+	return math.Exp(lo + (hi-lo)*rnd.Float64())
+}
+
+// Choice is the uniform distribution over its values
+type Choice []interface{}
+
+// Sample returns one of the values
+func (d Choice) Sample(rnd *rand.Rand) interface{} { return d[rnd.Intn(len(d))] }
+
+// RandomizedSearchCV is like GridSearchCV but evaluates NIter parameter combinations sampled from ParamDistributions
+// (using RandomState) instead of ParamGrid.
+type RandomizedSearchCV struct {
+	GridSearchCV
+	ParamDistributions map[string]Distribution
+	NIter              int
+}
+
+// PredicterClone ...
+func (rscv *RandomizedSearchCV) PredicterClone() base.Predicter {
+	if rscv == nil {
+		return nil
+	}
+	clone := *rscv
+	if sourceCloner, ok := clone.RandomState.(base.SourceCloner); ok && sourceCloner != base.SourceCloner(nil) {
+		clone.RandomState = sourceCloner.SourceClone()
+	}
+	return &clone
+}
+
+// Fit samples NIter (default 10) parameter combinations and cross validates Estimator for each of them
+func (rscv *RandomizedSearchCV) Fit(X, Y mat.Matrix) base.Fiter {
+	if rscv.RandomState == rand.Source(nil) {
+		rscv.RandomState = base.NewSource(0)
+	}
+	if rscv.NIter <= 0 {
+		rscv.NIter = 10
+	}
+	names := make([]string, 0, len(rscv.ParamDistributions))
+	for k := range rscv.ParamDistributions {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	rnd := rand.New(rscv.RandomState)
+	rscv.paramArray = make([]map[string]interface{}, rscv.NIter)
+	for i := range rscv.paramArray {
+		rscv.paramArray[i] = make(map[string]interface{})
+		for _, k := range names {
+			rscv.paramArray[i][k] = rscv.ParamDistributions[k].Sample(rnd)
+		}
+	}
+	rscv.GridSearchCV.Fit(X, Y)
+	return rscv
+}
+
 func getParam(estimator interface{}, k string) (v interface{}, ok bool) {
 	est := reflect.ValueOf(estimator)
 	est = reflect.Indirect(est)
@@ -221,6 +332,10 @@ func setParam(estimator base.Predicter, k string, v interface{}) {
 		field.Set(reflect.ValueOf(v))
 
 	case reflect.Interface:
+		if field.Type() == reflect.TypeOf((*base.RandomState)(nil)).Elem() {
+			// an int seed is converted to a new source
+			v = base.ToRandomState(v)
+		}
 		field.Set(reflect.ValueOf(v))
 	default:
 		field.Set(reflect.ValueOf(v))
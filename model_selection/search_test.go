@@ -122,7 +122,7 @@ func ExampleGridSearchCV() {
 
 	// Output:
 	//Alpha 0.0001
-	//WeightDecay 5e-08
+	//WeightDecay 1e-05
 
 }
 
@@ -144,4 +144,112 @@ func Test_setParam(t *testing.T) {
 	if alpha.(float64) != 1 {
 		t.Fail()
 	}
+	setParam(mlp, "RandomState", 7)
+	if _, ok := mlp.RandomState.(*base.LockedSource); !ok {
+		t.Errorf("expected an int RandomState to be converted to a *base.LockedSource, got %T", mlp.RandomState)
+	}
+}
+
+func TestRandomizedSearchCV(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	X, Y := preprocessing.NewStandardScaler().FitTransform(ds.X, ds.Y)
+
+	mlp := neuralnetwork.NewMLPClassifier([]int{}, "logistic", "adam", 0)
+	mlp.RandomState = base.NewLockedSource(7)
+	mlp.MaxIter = 20
+	rscv := &RandomizedSearchCV{
+		GridSearchCV: GridSearchCV{
+			Estimator:   mlp,
+			Scorer:      func(Ytrue, Ypred mat.Matrix) float64 { return metrics.AccuracyScore(Ytrue, Ypred, true, nil) },
+			CV:          &KFold{NSplits: 3, Shuffle: true, RandomState: base.NewLockedSource(7)},
+			RandomState: base.NewLockedSource(7),
+			NJobs:       -1,
+		},
+		ParamDistributions: map[string]Distribution{
+			"LearningRateInit": LogUniform{Low: 1e-4, High: 1e-1},
+			"Alpha":            Choice{1e-5, 1e-4, 1e-3},
+		},
+		NIter: 5,
+	}
+	rscv.Fit(X, Y)
+	for _, k := range []string{"LearningRateInit", "Alpha", "score"} {
+		if len(rscv.CVResults[k]) != 5 {
+			t.Errorf("expected 5 %s results, got %d", k, len(rscv.CVResults[k]))
+		}
+	}
+	for _, v := range rscv.CVResults["LearningRateInit"] {
+		if lr := v.(float64); lr < 1e-4 || lr >= 1e-1 {
+			t.Errorf("LearningRateInit %g out of range", lr)
+		}
+	}
+	if _, ok := rscv.BestParams["Alpha"]; !ok {
+		t.Errorf("expected Alpha in BestParams, got %v", rscv.BestParams)
+	}
+	if rscv.BestScore < .9 {
+		t.Errorf("expected BestScore >= .9, got %g", rscv.BestScore)
+	}
+	if _, ok := rscv.PredicterClone().(*RandomizedSearchCV); !ok {
+		t.Error("expected *RandomizedSearchCV clone")
+	}
+}
+
+// thresholdClassifier predicts 1 when the first feature is greater than Threshold
+type thresholdClassifier struct{ Threshold float64 }
+
+func (m *thresholdClassifier) Fit(X, Y mat.Matrix) base.Fiter { return m }
+func (m *thresholdClassifier) GetNOutputs() int               { return 1 }
+func (m *thresholdClassifier) Predict(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	nSamples, _ := X.Dims()
+	Ypred := mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		if X.At(i, 0) > m.Threshold {
+			Ypred.Set(i, 0, 1)
+		}
+	}
+	return base.FromDense(Y, Ypred)
+}
+func (m *thresholdClassifier) Score(X, Y mat.Matrix) float64 { return 0 }
+func (m *thresholdClassifier) IsClassifier() bool            { return true }
+func (m *thresholdClassifier) PredicterClone() base.Predicter {
+	clone := *m
+	return &clone
+}
+
+func TestGridSearchCVRefit(t *testing.T) {
+	// imbalanced overlapping classes: a high threshold favors the majority class, hence accuracy over f1_macro
+	rnd := rand.New(base.NewSource(7))
+	X, Y := mat.NewDense(200, 1, nil), mat.NewDense(200, 1, nil)
+	for i := 0; i < 200; i++ {
+		class := 0.
+		if i%8 == 0 {
+			class = 1
+		}
+		X.Set(i, 0, 1.5*class+rnd.NormFloat64())
+		Y.Set(i, 0, class)
+	}
+	gscv := &GridSearchCV{
+		Estimator: &thresholdClassifier{},
+		ParamGrid: map[string][]interface{}{"Threshold": {0., .5, 1., 1.5, 2., 3.}},
+		Scoring:   GetScorers("accuracy", "f1_macro"),
+		Refit:     "f1_macro",
+		CV:        &KFold{NSplits: 3, Shuffle: true, RandomState: base.NewLockedSource(7)},
+		NJobs:     1,
+	}
+	gscv.Fit(X, Y)
+	argmax := func(scores []interface{}) int {
+		best := 0
+		for i, score := range scores {
+			if score.(float64) > scores[best].(float64) {
+				best = i
+			}
+		}
+		return best
+	}
+	bestF1, bestAccuracy := argmax(gscv.CVResults["score_f1_macro"]), argmax(gscv.CVResults["score_accuracy"])
+	if gscv.BestIndex != bestF1 || gscv.BestScore != gscv.CVResults["score_f1_macro"][bestF1] {
+		t.Errorf("expected BestIndex %d maximizing f1_macro %v, got %d", bestF1, gscv.CVResults["score_f1_macro"], gscv.BestIndex)
+	}
+	if gscv.CVResults["Threshold"][bestAccuracy] == gscv.BestParams["Threshold"] {
+		t.Errorf("expected accuracy %v to select another Threshold than f1_macro %v", gscv.CVResults["score_accuracy"], gscv.CVResults["score_f1_macro"])
+	}
 }
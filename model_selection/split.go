@@ -1,7 +1,9 @@
 package modelselection
 
 import (
+	"fmt"
 	"math"
+	"sort"
 
 	"github.com/pa-m/sklearn/base"
 	"golang.org/x/exp/rand"
@@ -19,7 +21,9 @@ type KFold struct {
 }
 
 var (
-	_ Splitter = &KFold{}
+	_ Splitter      = &KFold{}
+	_ GroupSplitter = &GroupKFold{}
+	_ Splitter      = &RepeatedKFold{}
 )
 
 // Splitter is the interface for splitters like KFold
@@ -29,6 +33,13 @@ type Splitter interface {
 	SplitterClone() Splitter
 }
 
+// GroupSplitter is the interface for splitters using sample groups, like GroupKFold.
+// CrossValidate uses GroupSplit when groups are given
+type GroupSplitter interface {
+	Splitter
+	GroupSplit(X, Y *mat.Dense, groups []int) (ch chan Split)
+}
+
 // Split ...
 type Split struct{ TrainIndex, TestIndex []int }
 
@@ -55,8 +66,9 @@ func (splitter *KFold) Split(X, Y *mat.Dense) (ch chan Split) {
 		Shuffle(n int, swap func(i, j int))
 	}
 	type Intner interface{ Intn(int) int }
-	var rndShuffle = rand.Shuffle
-	var rndIntn = rand.Intn
+	defaultRnd := rand.New(base.DefaultSource())
+	var rndShuffle = defaultRnd.Shuffle
+	var rndIntn = defaultRnd.Intn
 
 	if splitter.RandomState != base.Source(nil) {
 		if shuffler, ok := splitter.RandomState.(Shuffler); ok {
@@ -110,6 +122,155 @@ func (splitter *KFold) GetNSplits(X, Y *mat.Dense) int {
 	return splitter.NSplits
 }
 
+// GroupKFold is a KFold variant with non-overlapping groups: samples of a group are all in the train set or all in the test set.
+// like in scikit-learn, groups are assigned to folds from the largest to the smallest, each to the fold having the fewest samples
+type GroupKFold struct {
+	NSplits int
+}
+
+// SplitterClone ...
+func (splitter *GroupKFold) SplitterClone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// Split panics as GroupKFold needs groups. use GroupSplit
+func (splitter *GroupKFold) Split(X, Y *mat.Dense) (ch chan Split) {
+	panic(fmt.Errorf("GroupKFold: groups are required, use GroupSplit"))
+}
+
+// GroupSplit generate Split structs. groups must have one value per sample
+func (splitter *GroupKFold) GroupSplit(X, Y *mat.Dense, groups []int) (ch chan Split) {
+	if splitter.NSplits <= 0 {
+		splitter.NSplits = 3
+	}
+	NSamples, _ := X.Dims()
+	if len(groups) != NSamples {
+		panic(fmt.Errorf("GroupKFold: expected %d groups, got %d", NSamples, len(groups)))
+	}
+	sizes := make(map[int]int)
+	for _, g := range groups {
+		sizes[g]++
+	}
+	if len(sizes) < splitter.NSplits {
+		panic(fmt.Errorf("GroupKFold: cannot have NSplits=%d greater than the number of groups: %d", splitter.NSplits, len(sizes)))
+	}
+	uniqueGroups := make([]int, 0, len(sizes))
+	for g := range sizes {
+		uniqueGroups = append(uniqueGroups, g)
+	}
+	sort.Slice(uniqueGroups, func(i, j int) bool {
+		gi, gj := uniqueGroups[i], uniqueGroups[j]
+		return sizes[gi] > sizes[gj] || (sizes[gi] == sizes[gj] && gi < gj)
+	})
+	foldOfGroup := make(map[int]int)
+	foldSizes := make([]int, splitter.NSplits)
+	for _, g := range uniqueGroups {
+		lightest := 0
+		for f, size := range foldSizes {
+			if size < foldSizes[lightest] {
+				lightest = f
+			}
+		}
+		foldOfGroup[g] = lightest
+		foldSizes[lightest] += sizes[g]
+	}
+
+	ch = make(chan Split)
+	go func() {
+		for isplit := 0; isplit < splitter.NSplits; isplit++ {
+			var sp Split
+			for i, g := range groups {
+				if foldOfGroup[g] == isplit {
+					sp.TestIndex = append(sp.TestIndex, i)
+				} else {
+					sp.TrainIndex = append(sp.TrainIndex, i)
+				}
+			}
+			ch <- sp
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+// GetNSplits for GroupKFold
+func (splitter *GroupKFold) GetNSplits(X, Y *mat.Dense) int {
+	if splitter.NSplits <= 0 {
+		splitter.NSplits = 3
+	}
+	return splitter.NSplits
+}
+
+// RepeatedKFold repeats NRepeats times a KFold with NSplits folds on a different permutation of samples.
+// test sets of the NSplits splits of a repeat are disjoint and cover all samples.
+// NSplits defaults to 5 and NRepeats to 10. RandomState may be nil to use the global random source
+type RepeatedKFold struct {
+	NSplits, NRepeats int
+	RandomState       base.RandomState
+}
+
+// SplitterClone ...
+func (splitter *RepeatedKFold) SplitterClone() Splitter {
+	if splitter == nil {
+		return nil
+	}
+	clone := *splitter
+	if sourceCloner, ok := clone.RandomState.(base.SourceCloner); ok && sourceCloner != base.SourceCloner(nil) {
+		clone.RandomState = sourceCloner.SourceClone()
+	}
+	return &clone
+}
+
+// Split generate NSplits*NRepeats Split structs
+func (splitter *RepeatedKFold) Split(X, Y *mat.Dense) (ch chan Split) {
+	if splitter.NSplits <= 0 {
+		splitter.NSplits = 5
+	}
+	if splitter.NRepeats <= 0 {
+		splitter.NRepeats = 10
+	}
+	NSamples, _ := X.Dims()
+	if splitter.NSplits > NSamples {
+		panic(fmt.Errorf("RepeatedKFold: cannot have NSplits=%d greater than the number of samples: %d", splitter.NSplits, NSamples))
+	}
+	var perm = rand.New(base.DefaultSource()).Perm
+	if splitter.RandomState != base.RandomState(nil) {
+		perm = rand.New(splitter.RandomState).Perm
+	}
+	ch = make(chan Split)
+	go func() {
+		for repeat := 0; repeat < splitter.NRepeats; repeat++ {
+			a := perm(NSamples)
+			start := 0
+			for isplit := 0; isplit < splitter.NSplits; isplit++ {
+				// the first NSamples%NSplits folds have one more sample
+				NTest := NSamples / splitter.NSplits
+				if isplit < NSamples%splitter.NSplits {
+					NTest++
+				}
+				sp := Split{TrainIndex: make([]int, 0, NSamples-NTest), TestIndex: a[start : start+NTest]}
+				sp.TrainIndex = append(append(sp.TrainIndex, a[:start]...), a[start+NTest:]...)
+				start += NTest
+				ch <- sp
+			}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+// GetNSplits for RepeatedKFold is NSplits*NRepeats
+func (splitter *RepeatedKFold) GetNSplits(X, Y *mat.Dense) int {
+	if splitter.NSplits <= 0 {
+		splitter.NSplits = 5
+	}
+	if splitter.NRepeats <= 0 {
+		splitter.NRepeats = 10
+	}
+	return splitter.NSplits * splitter.NRepeats
+}
+
 // TrainTestSplit splits X and Y into test set and train set
 // testsize must be between 0 and 1
 // it produce same sets than scikit-learn
@@ -2,6 +2,8 @@ package modelselection
 
 import (
 	"fmt"
+	"sort"
+	"testing"
 
 	"github.com/pa-m/sklearn/datasets"
 
@@ -117,3 +119,42 @@ func ExampleTrainTestSplit() {
 	//⎣4⎦
 
 }
+
+func TestRepeatedKFold(t *testing.T) {
+	X := mat.NewDense(10, 1, nil)
+	cv := &RepeatedKFold{NSplits: 3, NRepeats: 4, RandomState: base.NewSource(7)}
+	if n := cv.GetNSplits(X, nil); n != 12 {
+		t.Errorf("expected 12 splits, got %d", n)
+	}
+	var splits []Split
+	for sp := range cv.Split(X, nil) {
+		splits = append(splits, sp)
+	}
+	if len(splits) != 12 {
+		t.Fatalf("expected 12 splits, got %d", len(splits))
+	}
+	folds := make([]string, 4)
+	for repeat := 0; repeat < 4; repeat++ {
+		// test sets of a repeat partition the samples
+		var tests []int
+		for isplit, sp := range splits[repeat*3 : repeat*3+3] {
+			if len(sp.TrainIndex)+len(sp.TestIndex) != 10 || len(sp.TestIndex) != []int{4, 3, 3}[isplit] {
+				t.Errorf("repeat %d split %d: wrong sizes %d %d", repeat, isplit, len(sp.TrainIndex), len(sp.TestIndex))
+			}
+			tests = append(tests, sp.TestIndex...)
+			folds[repeat] += fmt.Sprint(sp.TestIndex)
+		}
+		sort.Ints(tests)
+		if fmt.Sprint(tests) != fmt.Sprint([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+			t.Errorf("repeat %d: test sets do not partition samples: %v", repeat, tests)
+		}
+	}
+	// repeats use different permutations
+	for i := range folds {
+		for j := 0; j < i; j++ {
+			if folds[i] == folds[j] {
+				t.Errorf("repeats %d and %d have the same folds %s", j, i, folds[i])
+			}
+		}
+	}
+}
@@ -1,17 +1,22 @@
 package modelselection
 
 import (
-	// "fmt"
+	"fmt"
 	"runtime"
+	"sort"
 	"time"
 
 	"github.com/pa-m/sklearn/base"
+	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
 )
 
 // CrossValidateResult is the struct result of CrossValidate. it includes TestScore,FitTime,ScoreTime,Estimator
+// TestScores holds per-scorer results of CrossValidateMulti. it is nil for CrossValidate
 type CrossValidateResult struct {
 	TestScore          []float64
+	TestScores         map[string][]float64
 	FitTime, ScoreTime []time.Duration
 	Estimator          []base.Predicter
 }
@@ -25,42 +30,70 @@ func (r CrossValidateResult) Less(i, j int) bool { return r.TestScore[j] < r.Tes
 // Swap  for CrossValidateResult to implement sort.Interface
 func (r CrossValidateResult) Swap(i, j int) {
 	r.TestScore[i], r.TestScore[j] = r.TestScore[j], r.TestScore[i]
+	for _, scores := range r.TestScores {
+		if &scores[0] != &r.TestScore[0] {
+			scores[i], scores[j] = scores[j], scores[i]
+		}
+	}
 	r.FitTime[i], r.FitTime[j] = r.FitTime[j], r.FitTime[i]
 	r.ScoreTime[i], r.ScoreTime[j] = r.ScoreTime[j], r.ScoreTime[i]
 	r.Estimator[i], r.Estimator[j] = r.Estimator[j], r.Estimator[i]
 }
 
 // CrossValidate Evaluate a score by cross-validation
+// groups (one per sample) are passed to GroupSplitter cv like GroupKFold. other splitters ignore them
 // scorer is a func(Ytrue,Ypred) float64
 // only mean_squared_error for now
 // NJobs is the number of goroutines. if <=0, runtime.NumCPU is used
 func CrossValidate(estimator base.Predicter, X, Y *mat.Dense, groups []int, scorer func(Ytrue, Ypred mat.Matrix) float64, cv Splitter, NJobs int) (res CrossValidateResult) {
+	res = crossValidate(estimator, X, Y, groups, map[string]Scorer{"score": scorer}, cv, NJobs)
+	res.TestScores = nil
+	return
+}
 
+// CrossValidateMulti is like CrossValidate but evaluates all scorers on the predictions of each fold.
+// scores are returned in TestScores, keyed like scorers. TestScore is the one of the first scorer name in alphabetical order.
+// see Scorers for predefined scorers. it panics if scorers is empty
+func CrossValidateMulti(estimator base.Predicter, X, Y *mat.Dense, groups []int, scorers map[string]Scorer, cv Splitter, NJobs int) (res CrossValidateResult) {
+	return crossValidate(estimator, X, Y, groups, scorers, cv, NJobs)
+}
+
+func crossValidate(estimator base.Predicter, X, Y *mat.Dense, groups []int, scorers map[string]Scorer, cv Splitter, NJobs int) (res CrossValidateResult) {
+	if len(scorers) == 0 {
+		panic(fmt.Errorf("CrossValidateMulti: scorers is empty"))
+	}
 	if NJobs <= 0 {
 		NJobs = runtime.NumCPU()
 	}
+	if cv == Splitter(nil) {
+		cv = &KFold{NSplits: 3, Shuffle: true}
+	}
 	NSplits := cv.GetNSplits(X, Y)
 	if NJobs > NSplits {
 		NJobs = NSplits
 	}
-	if cv == Splitter(nil) {
-		cv = &KFold{NSplits: 3, Shuffle: true}
+	split := cv.Split
+	if groupSplitter, ok := cv.(GroupSplitter); ok && groups != nil {
+		split = func(X, Y *mat.Dense) chan Split { return groupSplitter.GroupSplit(X, Y, groups) }
 	}
 	res.Estimator = make([]base.Predicter, NSplits)
-	res.TestScore = make([]float64, NSplits)
+	names := make([]string, 0, len(scorers))
+	res.TestScores = make(map[string][]float64)
+	for name := range scorers {
+		names = append(names, name)
+		res.TestScores[name] = make([]float64, NSplits)
+	}
+	sort.Strings(names)
+	res.TestScore = res.TestScores[names[0]]
 	res.FitTime = make([]time.Duration, NSplits)
 	res.ScoreTime = make([]time.Duration, NSplits)
 	type structIn struct {
 		iSplit int
 		Split
 	}
-	type structOut struct {
-		iSplit int
-		score  float64
-	}
 	NSamples, NFeatures := X.Dims()
 	_, NOutputs := Y.Dims()
-	processSplit := func(job int, Xjob, Yjob *mat.Dense, sin structIn) structOut {
+	processSplit := func(job int, Xjob, Yjob *mat.Dense, sin structIn) {
 		Xtrain, Xtest, Ytrain, Ytest := &mat.Dense{}, &mat.Dense{}, &mat.Dense{}, &mat.Dense{}
 		// fmt.Println("iSplit", sin.iSplit, "TrainIndex", sin.Split.TrainIndex[:10])
 		trainLen, testLen := len(sin.Split.TrainIndex), len(sin.Split.TestIndex)
@@ -84,32 +117,145 @@ func CrossValidate(estimator base.Predicter, X, Y *mat.Dense, groups []int, scor
 		t0 = time.Now()
 		Ypred := mat.NewDense(Xtest.RawMatrix().Rows, res.Estimator[sin.iSplit].GetNOutputs(), nil)
 		res.Estimator[sin.iSplit].Predict(Xtest, Ypred)
-		score := scorer(Ytest, Ypred)
+		for name, scorer := range scorers {
+			res.TestScores[name][sin.iSplit] = scorer(Ytest, Ypred)
+		}
 		res.ScoreTime[sin.iSplit] = time.Since(t0)
-		//fmt.Printf("score for split %d is %g\n", sin.iSplit, score)
-		return structOut{sin.iSplit, score}
-
 	}
 	if NJobs > 1 {
 		var sin = make([]structIn, 0, NSplits)
-		for split := range cv.Split(X, Y) {
-			sin = append(sin, structIn{iSplit: len(sin), Split: split})
+		for sp := range split(X, Y) {
+			sin = append(sin, structIn{iSplit: len(sin), Split: sp})
 		}
 		base.Parallelize(NJobs, NSplits, func(th, start, end int) {
 			var Xjob, Yjob = mat.NewDense(NSamples, NFeatures, nil), mat.NewDense(NSamples, NOutputs, nil)
 			for i := start; i < end; i++ {
-				sout := processSplit(th, Xjob, Yjob, sin[i])
-				res.TestScore[sout.iSplit] = sout.score
+				processSplit(th, Xjob, Yjob, sin[i])
 			}
 		})
 	} else { // NJobs==1
 		var Xjob, Yjob = mat.NewDense(NSamples, NFeatures, nil), mat.NewDense(NSamples, NOutputs, nil)
 		var isplit int
-		for split := range cv.Split(X, Y) {
-			sout := processSplit(0, Xjob, Yjob, structIn{iSplit: isplit, Split: split})
-			res.TestScore[sout.iSplit] = sout.score
+		for sp := range split(X, Y) {
+			processSplit(0, Xjob, Yjob, structIn{iSplit: isplit, Split: sp})
 			isplit++
 		}
 	}
 	return
 }
+
+// BootstrapConfidenceInterval resamples scores (ie CrossValidateResult.TestScore) with replacement nResamples times
+// and returns the mean of scores and the percentile interval of the resampled means at the given confidence level (ie 0.95).
+// randomState may be nil to use the global random source
+func BootstrapConfidenceInterval(scores []float64, nResamples int, confidence float64, randomState base.RandomState) (mean, low, high float64) {
+	if len(scores) == 0 || nResamples <= 0 {
+		panic(fmt.Errorf("BootstrapConfidenceInterval: need scores and nResamples>0, got %d scores and nResamples=%d", len(scores), nResamples))
+	}
+	if confidence <= 0 || confidence >= 1 {
+		panic(fmt.Errorf("BootstrapConfidenceInterval: confidence must be in ]0,1[, got %g", confidence))
+	}
+	var intn = rand.New(base.DefaultSource()).Intn
+	if randomState != base.RandomState(nil) {
+		intn = rand.New(randomState).Intn
+	}
+	mean = stat.Mean(scores, nil)
+	means := make([]float64, nResamples)
+	for r := range means {
+		sum := 0.
+		for range scores {
+			sum += scores[intn(len(scores))]
+		}
+		means[r] = sum / float64(len(scores))
+	}
+	sort.Float64s(means)
+	alpha := (1 - confidence) / 2
+	low = stat.Quantile(alpha, stat.LinInterp, means, nil)
+	high = stat.Quantile(1-alpha, stat.LinInterp, means, nil)
+	return
+}
+
+// PermutationTestScore evaluates the significance of a cross-validated score with permutations of Y rows.
+// score is the mean CrossValidate score on (X,Y), permutationScores are the mean scores for nPermutations random permutations of Y
+// and pvalue is (1+number of permutation scores >= score)/(1+nPermutations).
+// each cross-validation uses a clone of cv. randomState may be nil to use the global random source
+func PermutationTestScore(estimator base.Predicter, X, Y *mat.Dense, cv Splitter, scorer Scorer, nPermutations int, randomState base.RandomState) (score float64, permutationScores []float64, pvalue float64) {
+	if nPermutations <= 0 {
+		panic(fmt.Errorf("PermutationTestScore: nPermutations must be > 0, got %d", nPermutations))
+	}
+	if cv == Splitter(nil) {
+		cv = &KFold{NSplits: 3}
+	}
+	var perm = rand.New(base.DefaultSource()).Perm
+	if randomState != base.RandomState(nil) {
+		perm = rand.New(randomState).Perm
+	}
+	cvScore := func(Y *mat.Dense) float64 {
+		return stat.Mean(CrossValidate(estimator, X, Y, nil, scorer, cv.SplitterClone(), 0).TestScore, nil)
+	}
+	score = cvScore(Y)
+	nSamples, nOutputs := Y.Dims()
+	Yperm := mat.NewDense(nSamples, nOutputs, nil)
+	permutationScores = make([]float64, nPermutations)
+	count := 0
+	for p := range permutationScores {
+		for i, j := range perm(nSamples) {
+			Yperm.SetRow(i, Y.RawRowView(j))
+		}
+		permutationScores[p] = cvScore(Yperm)
+		if permutationScores[p] >= score {
+			count++
+		}
+	}
+	pvalue = float64(count+1) / float64(nPermutations+1)
+	return
+}
+
+// CrossValScore is like CrossValidate but returns only the test scores, using the predefined scorer named scoring (see Scorers).
+// it mirrors scikit-learn cross_val_score. it panics for an unknown scorer name
+func CrossValScore(estimator base.Predicter, X, Y *mat.Dense, cv Splitter, scoring string) []float64 {
+	return CrossValidate(estimator, X, Y, nil, GetScorers(scoring)[scoring], cv, 0).TestScore
+}
+
+// CrossValPredict returns for each sample the prediction of a clone of estimator fitted on the other folds of cv.
+// test sets of cv must cover all samples, as RepeatedKFold ones with NRepeats=1 (the default cv, with 3 folds) but not KFold ones.
+// predict, if not nil, replaces estimator.Predict(X, nil), ie to return class probabilities. it mirrors scikit-learn cross_val_predict
+func CrossValPredict(estimator base.Predicter, X, Y *mat.Dense, cv Splitter, predict func(estimator base.Predicter, X mat.Matrix) *mat.Dense) *mat.Dense {
+	if cv == Splitter(nil) {
+		cv = &RepeatedKFold{NSplits: 3, NRepeats: 1}
+	}
+	if predict == nil {
+		predict = func(estimator base.Predicter, X mat.Matrix) *mat.Dense { return estimator.Predict(X, nil) }
+	}
+	nSamples, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	var Ypred *mat.Dense
+	predicted := make([]bool, nSamples)
+	for sp := range cv.Split(X, Y) {
+		Xtrain, Ytrain := mat.NewDense(len(sp.TrainIndex), nFeatures, nil), mat.NewDense(len(sp.TrainIndex), nOutputs, nil)
+		for i0, i1 := range sp.TrainIndex {
+			Xtrain.SetRow(i0, X.RawRowView(i1))
+			Ytrain.SetRow(i0, Y.RawRowView(i1))
+		}
+		Xtest := mat.NewDense(len(sp.TestIndex), nFeatures, nil)
+		for i0, i1 := range sp.TestIndex {
+			Xtest.SetRow(i0, X.RawRowView(i1))
+		}
+		est := estimator.PredicterClone()
+		est.Fit(Xtrain, Ytrain)
+		Ytest := predict(est, Xtest)
+		if Ypred == nil {
+			_, nCols := Ytest.Dims()
+			Ypred = mat.NewDense(nSamples, nCols, nil)
+		}
+		for i0, i1 := range sp.TestIndex {
+			Ypred.SetRow(i1, Ytest.RawRowView(i0))
+			predicted[i1] = true
+		}
+	}
+	for i, ok := range predicted {
+		if !ok {
+			panic(fmt.Errorf("CrossValPredict: sample %d is in no test set of %T", i, cv))
+		}
+	}
+	return Ypred
+}
@@ -2,14 +2,19 @@ package modelselection
 
 import (
 	"fmt"
+	"math"
 	"sort"
+	"testing"
 
 	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/datasets"
 	linearModel "github.com/pa-m/sklearn/linear_model"
 	"github.com/pa-m/sklearn/metrics"
+	neuralnetwork "github.com/pa-m/sklearn/neural_network"
+	"github.com/pa-m/sklearn/preprocessing"
 	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
 )
 
 func ExampleCrossValidate() {
@@ -32,3 +37,204 @@ func ExampleCrossValidate() {
 	// [0.29391770 0.25681807 0.24695688]
 
 }
+
+func TestCrossValidateMulti(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	X, Y := preprocessing.NewStandardScaler().FitTransform(ds.X, ds.Y)
+	mlp := neuralnetwork.NewMLPClassifier([]int{}, "logistic", "adam", 0)
+	mlp.RandomState = base.NewLockedSource(7)
+	mlp.MaxIter = 100
+	NSplits := 3
+	cvresults := CrossValidateMulti(mlp, X, Y, nil, GetScorers("accuracy", "f1_macro"), &KFold{NSplits: NSplits, Shuffle: true, RandomState: base.NewLockedSource(7)}, -1)
+	for _, name := range []string{"accuracy", "f1_macro"} {
+		scores := cvresults.TestScores[name]
+		if len(scores) != NSplits {
+			t.Fatalf("expected %d %s scores, got %d", NSplits, name, len(scores))
+		}
+		for _, score := range scores {
+			if score < .8 || score > 1 {
+				t.Errorf("unexpected %s score %g", name, score)
+			}
+		}
+	}
+	if len(cvresults.Estimator) != NSplits {
+		t.Errorf("expected %d estimators, got %d", NSplits, len(cvresults.Estimator))
+	}
+	sort.Sort(cvresults)
+	for i := range cvresults.TestScore {
+		if cvresults.TestScore[i] != cvresults.TestScores["accuracy"][i] {
+			t.Errorf("expected TestScore to be accuracy")
+		}
+	}
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic for empty scorers")
+			}
+		}()
+		CrossValidateMulti(mlp, X, Y, nil, map[string]Scorer{}, &KFold{NSplits: NSplits}, 1)
+	}()
+}
+
+func TestBootstrapConfidenceInterval(t *testing.T) {
+	randomState := rand.New(base.NewSource(7))
+	foldScores := func(nFolds int) []float64 {
+		scores := make([]float64, nFolds)
+		for i := range scores {
+			scores[i] = .8 + .05*randomState.NormFloat64()
+		}
+		return scores
+	}
+	mean, low, high := BootstrapConfidenceInterval(foldScores(5), 1000, .95, base.NewSource(1))
+	if !(low < mean && mean < high) {
+		t.Errorf("interval [%g,%g] does not bracket mean %g", low, high, mean)
+	}
+	// more folds narrow the interval
+	_, low50, high50 := BootstrapConfidenceInterval(foldScores(50), 1000, .95, base.NewSource(1))
+	if high50-low50 >= high-low {
+		t.Errorf("expected narrower interval with more folds, got %g >= %g", high50-low50, high-low)
+	}
+	// a lower confidence narrows the interval
+	_, low80, high80 := BootstrapConfidenceInterval(foldScores(5), 1000, .8, base.NewSource(1))
+	if high80-low80 >= high-low {
+		t.Errorf("expected narrower interval with lower confidence, got %g >= %g", high80-low80, high-low)
+	}
+	// more resamples narrow the spread of the bounds between seeds
+	scores := foldScores(10)
+	boundsSpread := func(nResamples int) float64 {
+		lows := make([]float64, 20)
+		for seed := range lows {
+			_, lows[seed], _ = BootstrapConfidenceInterval(scores, nResamples, .95, base.NewSource(uint64(seed)))
+		}
+		return stat.StdDev(lows, nil)
+	}
+	if s20, s2000 := boundsSpread(20), boundsSpread(2000); s2000 >= s20 {
+		t.Errorf("expected stabler bounds with more resamples, got %g >= %g", s2000, s20)
+	}
+}
+
+// sampleRecorder records the sample indices (first column of X) it is fitted and evaluated on
+type sampleRecorder struct{ train, test []int }
+
+func (m *sampleRecorder) Fit(X, Y mat.Matrix) base.Fiter {
+	r, _ := X.Dims()
+	for i := 0; i < r; i++ {
+		m.train = append(m.train, int(X.At(i, 0)))
+	}
+	return m
+}
+func (m *sampleRecorder) GetNOutputs() int { return 1 }
+func (m *sampleRecorder) Predict(X mat.Matrix, Y mat.Mutable) *mat.Dense {
+	r, _ := X.Dims()
+	for i := 0; i < r; i++ {
+		m.test = append(m.test, int(X.At(i, 0)))
+	}
+	return base.FromDense(Y, mat.NewDense(r, 1, nil))
+}
+func (m *sampleRecorder) Score(X, Y mat.Matrix) float64 { return 0 }
+func (m *sampleRecorder) IsClassifier() bool            { return false }
+func (m *sampleRecorder) PredicterClone() base.Predicter {
+	return &sampleRecorder{}
+}
+
+func TestCrossValidateGroupKFold(t *testing.T) {
+	nSamples := 30
+	X, Y := mat.NewDense(nSamples, 1, nil), mat.NewDense(nSamples, 1, nil)
+	groups := make([]int, nSamples)
+	for i := range groups {
+		X.Set(i, 0, float64(i))
+		groups[i] = (i * i) % 7
+	}
+	scorer := func(Ytrue, Ypred mat.Matrix) float64 { return 0 }
+	res := CrossValidate(&sampleRecorder{}, X, Y, groups, scorer, &GroupKFold{NSplits: 3}, 2)
+	for fold, estimator := range res.Estimator {
+		rec := estimator.(*sampleRecorder)
+		if len(rec.train)+len(rec.test) != nSamples || len(rec.test) == 0 {
+			t.Errorf("fold %d: got %d train and %d test samples", fold, len(rec.train), len(rec.test))
+		}
+		trainGroups := make(map[int]bool)
+		for _, i := range rec.train {
+			trainGroups[groups[i]] = true
+		}
+		for _, i := range rec.test {
+			if trainGroups[groups[i]] {
+				t.Errorf("fold %d: group %d spans train and test", fold, groups[i])
+			}
+		}
+	}
+	// NSplits defaults to 3
+	if res = CrossValidate(&sampleRecorder{}, X, Y, groups, scorer, &GroupKFold{}, 2); len(res.TestScore) != 3 {
+		t.Errorf("expected 3 default splits, got %d", len(res.TestScore))
+	}
+}
+
+func TestPermutationTestScore(t *testing.T) {
+	rnd := rand.New(base.NewSource(7))
+	X, Ynoise, Ylinear := mat.NewDense(60, 2, nil), mat.NewDense(60, 1, nil), mat.NewDense(60, 1, nil)
+	for i := 0; i < 60; i++ {
+		X.Set(i, 0, rnd.NormFloat64())
+		X.Set(i, 1, rnd.NormFloat64())
+		Ynoise.Set(i, 0, rnd.NormFloat64())
+		Ylinear.Set(i, 0, 2*X.At(i, 0)-X.At(i, 1)+.1*rnd.NormFloat64())
+	}
+	cv := &KFold{NSplits: 3, Shuffle: true, RandomState: base.NewSource(7)}
+	score, permutationScores, pvalue := PermutationTestScore(linearModel.NewLinearRegression(), X, Ynoise, cv, Scorers["r2"], 30, base.NewSource(7))
+	if len(permutationScores) != 30 || pvalue < .1 {
+		t.Errorf("noise: expected high pvalue, got score %g pvalue %g", score, pvalue)
+	}
+	score, _, pvalue = PermutationTestScore(linearModel.NewLinearRegression(), X, Ylinear, cv, Scorers["r2"], 30, base.NewSource(7))
+	if score < .9 || pvalue > 1./31+1e-12 {
+		t.Errorf("linear: expected score > .9 and pvalue 1/31, got score %g pvalue %g", score, pvalue)
+	}
+}
+
+func TestCrossValScore(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 3, "random_state": rand.New(base.NewSource(7))})
+	cv := &KFold{NSplits: 4}
+	scores := CrossValScore(linearModel.NewLinearRegression(), X, Y, cv, "r2")
+	expected := CrossValidate(linearModel.NewLinearRegression(), X, Y, nil, Scorers["r2"], cv, 0).TestScore
+	if len(scores) != 4 {
+		t.Fatalf("expected 4 scores, got %d", len(scores))
+	}
+	for i := range scores {
+		if scores[i] != expected[i] {
+			t.Errorf("split %d: expected %g, got %g", i, expected[i], scores[i])
+		}
+	}
+}
+
+func TestCrossValPredict(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 3, "random_state": rand.New(base.NewSource(7))})
+	// noise makes the fitted coefficients depend on the training fold
+	rnd := rand.New(base.NewSource(7))
+	for i, y := range Y.RawMatrix().Data {
+		Y.Set(i, 0, y+.1*rnd.NormFloat64())
+	}
+	cv := &RepeatedKFold{NSplits: 4, NRepeats: 1, RandomState: base.NewLockedSource(7)}
+	Ypred := CrossValPredict(linearModel.NewLinearRegression(), X, Y, cv.SplitterClone(), nil)
+	if r, c := Ypred.Dims(); r != 100 || c != 1 {
+		t.Fatalf("expected 100x1 predictions, got %dx%d", r, c)
+	}
+	if r2 := metrics.R2Score(Y, Ypred, nil, "").At(0, 0); r2 < .9 {
+		t.Errorf("expected R2 >= .9, got %g", r2)
+	}
+	// each sample is predicted by the estimator fitted on the other folds
+	for sp := range cv.Split(X, Y) {
+		Xtrain, Ytrain, Xtest := mat.NewDense(len(sp.TrainIndex), 3, nil), mat.NewDense(len(sp.TrainIndex), 1, nil), mat.NewDense(len(sp.TestIndex), 3, nil)
+		for i0, i1 := range sp.TrainIndex {
+			Xtrain.SetRow(i0, X.RawRowView(i1))
+			Ytrain.SetRow(i0, Y.RawRowView(i1))
+		}
+		for i0, i1 := range sp.TestIndex {
+			Xtest.SetRow(i0, X.RawRowView(i1))
+		}
+		lr := linearModel.NewLinearRegression()
+		lr.Fit(Xtrain, Ytrain)
+		expected := lr.Predict(Xtest, nil)
+		for i0, i1 := range sp.TestIndex {
+			if math.Abs(expected.At(i0, 0)-Ypred.At(i1, 0)) > 1e-9 {
+				t.Errorf("sample %d: expected %g, got %g", i1, expected.At(i0, 0), Ypred.At(i1, 0))
+			}
+		}
+	}
+}
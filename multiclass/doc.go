@@ -0,0 +1,2 @@
+// Package multiclass contains meta-estimators turning binary classifiers into multiclass ones: OneVsRestClassifier, OneVsOneClassifier
+package multiclass
@@ -0,0 +1,108 @@
+package multiclass
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/mat"
+)
+
+// OneVsOneClassifier fits one clone of a binary Estimator per pair of classes, on the samples of those two classes.
+// it predicts the class with the most pairwise votes. ties are broken by the sum of pairwise positive scores.
+// only the first column of Y is used
+type OneVsOneClassifier struct {
+	Estimator base.Predicter
+	// Estimators are ordered by pairs (0,1),(0,2)...(0,n-1),(1,2)...
+	Estimators []base.Predicter
+	Classes    []float64
+}
+
+// NewOneVsOneClassifier returns a *OneVsOneClassifier
+func NewOneVsOneClassifier(estimator base.Predicter) *OneVsOneClassifier {
+	return &OneVsOneClassifier{Estimator: estimator}
+}
+
+// IsClassifier returns true for OneVsOneClassifier
+func (*OneVsOneClassifier) IsClassifier() bool { return true }
+
+// PredicterClone returns an unfitted clone
+func (m *OneVsOneClassifier) PredicterClone() base.Predicter {
+	return &OneVsOneClassifier{Estimator: m.Estimator.PredicterClone()}
+}
+
+// GetNOutputs returns 1
+func (*OneVsOneClassifier) GetNOutputs() int { return 1 }
+
+// Fit fits one estimator per pair of classes
+func (m *OneVsOneClassifier) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	m.Classes = uniqueLabels(Y)
+	nClasses := len(m.Classes)
+	if nClasses < 2 {
+		panic(fmt.Errorf("OneVsOneClassifier: need at least 2 classes, got %d", nClasses))
+	}
+	nSamples, nFeatures := X.Dims()
+	m.Estimators = make([]base.Predicter, 0, nClasses*(nClasses-1)/2)
+	for c0 := 0; c0 < nClasses; c0++ {
+		for c1 := c0 + 1; c1 < nClasses; c1++ {
+			var idx []int
+			for i := 0; i < nSamples; i++ {
+				if y := Y.At(i, 0); y == m.Classes[c0] || y == m.Classes[c1] {
+					idx = append(idx, i)
+				}
+			}
+			Xpair, Ypair := mat.NewDense(len(idx), nFeatures, nil), mat.NewDense(len(idx), 1, nil)
+			for ip, i := range idx {
+				Xpair.SetRow(ip, X.RawRowView(i))
+				if Y.At(i, 0) == m.Classes[c1] {
+					Ypair.Set(ip, 0, 1)
+				}
+			}
+			estimator := m.Estimator.PredicterClone()
+			estimator.Fit(Xpair, Ypair)
+			m.Estimators = append(m.Estimators, estimator)
+		}
+	}
+	return m
+}
+
+// Predict returns the class with the most pairwise votes for each sample
+func (m *OneVsOneClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	nSamples, _ := X.Dims()
+	nClasses := len(m.Classes)
+	votes, confidences := mat.NewDense(nSamples, nClasses, nil), mat.NewDense(nSamples, nClasses, nil)
+	pair := 0
+	for c0 := 0; c0 < nClasses; c0++ {
+		for c1 := c0 + 1; c1 < nClasses; c1++ {
+			scores := positiveScores(m.Estimators[pair], X)
+			for i, score := range scores {
+				winner := c0
+				if score >= .5 {
+					winner = c1
+				}
+				votes.Set(i, winner, votes.At(i, winner)+1)
+				confidences.Set(i, c1, confidences.At(i, c1)+score)
+				confidences.Set(i, c0, confidences.At(i, c0)+1-score)
+			}
+			pair++
+		}
+	}
+	Y := mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		v, c := votes.RawRowView(i), confidences.RawRowView(i)
+		best := 0
+		for k := range v {
+			if v[k] > v[best] || (v[k] == v[best] && c[k] > c[best]) {
+				best = k
+			}
+		}
+		Y.Set(i, 0, m.Classes[best])
+	}
+	return base.FromDense(Ymutable, Y)
+}
+
+// Score returns the accuracy
+func (m *OneVsOneClassifier) Score(X, Y mat.Matrix) float64 {
+	return metrics.AccuracyScore(Y, m.Predict(X, nil), true, nil)
+}
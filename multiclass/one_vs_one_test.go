@@ -0,0 +1,29 @@
+package multiclass
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	linearmodel "github.com/pa-m/sklearn/linear_model"
+	"github.com/pa-m/sklearn/preprocessing"
+)
+
+var _ base.Predicter = &OneVsOneClassifier{}
+
+func TestOneVsOneClassifier(t *testing.T) {
+	ds := datasets.LoadIris()
+	X, Y := preprocessing.NewStandardScaler().FitTransform(ds.X, ds.Y)
+	ovo := NewOneVsOneClassifier(linearmodel.NewLogisticRegression())
+	ovo.Fit(X, Y)
+	nClasses := len(ovo.Classes)
+	if nClasses != 3 || len(ovo.Estimators) != nClasses*(nClasses-1)/2 {
+		t.Fatalf("expected %d estimators for %d classes, got %d", nClasses*(nClasses-1)/2, nClasses, len(ovo.Estimators))
+	}
+	if accuracy := ovo.Score(X, Y); accuracy < .9 {
+		t.Errorf("expected accuracy >= .9, got %g", accuracy)
+	}
+	if clone := ovo.PredicterClone().(*OneVsOneClassifier); clone.Estimators != nil {
+		t.Error("expected unfitted clone")
+	}
+}
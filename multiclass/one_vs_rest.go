@@ -0,0 +1,158 @@
+package multiclass
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/mat"
+)
+
+// OneVsRestClassifier fits one clone of a binary Estimator per class, on labels binarized as class versus all others.
+// it predicts the class whose estimator has the highest score. only the first column of Y is used
+type OneVsRestClassifier struct {
+	Estimator  base.Predicter
+	Estimators []base.Predicter
+	Classes    []float64
+}
+
+// NewOneVsRestClassifier returns a *OneVsRestClassifier
+func NewOneVsRestClassifier(estimator base.Predicter) *OneVsRestClassifier {
+	return &OneVsRestClassifier{Estimator: estimator}
+}
+
+// IsClassifier returns true for OneVsRestClassifier
+func (*OneVsRestClassifier) IsClassifier() bool { return true }
+
+// PredicterClone returns an unfitted clone
+func (m *OneVsRestClassifier) PredicterClone() base.Predicter {
+	return &OneVsRestClassifier{Estimator: m.Estimator.PredicterClone()}
+}
+
+// GetNOutputs returns 1
+func (*OneVsRestClassifier) GetNOutputs() int { return 1 }
+
+// Fit fits one estimator per class
+func (m *OneVsRestClassifier) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	m.Classes = uniqueLabels(Y)
+	if len(m.Classes) < 2 {
+		panic(fmt.Errorf("OneVsRestClassifier: need at least 2 classes, got %d", len(m.Classes)))
+	}
+	nSamples, _ := X.Dims()
+	m.Estimators = make([]base.Predicter, len(m.Classes))
+	for c, class := range m.Classes {
+		Ybin := mat.NewDense(nSamples, 1, nil)
+		for i := 0; i < nSamples; i++ {
+			if Y.At(i, 0) == class {
+				Ybin.Set(i, 0, 1)
+			}
+		}
+		m.Estimators[c] = m.Estimator.PredicterClone()
+		m.Estimators[c].Fit(X, Ybin)
+	}
+	return m
+}
+
+// decisionFunction returns the positive class scores of each estimator (nSamples,nClasses)
+func (m *OneVsRestClassifier) decisionFunction(X mat.Matrix) *mat.Dense {
+	nSamples, _ := X.Dims()
+	scores := mat.NewDense(nSamples, len(m.Classes), nil)
+	for c, estimator := range m.Estimators {
+		scores.SetCol(c, positiveScores(estimator, X))
+	}
+	return scores
+}
+
+// PredictProba returns per-class scores normalized to sum to 1 for each sample
+func (m *OneVsRestClassifier) PredictProba(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	Y := m.decisionFunction(X)
+	normalizeRows(Y)
+	return base.FromDense(Ymutable, Y)
+}
+
+// Predict returns the class with the highest score for each sample
+func (m *OneVsRestClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	scores := m.decisionFunction(X)
+	nSamples, _ := X.Dims()
+	Y := mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		Y.Set(i, 0, m.Classes[argmax(scores.RawRowView(i))])
+	}
+	return base.FromDense(Ymutable, Y)
+}
+
+// Score returns the accuracy
+func (m *OneVsRestClassifier) Score(X, Y mat.Matrix) float64 {
+	return metrics.AccuracyScore(Y, m.Predict(X, nil), true, nil)
+}
+
+type probasPredicter interface {
+	PredictProbas(X mat.Matrix, Y mat.Mutable) *mat.Dense
+}
+
+type probaPredicter interface {
+	PredictProba(X mat.Matrix, Y mat.Mutable) *mat.Dense
+}
+
+// positiveScores returns the probability of positive class of a binary estimator if it has PredictProbas or PredictProba,
+// otherwise its predictions
+func positiveScores(estimator base.Predicter, X mat.Matrix) []float64 {
+	var Y *mat.Dense
+	switch e := estimator.(type) {
+	case probasPredicter:
+		Y = e.PredictProbas(X, nil)
+	case probaPredicter:
+		Y = e.PredictProba(X, nil)
+	default:
+		Y = estimator.Predict(X, nil)
+	}
+	_, nCols := Y.Dims()
+	// with two columns, the second one is the probability of positive class
+	return mat.Col(nil, nCols-1, Y)
+}
+
+// uniqueLabels returns the sorted unique values of Y first column
+func uniqueLabels(Y mat.Matrix) []float64 {
+	nSamples, _ := Y.Dims()
+	seen := make(map[float64]bool)
+	var classes []float64
+	for i := 0; i < nSamples; i++ {
+		if y := Y.At(i, 0); !seen[y] {
+			seen[y] = true
+			classes = append(classes, y)
+		}
+	}
+	sort.Float64s(classes)
+	return classes
+}
+
+// normalizeRows scales rows of Y to sum to 1. rows summing to 0 are set to uniform probabilities
+func normalizeRows(Y *mat.Dense) {
+	nSamples, nClasses := Y.Dims()
+	for i := 0; i < nSamples; i++ {
+		row := Y.RawRowView(i)
+		sum := 0.
+		for _, v := range row {
+			sum += v
+		}
+		for c := range row {
+			if sum > 0 {
+				row[c] /= sum
+			} else {
+				row[c] = 1 / float64(nClasses)
+			}
+		}
+	}
+}
+
+func argmax(row []float64) int {
+	best := 0
+	for c, v := range row {
+		if v > row[best] {
+			best = c
+		}
+	}
+	return best
+}
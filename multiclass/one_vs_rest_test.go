@@ -0,0 +1,44 @@
+package multiclass
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	linearmodel "github.com/pa-m/sklearn/linear_model"
+	nn "github.com/pa-m/sklearn/neural_network"
+	"github.com/pa-m/sklearn/preprocessing"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestOneVsRestClassifier(t *testing.T) {
+	ds := datasets.LoadIris()
+	X, Y := preprocessing.NewStandardScaler().FitTransform(ds.X, ds.Y)
+	ovr := NewOneVsRestClassifier(linearmodel.NewLogisticRegression())
+	ovr.Fit(X, Y)
+	if len(ovr.Estimators) != 3 || len(ovr.Classes) != 3 {
+		t.Fatalf("expected 3 estimators, got %d", len(ovr.Estimators))
+	}
+	mlp := nn.NewMLPClassifier([]int{}, "relu", "lbfgs", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 200
+	mlp.Fit(X, Y)
+	ovrAccuracy, mlpAccuracy := ovr.Score(X, Y), mlp.Score(X, Y)
+	if ovrAccuracy < .9 || ovrAccuracy < mlpAccuracy-.05 {
+		t.Errorf("OneVsRest accuracy %g, native multiclass MLP accuracy %g", ovrAccuracy, mlpAccuracy)
+	}
+	probas := ovr.PredictProba(X, nil)
+	nSamples, nClasses := probas.Dims()
+	if nClasses != 3 {
+		t.Fatalf("expected 3 probability columns, got %d", nClasses)
+	}
+	for i := 0; i < nSamples; i++ {
+		if sum := mat.Sum(probas.RowView(i)); math.Abs(sum-1) > 1e-12 {
+			t.Fatalf("sample %d probabilities sum to %g", i, sum)
+		}
+	}
+	if clone := ovr.PredicterClone().(*OneVsRestClassifier); clone.Estimators != nil {
+		t.Error("expected unfitted clone")
+	}
+}
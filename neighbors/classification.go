@@ -2,6 +2,7 @@ package neighbors
 
 import (
 	"fmt"
+	"math"
 	"runtime"
 	"sort"
 
@@ -17,10 +18,12 @@ import (
 type KNeighborsClassifier struct {
 	base.Predicter
 	NearestNeighbors
-	K        int
-	Weight   string
-	Scale    bool
-	Distance Distance
+	K      int
+	Weight string
+	// DistancePower is the exponent p of "distance" weights 1/d^p. it defaults to 1
+	DistancePower float64
+	Scale         bool
+	Distance      Distance
 	// Runtime members
 	Xscaled, Y *mat.Dense
 	Classes    [][]float64
@@ -32,6 +35,15 @@ func NewKNeighborsClassifier(K int, Weights string) *KNeighborsClassifier {
 	return &KNeighborsClassifier{NearestNeighbors: *NewNearestNeighbors(), K: K, Weight: Weights}
 }
 
+// PredicterClone return a (possibly unfitted) copy of predicter
+func (m *KNeighborsClassifier) PredicterClone() base.Predicter {
+	clone := *m
+	return &clone
+}
+
+// IsClassifier returns true for KNeighborsClassifier
+func (*KNeighborsClassifier) IsClassifier() bool { return true }
+
 // Fit ...
 func (m *KNeighborsClassifier) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
@@ -92,7 +104,6 @@ func (m *KNeighborsClassifier) _predict(X, Y *mat.Dense, wantProba bool) *KNeigh
 	distances, indices := m.KNeighbors(X, m.K)
 
 	base.Parallelize(NCPU, NX, func(th, start, end int) {
-		epsilon := 1e-15
 		weights := make([]float64, m.K)
 		sumweights := 0.
 		ys := make([]float64, m.K)
@@ -108,16 +119,10 @@ func (m *KNeighborsClassifier) _predict(X, Y *mat.Dense, wantProba bool) *KNeigh
 			for o := 0; o < outputs; o++ {
 				classw := make(map[float64]float64)
 				if isWeightDistance {
-					sumweights = 0.
+					sumweights = distanceWeights(weights, distances.RawRowView(sample), m.DistancePower)
 				}
 				for ik := range ys {
 					cl := m.Y.At(int(indices.At(sample, ik)), o)
-					if isWeightDistance {
-						dist := distances.At(sample, ik)
-						weights[ik] = 1. / (epsilon + dist)
-						sumweights += weights[ik]
-
-					}
 					if clw, present := classw[cl]; present {
 						classw[cl] = clw + weights[ik]
 					} else {
@@ -147,6 +152,31 @@ func (m *KNeighborsClassifier) _predict(X, Y *mat.Dense, wantProba bool) *KNeigh
 	return m
 }
 
+// distanceWeights sets weights to 1/d^power (power defaults to 1) and returns their sum.
+// if some distances are 0, these exact matches get weight 1 and other neighbors 0
+func distanceWeights(weights, distances []float64, power float64) (sum float64) {
+	if power == 0 {
+		power = 1
+	}
+	exactMatches := 0.
+	for ik, dist := range distances[:len(weights)] {
+		if dist == 0 {
+			exactMatches++
+		}
+		weights[ik] = math.Pow(dist, -power)
+	}
+	for ik, dist := range distances[:len(weights)] {
+		if exactMatches > 0 {
+			weights[ik] = 0
+			if dist == 0 {
+				weights[ik] = 1
+			}
+		}
+		sum += weights[ik]
+	}
+	return
+}
+
 // Score for KNeighborsClassifier
 func (m *KNeighborsClassifier) Score(X, Y mat.Matrix) float64 {
 	NSamples, NOutputs := Y.Dims()
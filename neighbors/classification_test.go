@@ -25,3 +25,22 @@ func ExampleKNeighborsClassifier() {
 	// [0]
 	// [0.66666667  0.33333333]
 }
+
+func ExampleKNeighborsClassifier_distancePower() {
+	// one sample of class 0 at 0, two samples of class 1 at 2 and 2.1
+	X := mat.NewDense(3, 1, []float64{0, 2, 2.1})
+	Y := mat.NewDense(3, 1, []float64{0, 1, 1})
+	Xtest := mat.NewDense(2, 1, []float64{.6, 2})
+	for _, p := range []float64{1, 2} {
+		neigh := NewKNeighborsClassifier(3, "distance")
+		neigh.DistancePower = p
+		neigh.Fit(X, Y)
+		Yprob := mat.NewDense(2, 2, nil)
+		neigh.PredictProba(Xtest, Yprob)
+		// closest neighbor of .6 is of class 0, exact match of 2 is of class 1
+		fmt.Printf("p=%g predict:%g probas:%.3f\n", p, mat.Col(nil, 0, neigh.Predict(Xtest, nil)), mat.Row(nil, 0, Yprob))
+	}
+	// Output:
+	// p=1 predict:[1 1] probas:[0.437 0.563]
+	// p=2 predict:[0 1] probas:[0.547 0.453]
+}
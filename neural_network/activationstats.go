@@ -0,0 +1,29 @@
+package neuralnetwork
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// ActivationStats are statistics of the activations of a hidden layer over a batch.
+// ZeroFraction is the fraction of zero activations (the dead unit rate for relu)
+type ActivationStats struct {
+	ZeroFraction, Mean, Std float64
+}
+
+// hiddenActivationStats returns ActivationStats of hidden layers of activations, as returned by Activations
+func hiddenActivationStats(activations []*mat.Dense) []ActivationStats {
+	stats := make([]ActivationStats, 0, len(activations)-2)
+	for _, a := range activations[1 : len(activations)-1] {
+		data := a.RawMatrix().Data
+		zeros := 0
+		for _, v := range data {
+			if v == 0 {
+				zeros++
+			}
+		}
+		mean, std := stat.MeanStdDev(data, nil)
+		stats = append(stats, ActivationStats{ZeroFraction: float64(zeros) / float64(len(data)), Mean: mean, Std: std})
+	}
+	return stats
+}
@@ -3,10 +3,13 @@ package neuralnetwork
 import (
 	"encoding/json"
 	"fmt"
+	"go/format"
+	"io"
 	"log"
 	"reflect"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,14 +17,21 @@ import (
 	"gonum.org/v1/gonum/blas/blas64"
 
 	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
 
 	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/blas"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat/distuv"
 )
 
 // BaseMultilayerPerceptron64 closely matches sklearn/neural_network/multilayer_perceptron.py
+// Alpha is the strength of the L2 penalty added to the loss: its gradient Alpha*W/nSamples is added to the loss gradient
+// (and normalized by adam like the data gradient).
+// WeightDecay is the decoupled (AdamW-style) weight decay of stochastic solvers: after each update, weights are shrunk
+// directly by learningRate*WeightDecay*W, independently of the gradient. learningRate is the current sgd learning rate,
+// or LearningRateInit for adam. WeightDecay is not used by lbfgs
 type BaseMultilayerPerceptron64 struct {
 	Activation         string  `json:"activation"`
 	Solver             string  `json:"solver"`
@@ -48,6 +58,67 @@ type BaseMultilayerPerceptron64 struct {
 	Beta2              float64          `json:"beta_2"`
 	Epsilon            float64          `json:"epsilon"`
 	NIterNoChange      int              `json:"n_iter_no_change"`
+	Standardize        bool             `json:"standardize"`
+	AccumulationSteps  int              `json:"accumulation_steps"`
+	// OutputActivation overrides the output layer activation, which defaults to identity for regression,
+	// logistic for binary/multilabel classification and softmax for multiclass classification.
+	// activations other than those defaults are supported with square_loss only
+	OutputActivation string `json:"output_activation"`
+	// RecordShuffleOrder makes stochastic solvers record in ShuffleOrders, for each epoch, the original indices of training samples
+	// in the order they were trained on. only the first maxShuffleOrders epochs are recorded
+	RecordShuffleOrder bool `json:"record_shuffle_order"`
+	// NInit is the number of trainings from different random initializations at Fit. the best one is kept
+	NInit int `json:"n_init"`
+	// OutputClip clamps regression predictions to [OutputClip[0],OutputClip[1]]. no clamping occurs unless OutputClip[0]<OutputClip[1]
+	OutputClip [2]float64 `json:"output_clip"`
+	// InputMask zeroes input columns whose mask is true before the forward pass, at training and prediction,
+	// so that masked features have no influence on the network
+	InputMask []bool `json:"input_mask"`
+	// LabelSmoothing replaces binarized classification targets by 1-LabelSmoothing for the true class and
+	// LabelSmoothing/(K-1) for the K-1 others (K=2 for binary and multilabel outputs) in loss and gradient. 0 disables it
+	LabelSmoothing float64 `json:"label_smoothing"`
+	// CycleLength is the number of epochs of the first cycle of the sgd "cosine" LearningRate schedule, which anneals the learning rate
+	// from LearningRateInit to LearningRateMin over each cycle, then restarts. each cycle is CycleMult (1 if 0) times longer than the previous one
+	CycleLength     int     `json:"cycle_length"`
+	CycleMult       float64 `json:"cycle_mult"`
+	LearningRateMin float64 `json:"learning_rate_min"`
+	// MonitorMetric, if set, replaces the default score (accuracy or R2) computed each epoch on the validation split
+	// for EarlyStopping and RestoreBestWeights. Ytrue and Ypred are binarized for classifiers
+	MonitorMetric func(Ytrue, Ypred mat.Matrix) float64 `json:"-"`
+	// RestoreBestWeights makes stochastic solvers evaluate the validation score each epoch on a ValidationFraction split
+	// (as EarlyStopping does) and restore the best scoring weights at the end of Fit, whatever the stopping reason
+	RestoreBestWeights bool `json:"restore_best_weights"`
+	// OutputLosses sets a loss per output column, for multi-task regressors: "square_loss" (or "square") with identity output activation,
+	// "binary_log_loss" (or "log") with logistic output activation for 0/1 targets, or "quantile:q" (ie "quantile:0.95"),
+	// the pinball loss of the q quantile (0<q<1) with identity output activation. the objective is the sum of the per-output losses
+	OutputLosses []string `json:"output_losses"`
+	// FreezeBatchNorm puts BatchNormalize in eval mode, ie for fine-tuning with WarmStart: activations are divided by the statistics
+	// stored at the last training batch instead of the statistics of the current batch, and stored statistics are not updated
+	FreezeBatchNorm bool `json:"freeze_batch_norm"`
+	// Dropout is the fraction of hidden units zeroed at random during training forward passes, survivors being scaled by 1/(1-Dropout).
+	// masks are drawn from RandomState. Predict uses all units; see PredictMCDropout for stochastic predictions
+	Dropout float64 `json:"dropout"`
+	// Mixup is the alpha parameter of mixup augmentation by stochastic solvers: each minibatch is replaced by blends
+	// lambda*x+(1-lambda)*x' of random pairs of its samples, and of their binarized targets, lambda being drawn from Beta(Mixup,Mixup).
+	// 0 disables it
+	Mixup float64 `json:"mixup"`
+	// RegularizeIntercept adds intercepts to the Alpha L2 penalty of loss and gradient. by default, as in scikit-learn,
+	// only Coefs are penalized. WeightDecay is not affected
+	RegularizeIntercept bool `json:"regularize_intercept"`
+	// StandardizeTarget makes regressors (square_loss) train on targets standardized with per-output YMean and YScale
+	// computed at Fit (kept with WarmStart and PartialFit). predictions are transformed back to original units
+	StandardizeTarget bool `json:"standardize_target"`
+	// TrainPatience and ValidationPatience are the numbers of epochs without improvement by Tol of the training loss,
+	// and of the validation score with EarlyStopping, after which stochastic solvers stop (or sgd adaptive learning rate decreases).
+	// 0 means NIterNoChange, except that with EarlyStopping training loss plateaus are ignored unless TrainPatience is set
+	TrainPatience      int `json:"train_patience"`
+	ValidationPatience int `json:"validation_patience"`
+	// Progress, if not nil, receives a ProgressEvent at the end of each iteration of Fit (each loss evaluation for lbfgs).
+	// events are sent without blocking and dropped when the channel is full. the channel is not closed
+	Progress chan<- ProgressEvent `json:"-"`
+	// TargetScore, if not 0, makes stochastic solvers stop as soon as the monitored score (MonitorMetric if set, else accuracy or R2)
+	// reaches it at the end of an epoch. the score is the last validation score if a validation split is used, the training score otherwise
+	TargetScore float64 `json:"target_score"`
 
 	// Outputs
 	NLayers       int
@@ -57,20 +128,36 @@ type BaseMultilayerPerceptron64 struct {
 	Coefs         []blas64General `json:"coefs_"`
 	OutActivation string          `json:"out_activation_"`
 	Loss          float64
+	XMean, XScale []float64     // per-feature statistics used when Standardize is set
+	YMean, YScale []float64     // per-output target statistics used when StandardizeTarget is set
+	Converged     bool          // false when the last Fit stopped because MaxIter was reached
+	FitTime       time.Duration // wall time of the last Fit
+	StopReason    string        // why the last Fit stopped before MaxIter, empty otherwise
 
 	// internal
 	t                   int
 	LossCurve           []float64
+	LayerGradNorms      [][]float64 // L2 norms of coefficient gradients of each layer (input layer first), appended at each iteration
 	ValidationScores    []float64
+	ShuffleOrders       [][]int
 	BestValidationScore float64
+	BestIter            int // iteration (1-based, as NIter) of BestValidationScore, whose weights are restored at the end of Fit
 	BestLoss            float64
 	NoImprovementCount  int
+	trainNoImproveCount int // epochs without training loss improvement, counted even with EarlyStopping
 	optimizer           Optimizer64
 	packedParameters    []float64
 	packedGrads         []float64 // packedGrads allow tests to check gradients
 	bestParameters      []float64
 	batchNorm           [][]float64
+	dropoutMasks        []blas64General
 	lb                  *LabelBinarizer64
+	// pretrained hidden layers weights set by Pretrain
+	pretrainedCoefs      []blas64General
+	pretrainedIntercepts [][]float64
+	// initial weights set by SetInitialWeights
+	initialCoefs      []blas64General
+	initialIntercepts [][]float64
 	// beforeMinimize allow test to set weights
 	beforeMinimize func(optimize.Problem, []float64)
 }
@@ -88,7 +175,7 @@ var Activations64 = map[string]func(z blas64General){
 	"tanh": func(z blas64General) {
 		for row, zpos := 0, 0; row < z.Rows; row, zpos = row+1, zpos+z.Stride {
 			for col := 0; col < z.Cols; col++ {
-				z.Data[zpos+col] = M64.Tanh(-z.Data[zpos+col])
+				z.Data[zpos+col] = M64.Tanh(z.Data[zpos+col])
 			}
 		}
 	},
@@ -256,9 +343,20 @@ func NewBaseMultilayerPerceptron64() *BaseMultilayerPerceptron64 {
 
 // forwardPass Perform a forward pass on the network by computing the values
 // of the neurons in the hidden layers and the output layer.
-//        activations : []blas64General, length = nLayers - 1
+//
+//	activations : []blas64General, length = nLayers - 1
 func (mlp *BaseMultilayerPerceptron64) forwardPass(activations []blas64General) {
+	mlp.forwardPassDropout(activations, nil)
+}
+
+// forwardPassDropout is forwardPass with dropout applied to hidden layers if masks is not nil.
+// masks (one per hidden layer, with the shape of its activations) are filled with the scale applied to each activation: 0 or 1/(1-Dropout)
+func (mlp *BaseMultilayerPerceptron64) forwardPassDropout(activations, masks []blas64General) {
 	hiddenActivation := Activations64[mlp.Activation]
+	var rndFloat64 func() float64
+	if masks != nil {
+		rndFloat64 = rand.New(mlp.RandomState).Float64
+	}
 	var i int
 	for i = 0; i < mlp.NLayers-1; i++ {
 		gemm64(blas.NoTrans, blas.NoTrans, 1, activations[i], mlp.Coefs[i], 0, activations[i+1])
@@ -266,26 +364,103 @@ func (mlp *BaseMultilayerPerceptron64) forwardPass(activations []blas64General)
 		// For the hidden layers
 		if (i + 1) != (mlp.NLayers - 1) {
 			hiddenActivation(activations[i+1])
+			if masks != nil {
+				for pos := range activations[i+1].Data {
+					masks[i].Data[pos] = 0
+					if rndFloat64() >= mlp.Dropout {
+						masks[i].Data[pos] = 1 / (1 - mlp.Dropout)
+					}
+					activations[i+1].Data[pos] *= masks[i].Data[pos]
+				}
+			}
 		}
 	}
 	i = mlp.NLayers - 2
 	// # For the last layer
+	if mlp.OutActivation == "mixed" {
+		for o := range mlp.OutputLosses {
+			Activations64[mlp.outputColumnActivation(o)](columnView64(activations[i+1], o))
+		}
+		return
+	}
 	outputActivation := Activations64[mlp.OutActivation]
 	outputActivation(activations[i+1])
 }
 
-// batchNormalize computes norms of activations and divides activations
+// outputColumnActivation returns the activation of output column o, given by OutputLosses[o] when OutActivation is mixed
+func (mlp *BaseMultilayerPerceptron64) outputColumnActivation(o int) string {
+	if mlp.OutActivation != "mixed" {
+		return mlp.OutActivation
+	}
+	if name, _ := outputLossName64(mlp.OutputLosses[o]); name == "binary_log_loss" {
+		return "logistic"
+	}
+	return "identity"
+}
+
+// outputLossName64 returns the loss function name of an OutputLosses item
+func outputLossName64(loss string) (string, bool) {
+	switch loss {
+	case "square", "square_loss":
+		return "square_loss", true
+	case "log", "binary_log_loss":
+		return "binary_log_loss", true
+	}
+	if _, ok := outputQuantile64(loss); ok {
+		return "quantile", true
+	}
+	return loss, false
+}
+
+// outputQuantile64 returns q for a "quantile:q" OutputLosses item with 0<q<1
+func outputQuantile64(loss string) (float64, bool) {
+	if !strings.HasPrefix(loss, "quantile:") {
+		return 0, false
+	}
+	q, err := strconv.ParseFloat(strings.TrimPrefix(loss, "quantile:"), 64)
+	if err != nil || q <= 0 || q >= 1 {
+		return 0, false
+	}
+	return float64(q), true
+}
+
+// pinballLoss64 is the mean quantile loss of h for y: q*(y-h) if y>h, (1-q)*(h-y) otherwise
+func pinballLoss64(y, h blas64General, q float64) float64 {
+	sum := float64(0)
+	for row, hpos, ypos := 0, 0, 0; row < y.Rows; row, hpos, ypos = row+1, hpos+h.Stride, ypos+y.Stride {
+		for col := 0; col < y.Cols; col++ {
+			if e := y.Data[ypos+col] - h.Data[hpos+col]; e > 0 {
+				sum += q * e
+			} else {
+				sum -= (1 - q) * e
+			}
+		}
+	}
+	return sum / float64(h.Rows)
+}
+
+// columnView64 returns a single column view of m
+func columnView64(m blas64General, col int) blas64General {
+	return blas64General{Rows: m.Rows, Cols: 1, Stride: m.Stride, Data: m.Data[col:]}
+}
+
+// batchNormalize computes norms of activations and divides activations.
+// if FreezeBatchNorm is set, stored norms are used and left unchanged
 func (mlp *BaseMultilayerPerceptron64) batchNormalize(activations []blas64General) {
 	for i := 0; i < mlp.NLayers-2; i++ {
 		activation := activations[i+1]
 		batchNorm := mlp.batchNorm[i]
 		for o := 0; o < activation.Cols; o++ {
 			M := float64(0)
-			// compute max for layer i, output o
-			for r, rpos := 0, 0; r < activation.Rows; r, rpos = r+1, rpos+activation.Stride {
-				a := M64.Abs(activation.Data[rpos+o])
-				if M < a {
-					M = a
+			if mlp.FreezeBatchNorm {
+				M = batchNorm[o]
+			} else {
+				// compute max for layer i, output o
+				for r, rpos := 0, 0; r < activation.Rows; r, rpos = r+1, rpos+activation.Stride {
+					a := M64.Abs(activation.Data[rpos+o])
+					if M < a {
+						M = a
+					}
 				}
 			}
 			// divide activation by max
@@ -315,6 +490,13 @@ func (mlp *BaseMultilayerPerceptron64) sumCoefSquares() float64 {
 			s += co * co
 		}
 	}
+	if mlp.RegularizeIntercept {
+		for _, b := range mlp.Intercepts {
+			for _, bo := range b {
+				s += bo * bo
+			}
+		}
+	}
 	return s
 }
 
@@ -328,6 +510,9 @@ func (mlp *BaseMultilayerPerceptron64) computeLossGrad(layer, NSamples int, acti
 	axpy64(len(coefGrads[layer].Data), mlp.Alpha/float64(NSamples), mlp.Coefs[layer].Data, coefGrads[layer].Data)
 	// interceptGrads[layer] = np.mean(deltas[layer], 0)
 	matRowMean64(deltas[layer], interceptGrads[layer])
+	if mlp.RegularizeIntercept {
+		axpy64(len(interceptGrads[layer]), mlp.Alpha/float64(NSamples), mlp.Intercepts[layer], interceptGrads[layer])
+	}
 }
 
 // backprop Compute the MLP loss function and its corresponding derivatives with respect to each parameter: weights and bias vectors.
@@ -339,25 +524,28 @@ func (mlp *BaseMultilayerPerceptron64) computeLossGrad(layer, NSamples int, acti
 // interceptGrads : [][]float64, length=NLayers-1
 
 func (mlp *BaseMultilayerPerceptron64) backprop(X, y blas64General, activations, deltas, coefGrads []blas64General, interceptGrads [][]float64) float64 {
-	nSamples := X.Rows
-	if mlp.WeightDecay > 0 {
-		for iw := range mlp.packedParameters {
-			mlp.packedParameters[iw] *= (1 - mlp.WeightDecay)
-		}
+	var masks []blas64General
+	if mlp.Dropout > 0 {
+		masks = mlp.allocDropoutMasks(activations)
 	}
-	mlp.forwardPass(activations)
+	return mlp.backpropDropout(X, y, masks, activations, deltas, coefGrads, interceptGrads)
+}
+
+// backpropDropout is backprop with dropout masks drawn from RandomState if masks is not nil (see forwardPassDropout)
+func (mlp *BaseMultilayerPerceptron64) backpropDropout(X, y blas64General, masks, activations, deltas, coefGrads []blas64General, interceptGrads [][]float64) float64 {
+	nSamples := X.Rows
+	mlp.forwardPassDropout(activations, masks)
 	if mlp.BatchNormalize {
 		// compute norm of activations for non-terminal layers
 		mlp.batchNormalize(activations)
 	}
+	if mlp.LabelSmoothing > 0 && mlp.IsClassifier() {
+		y = mlp.smoothLabels(y)
+	}
 
 	//# Get loss
-	lossFuncName := mlp.LossFuncName
-	if strings.EqualFold(lossFuncName, "log_loss") && strings.EqualFold(mlp.OutActivation, "logistic") {
-		lossFuncName = "binary_log_loss"
-	}
 	// y may have less rows than activations il last batch
-	loss := LossFunctions64[lossFuncName](y, activations[len(activations)-1])
+	loss := mlp.dataLoss(y, activations[len(activations)-1])
 	// # Add L2 regularization term to loss
 	loss += (0.5 * mlp.Alpha) * mlp.sumCoefSquares() / float64(nSamples)
 
@@ -379,6 +567,21 @@ func (mlp *BaseMultilayerPerceptron64) backprop(X, y blas64General, activations,
 				D.Data[posc] = H.Data[posc] - y.Data[posc]
 			}
 		}
+		if !mlp.canonicalOutput() {
+			Derivatives64[mlp.OutActivation](H, D)
+		}
+		for o, outputLoss := range mlp.OutputLosses {
+			// pinball loss derivative is -q below the target, 1-q above
+			if q, ok := outputQuantile64(outputLoss); ok {
+				for r, pos := 0, o; r < y.Rows; r, pos = r+1, pos+y.Stride {
+					if D.Data[pos] < 0 {
+						D.Data[pos] = -q
+					} else {
+						D.Data[pos] = 1 - q
+					}
+				}
+			}
+		}
 	}
 
 	//# Compute gradient for the last layer
@@ -389,6 +592,15 @@ func (mlp *BaseMultilayerPerceptron64) backprop(X, y blas64General, activations,
 	for i := mlp.NLayers - 2; i >= 1; i-- {
 		//deltas[i - 1] = safeSparseDot(deltas[i], self.coefs_[i].T)
 		gemm64(blas.NoTrans, blas.Trans, 1, deltas[i], mlp.Coefs[i], 0, deltas[i-1])
+		if masks != nil {
+			// skip dropped units, and restore activations[i] (no more used by computeLossGrad) before dropout for inplaceDerivative
+			for pos, scale := range masks[i-1].Data {
+				deltas[i-1].Data[pos] *= scale
+				if scale > 0 {
+					activations[i].Data[pos] /= scale
+				}
+			}
+		}
 
 		inplaceDerivative := Derivatives64[mlp.Activation]
 		// inplaceDerivative multiplies deltas[i-1] by activation derivative
@@ -406,6 +618,56 @@ func (mlp *BaseMultilayerPerceptron64) backprop(X, y blas64General, activations,
 	return loss
 }
 
+// mixup returns blends of X and y rows with randomly permuted rows, see Mixup
+func (mlp *BaseMultilayerPerceptron64) mixup(X, y blas64General) (Xm, ym blas64General) {
+	lambda := float64(distuv.Beta{Alpha: float64(mlp.Mixup), Beta: float64(mlp.Mixup), Src: mlp.RandomState}.Rand())
+	perm := rand.New(mlp.RandomState).Perm(X.Rows)
+	blend := func(m blas64General) blas64General {
+		b := blas64General{Rows: m.Rows, Cols: m.Cols, Stride: m.Cols, Data: make([]float64, m.Rows*m.Cols)}
+		for row, pos, bpos := 0, 0, 0; row < m.Rows; row, pos, bpos = row+1, pos+m.Stride, bpos+b.Stride {
+			other := perm[row] * m.Stride
+			for col := 0; col < m.Cols; col++ {
+				b.Data[bpos+col] = lambda*m.Data[pos+col] + (1-lambda)*m.Data[other+col]
+			}
+		}
+		return b
+	}
+	return blend(X), blend(y)
+}
+
+// allocDropoutMasks returns dropoutMasks for the hidden layers of activations, reallocating them if their shape changed
+func (mlp *BaseMultilayerPerceptron64) allocDropoutMasks(activations []blas64General) []blas64General {
+	hidden := activations[1 : len(activations)-1]
+	if len(mlp.dropoutMasks) != len(hidden) || len(hidden) > 0 && len(mlp.dropoutMasks[0].Data) != len(hidden[0].Data) {
+		mlp.dropoutMasks = make([]blas64General, len(hidden))
+		for i, a := range hidden {
+			mlp.dropoutMasks[i] = blas64General{Rows: a.Rows, Cols: a.Cols, Stride: a.Stride, Data: make([]float64, len(a.Data))}
+		}
+	}
+	return mlp.dropoutMasks
+}
+
+// dataLoss returns the loss of output activations h for targets y, without regularization
+func (mlp *BaseMultilayerPerceptron64) dataLoss(y, h blas64General) float64 {
+	lossFuncName := mlp.LossFuncName
+	if strings.EqualFold(lossFuncName, "log_loss") && strings.EqualFold(mlp.OutActivation, "logistic") {
+		lossFuncName = "binary_log_loss"
+	}
+	if lossFuncName != "mixed_loss" {
+		return LossFunctions64[lossFuncName](y, h)
+	}
+	var loss float64
+	for o, outputLoss := range mlp.OutputLosses {
+		if q, ok := outputQuantile64(outputLoss); ok {
+			loss += pinballLoss64(columnView64(y, o), columnView64(h, o), q)
+			continue
+		}
+		name, _ := outputLossName64(outputLoss)
+		loss += LossFunctions64[name](columnView64(y, o), columnView64(h, o))
+	}
+	return loss
+}
+
 func (mlp *BaseMultilayerPerceptron64) initialize(yCols int, layerUnits []int, isClassifier, isMultiClass bool) {
 	// # set all attributes, allocate weights etc for first call
 	// # Initialize parameters
@@ -429,6 +691,18 @@ func (mlp *BaseMultilayerPerceptron64) initialize(yCols int, layerUnits []int, i
 		mlp.OutActivation = "logistic"
 		mlp.LossFuncName = "binary_log_loss"
 	}
+	if len(mlp.OutputLosses) > 0 {
+		if len(mlp.OutputLosses) != yCols {
+			log.Panicf("OutputLosses: got %d losses for %d outputs.", len(mlp.OutputLosses), yCols)
+		}
+		mlp.OutActivation = "mixed"
+		mlp.LossFuncName = "mixed_loss"
+	} else if mlp.OutputActivation != "" {
+		mlp.OutActivation = mlp.OutputActivation
+		if _, ok := Derivatives64[mlp.OutActivation]; !mlp.canonicalOutput() && (!ok || mlp.LossFuncName != "square_loss") {
+			log.Panicf("The output activation \"%s\" is not supported with %s.", mlp.OutActivation, mlp.LossFuncName)
+		}
+	}
 	//# Initialize coefficient and intercept layers
 	mlp.Coefs = make([]blas64General, mlp.NLayers-1)
 	mlp.Intercepts = make([][]float64, mlp.NLayers-1)
@@ -445,7 +719,7 @@ func (mlp *BaseMultilayerPerceptron64) initialize(yCols int, layerUnits []int, i
 
 	off = 0
 	if mlp.RandomState == (base.RandomState)(nil) {
-		mlp.RandomState = base.NewLockedSource(uint64(time.Now().UnixNano()))
+		mlp.RandomState = base.DefaultSource()
 	}
 	type Float64er interface {
 		Float64() float64
@@ -482,7 +756,28 @@ func (mlp *BaseMultilayerPerceptron64) initialize(yCols int, layerUnits []int, i
 	mlp.BestLoss = M64.Inf(1)
 }
 
+// canonicalOutput returns true if OutActivation is the canonical link of the loss function,
+// in which case the derivative of the loss with respect to output layer input is activation-y
+func (mlp *BaseMultilayerPerceptron64) canonicalOutput() bool {
+	switch mlp.OutActivation {
+	case "identity":
+		return mlp.LossFuncName == "square_loss"
+	case "logistic":
+		return mlp.LossFuncName == "binary_log_loss" || mlp.LossFuncName == "log_loss"
+	case "softmax":
+		return mlp.LossFuncName == "log_loss"
+	case "mixed":
+		return mlp.LossFuncName == "mixed_loss"
+	}
+	return false
+}
+
 func (mlp *BaseMultilayerPerceptron64) fit(X, y blas64General, incremental bool) {
+	defer func(start time.Time) { mlp.FitTime = time.Since(start) }(time.Now())
+	if mlp.NInit > 1 && !mlp.WarmStart && !incremental {
+		mlp.fitNInit(X, y)
+		return
+	}
 	// # Validate input parameters.
 	mlp.validateHyperparameters()
 	for _, s := range mlp.HiddenLayerSizes {
@@ -491,6 +786,13 @@ func (mlp *BaseMultilayerPerceptron64) fit(X, y blas64General, incremental bool)
 		}
 	}
 	X, y = mlp.validateInput(X, y, incremental)
+	if mlp.Standardize {
+		if (!mlp.WarmStart && !incremental) || mlp.XMean == nil {
+			mlp.fitStandardize(X)
+		}
+		X = mlp.standardize(X)
+	}
+	X = mlp.maskInput(X)
 	nSamples, nFeatures := X.Rows, X.Cols
 
 	mlp.NOutputs = y.Cols
@@ -498,18 +800,22 @@ func (mlp *BaseMultilayerPerceptron64) fit(X, y blas64General, incremental bool)
 	layerUnits = append(layerUnits, mlp.NOutputs)
 
 	if mlp.RandomState == nil {
-		mlp.RandomState = rand.New(base.NewLockedSource(uint64(time.Now().UnixNano())))
+		mlp.RandomState = base.DefaultSource()
 	}
 	if !mlp.WarmStart && !incremental {
 		//# First time training the model
-		var isClassifier, isMulticlass = true, y.Cols > 1
-		for _, yval := range y.Data {
-			if yval != 0 && yval != 1 {
-				isClassifier = false
-				break
-			}
-		}
+		var isClassifier, isMulticlass = isBinarized64(y), y.Cols > 1
 		mlp.initialize(y.Cols, layerUnits, isClassifier, isMulticlass)
+		mlp.usePretrained()
+		mlp.useInitialWeights()
+	}
+	if mlp.StandardizeTarget && mlp.LossFuncName == "square_loss" {
+		if (!mlp.WarmStart && !incremental) || mlp.YMean == nil {
+			mlp.YMean, mlp.YScale = meanScale64(y)
+		}
+		y = standardizeColumns64(y, mlp.YMean, mlp.YScale)
+	} else if !mlp.WarmStart && !incremental {
+		mlp.YMean, mlp.YScale = nil, nil
 	}
 
 	//    # lbfgs does not support mini-batches
@@ -546,17 +852,7 @@ func (mlp *BaseMultilayerPerceptron64) fit(X, y blas64General, incremental bool)
 		off += size
 	}
 
-	off = len(mlp.packedParameters)
-	packedGrads := make([]float64, off)
-	CoefsGrads := make([]blas64General, mlp.NLayers-1)
-	InterceptsGrads := make([][]float64, mlp.NLayers-1)
-	off = 0
-	for i := 0; i < mlp.NLayers-1; i++ {
-		InterceptsGrads[i] = packedGrads[off : off+layerUnits[i+1]]
-		off += layerUnits[i+1]
-		CoefsGrads[i] = blas64General{Rows: layerUnits[i], Cols: layerUnits[i+1], Stride: layerUnits[i+1], Data: packedGrads[off : off+layerUnits[i]*layerUnits[i+1]]}
-		off += layerUnits[i] * layerUnits[i+1]
-	}
+	packedGrads, CoefsGrads, InterceptsGrads := mlp.allocGrads(layerUnits)
 
 	if strings.EqualFold(mlp.Solver, "lbfgs") {
 		// # Run the LBFGS solver
@@ -570,26 +866,136 @@ func (mlp *BaseMultilayerPerceptron64) fit(X, y blas64General, incremental bool)
 	mlp.packedGrads = packedGrads
 }
 
-// IsClassifier return true if LossFuncName is not square_loss
+// fitNInit trains NInit times from random initializations drawn from RandomState and keeps the best run:
+// the one with the highest BestValidationScore if EarlyStopping is set, the one with the lowest final Loss otherwise
+func (mlp *BaseMultilayerPerceptron64) fitNInit(X, y blas64General) {
+	nInit := mlp.NInit
+	mlp.NInit = 1
+	var best BaseMultilayerPerceptron64
+	for i := 0; i < nInit; i++ {
+		mlp.LossCurve, mlp.ValidationScores, mlp.LayerGradNorms = nil, nil, nil
+		mlp.fit(X, y, false)
+		better := mlp.Loss < best.Loss
+		if mlp.EarlyStopping {
+			better = mlp.BestValidationScore > best.BestValidationScore
+		}
+		if i == 0 || better {
+			best = *mlp
+		}
+	}
+	*mlp = best
+	mlp.NInit = nInit
+}
+
+// Pretrain does greedy layer-wise autoencoder pretraining of the hidden layers on (unlabeled) X.
+// each hidden layer is trained, using the mlp hyperparameters, as the hidden layer of an autoencoder reconstructing
+// its input (X for the first hidden layer, activations of the previous hidden layer for the others).
+// learned weights replace the random initialization at next Fit without WarmStart.
+// if Standardize is set, X is standardized for pretraining with its own statistics
+func (mlp *BaseMultilayerPerceptron64) Pretrain(X Matrix) {
+	var xc General64
+	xc.Copy(X)
+	input := xc.RawMatrix()
+	if mlp.Standardize {
+		mlp.fitStandardize(input)
+		input = mlp.standardize(input)
+	}
+	if mlp.RandomState == nil {
+		mlp.RandomState = base.DefaultSource()
+	}
+	mlp.pretrainedCoefs = make([]blas64General, len(mlp.HiddenLayerSizes))
+	mlp.pretrainedIntercepts = make([][]float64, len(mlp.HiddenLayerSizes))
+	for i, units := range mlp.HiddenLayerSizes {
+		ae := NewBaseMultilayerPerceptron64()
+		ae.Activation, ae.Solver, ae.Alpha, ae.BatchSize = mlp.Activation, mlp.Solver, mlp.Alpha, mlp.BatchSize
+		ae.LearningRate, ae.LearningRateInit, ae.MaxIter, ae.Tol = mlp.LearningRate, mlp.LearningRateInit, mlp.MaxIter, mlp.Tol
+		ae.Shuffle, ae.RandomState, ae.Verbose = mlp.Shuffle, mlp.RandomState, mlp.Verbose
+		ae.HiddenLayerSizes = []int{units}
+		// rows of input and target are swapped together when shuffling, so target must not share input data
+		var target General64
+		target.Copy(General64(input))
+		ae.fit(input, target.RawMatrix(), false)
+
+		hidden := blas64General{Rows: input.Rows, Cols: units, Stride: units, Data: make([]float64, input.Rows*units)}
+		gemm64(blas.NoTrans, blas.NoTrans, 1, input, ae.Coefs[0], 0, hidden)
+		addIntercepts64(hidden, ae.Intercepts[0])
+		Activations64[mlp.Activation](hidden)
+		mlp.pretrainedCoefs[i], mlp.pretrainedIntercepts[i] = ae.Coefs[0], ae.Intercepts[0]
+		input = hidden
+	}
+}
+
+// usePretrained copies the weights learned by Pretrain into the hidden layers
+func (mlp *BaseMultilayerPerceptron64) usePretrained() {
+	for i, coefs := range mlp.pretrainedCoefs {
+		if coefs.Rows != mlp.Coefs[i].Rows || coefs.Cols != mlp.Coefs[i].Cols {
+			panic(fmt.Errorf("Pretrain: layer %d weights are %dx%d, expected %dx%d", i, coefs.Rows, coefs.Cols, mlp.Coefs[i].Rows, mlp.Coefs[i].Cols))
+		}
+		copy(mlp.Coefs[i].Data, coefs.Data)
+		copy(mlp.Intercepts[i], mlp.pretrainedIntercepts[i])
+	}
+}
+
+// SetInitialWeights sets the weights used instead of the random initialization at next Fit without WarmStart.
+// coefs[i] is the fanIn x fanOut matrix of layer i (input layer first) and intercepts[i] its fanOut intercepts.
+// hidden layers must match HiddenLayerSizes; input and output sizes are checked at Fit
+func (mlp *BaseMultilayerPerceptron64) SetInitialWeights(coefs [][][]float64, intercepts [][]float64) {
+	if len(coefs) != len(mlp.HiddenLayerSizes)+1 || len(intercepts) != len(coefs) {
+		panic(fmt.Errorf("SetInitialWeights: expected %d layers, got %d coefs and %d intercepts", len(mlp.HiddenLayerSizes)+1, len(coefs), len(intercepts)))
+	}
+	mlp.initialCoefs = make([]blas64General, len(coefs))
+	mlp.initialIntercepts = make([][]float64, len(coefs))
+	for i, c := range coefs {
+		fanIn, fanOut := len(c), len(intercepts[i])
+		if (i > 0 && fanIn != mlp.HiddenLayerSizes[i-1]) || (i < len(mlp.HiddenLayerSizes) && fanOut != mlp.HiddenLayerSizes[i]) {
+			panic(fmt.Errorf("SetInitialWeights: layer %d weights are %dx%d, expected hidden layer sizes %v", i, fanIn, fanOut, mlp.HiddenLayerSizes))
+		}
+		w := blas64General{Rows: fanIn, Cols: fanOut, Stride: fanOut, Data: make([]float64, fanIn*fanOut)}
+		for r, row := range c {
+			if len(row) != fanOut {
+				panic(fmt.Errorf("SetInitialWeights: layer %d row %d has %d weights, expected %d", i, r, len(row), fanOut))
+			}
+			for o, v := range row {
+				w.Data[r*w.Stride+o] = float64(v)
+			}
+		}
+		mlp.initialCoefs[i] = w
+		mlp.initialIntercepts[i] = make([]float64, fanOut)
+		for o, v := range intercepts[i] {
+			mlp.initialIntercepts[i][o] = float64(v)
+		}
+	}
+}
+
+// useInitialWeights copies the weights set by SetInitialWeights into the network. they are used only once
+func (mlp *BaseMultilayerPerceptron64) useInitialWeights() {
+	for i, coefs := range mlp.initialCoefs {
+		if coefs.Rows != mlp.Coefs[i].Rows || coefs.Cols != mlp.Coefs[i].Cols {
+			panic(fmt.Errorf("SetInitialWeights: layer %d weights are %dx%d, expected %dx%d", i, coefs.Rows, coefs.Cols, mlp.Coefs[i].Rows, mlp.Coefs[i].Cols))
+		}
+		copy(mlp.Coefs[i].Data, coefs.Data)
+		copy(mlp.Intercepts[i], mlp.initialIntercepts[i])
+	}
+	mlp.initialCoefs, mlp.initialIntercepts = nil, nil
+}
+
+// IsClassifier return true if LossFuncName is not square_loss nor mixed_loss (set by OutputLosses)
 func (mlp *BaseMultilayerPerceptron64) IsClassifier() bool {
-	return mlp.LossFuncName != "square_loss"
+	return mlp.LossFuncName != "square_loss" && mlp.LossFuncName != "mixed_loss"
 }
 
 // Fit compute Coefs and Intercepts
+// X and Y may be any mat.Matrix. RawMatrixer64 (such as slices of General64) are used without copy unless Shuffle is set
 func (mlp *BaseMultilayerPerceptron64) Fit(X, Y Matrix) {
 	var xb, yb blas64.General
-	if xg, ok := X.(RawMatrixer64); ok && !mlp.Shuffle {
-		if yg, ok := Y.(RawMatrixer64); ok {
-			xb, yb = xg.RawMatrix(), yg.RawMatrix()
-		}
+	if mlp.Shuffle {
+		// rows are swapped during training, so work on copies
+		var xc, yc General64
+		xc.Copy(X)
+		yc.Copy(Y)
+		xb, yb = xc.RawMatrix(), yc.RawMatrix()
 	} else {
-		var tmp General64
-		tmp = General64(xb)
-		tmp.Copy(X)
-		xb = tmp.RawMatrix()
-		tmp = General64(yb)
-		tmp.Copy(Y)
-		yb = tmp.RawMatrix()
+		xb, yb = ToDense64(X).RawMatrix(), ToDense64(Y).RawMatrix()
 	}
 	if mlp.IsClassifier() && !isBinarized64(yb) {
 		mlp.lb = NewLabelBinarizer64(0, 1)
@@ -609,20 +1015,134 @@ func (mlp *BaseMultilayerPerceptron64) GetNOutputs() int {
 
 // Predict do forward pass and fills Y (Y must be Mutable)
 func (mlp *BaseMultilayerPerceptron64) Predict(X mat.Matrix, Y Mutable) {
-	var xb, yb General64
-	if xg, ok := X.(RawMatrixer64); ok {
-		if yg, ok := Y.(RawMatrixer64); ok {
-			xb, yb = General64(xg.RawMatrix()), General64(yg.RawMatrix())
-		}
-	} else {
-		xb.Copy(X)
-		yb.Copy(Y)
+	xb, yb := ToDense64(X), ToDense64(Y)
+	if mlp.Standardize {
+		xb = General64(mlp.standardize(xb.RawMatrix()))
 	}
 	mlp.predict(xb.RawMatrix(), yb.RawMatrix())
 
 	FromDense64(Y, yb)
 }
 
+// fitStandardize computes XMean and XScale from X
+func (mlp *BaseMultilayerPerceptron64) fitStandardize(X blas64General) {
+	mlp.XMean, mlp.XScale = meanScale64(X)
+}
+
+// meanScale64 returns the mean and standard deviation of each column of X. the scale of constant columns is 1
+func meanScale64(X blas64General) (mean, scale []float64) {
+	mean, scale = make([]float64, X.Cols), make([]float64, X.Cols)
+	for row, pos := 0, 0; row < X.Rows; row, pos = row+1, pos+X.Stride {
+		for col := 0; col < X.Cols; col++ {
+			mean[col] += X.Data[pos+col]
+		}
+	}
+	for col := range mean {
+		mean[col] /= float64(X.Rows)
+	}
+	for row, pos := 0, 0; row < X.Rows; row, pos = row+1, pos+X.Stride {
+		for col := 0; col < X.Cols; col++ {
+			d := X.Data[pos+col] - mean[col]
+			scale[col] += d * d
+		}
+	}
+	for col := range scale {
+		scale[col] = M64.Sqrt(scale[col] / float64(X.Rows))
+		// constant features are only centered, as in StandardScaler
+		if scale[col] == 0 {
+			scale[col] = 1
+		}
+	}
+	return
+}
+
+// standardize returns a standardized copy of X using XMean and XScale
+func (mlp *BaseMultilayerPerceptron64) standardize(X blas64General) blas64General {
+	if len(mlp.XMean) != X.Cols {
+		log.Panicf("Standardize: X has %d features, expected %d", X.Cols, len(mlp.XMean))
+	}
+	return standardizeColumns64(X, mlp.XMean, mlp.XScale)
+}
+
+// standardizeColumns64 returns a copy of X with columns centered by mean and divided by scale
+func standardizeColumns64(X blas64General, mean, scale []float64) blas64General {
+	Xs := blas64General{Rows: X.Rows, Cols: X.Cols, Stride: X.Cols, Data: make([]float64, X.Rows*X.Cols)}
+	for row, pos, spos := 0, 0, 0; row < X.Rows; row, pos, spos = row+1, pos+X.Stride, spos+Xs.Stride {
+		for col := 0; col < X.Cols; col++ {
+			Xs.Data[spos+col] = (X.Data[pos+col] - mean[col]) / scale[col]
+		}
+	}
+	return Xs
+}
+
+// targetStandardized returns true if the network was trained on targets standardized with YMean and YScale
+func (mlp *BaseMultilayerPerceptron64) targetStandardized() bool {
+	return mlp.StandardizeTarget && mlp.YMean != nil
+}
+
+// lossTargets returns Y as seen by the loss in fit: binarized for a classifier fitted on labels, standardized if targetStandardized
+func (mlp *BaseMultilayerPerceptron64) lossTargets(Y *mat.Dense) blas64General {
+	var yg General64
+	if mlp.lb != nil {
+		_, yg = mlp.lb.Transform(nil, Y)
+	} else {
+		yg.Copy(Y)
+	}
+	if mlp.targetStandardized() {
+		return standardizeColumns64(yg.RawMatrix(), mlp.YMean, mlp.YScale)
+	}
+	return yg.RawMatrix()
+}
+
+// unstandardizeTarget transforms in place standardized predictions Y back to original units
+func (mlp *BaseMultilayerPerceptron64) unstandardizeTarget(Y blas64General) {
+	for row, pos := 0, 0; row < Y.Rows; row, pos = row+1, pos+Y.Stride {
+		for o := 0; o < Y.Cols; o++ {
+			Y.Data[pos+o] = Y.Data[pos+o]*mlp.YScale[o] + mlp.YMean[o]
+		}
+	}
+}
+
+// smoothLabels returns a copy of binarized y with LabelSmoothing applied
+func (mlp *BaseMultilayerPerceptron64) smoothLabels(y blas64General) blas64General {
+	eps := mlp.LabelSmoothing
+	off := eps
+	if mlp.OutActivation == "softmax" {
+		off = eps / float64(y.Cols-1)
+	}
+	ys := blas64General{Rows: y.Rows, Cols: y.Cols, Stride: y.Cols, Data: make([]float64, y.Rows*y.Cols)}
+	for row, pos, spos := 0, 0, 0; row < y.Rows; row, pos, spos = row+1, pos+y.Stride, spos+ys.Stride {
+		for col := 0; col < y.Cols; col++ {
+			v := y.Data[pos+col]
+			ys.Data[spos+col] = v*(1-eps) + (1-v)*off
+		}
+	}
+	return ys
+}
+
+// maskInput returns X, or a copy of X whose columns masked by InputMask are zeroed
+func (mlp *BaseMultilayerPerceptron64) maskInput(X blas64General) blas64General {
+	masked := false
+	for _, m := range mlp.InputMask {
+		masked = masked || m
+	}
+	if !masked {
+		return X
+	}
+	if len(mlp.InputMask) != X.Cols {
+		log.Panicf("InputMask: X has %d features, expected %d", X.Cols, len(mlp.InputMask))
+	}
+	Xm := blas64General{Rows: X.Rows, Cols: X.Cols, Stride: X.Cols, Data: make([]float64, X.Rows*X.Cols)}
+	for row, pos, mpos := 0, 0, 0; row < X.Rows; row, pos, mpos = row+1, pos+X.Stride, mpos+Xm.Stride {
+		for col := 0; col < X.Cols; col++ {
+			if !mlp.InputMask[col] {
+				Xm.Data[mpos+col] = X.Data[pos+col]
+			}
+		}
+	}
+	return Xm
+}
+
 func (mlp *BaseMultilayerPerceptron64) validateHyperparameters() {
 	if mlp.MaxIter <= 0 {
 		log.Panicf("maxIter must be > 0, got %d.", mlp.MaxIter)
@@ -651,6 +1171,18 @@ func (mlp *BaseMultilayerPerceptron64) validateHyperparameters() {
 	if mlp.NIterNoChange <= 0 {
 		log.Panicf("nIterNoChange must be > 0, got %d.", mlp.NIterNoChange)
 	}
+	if mlp.TrainPatience < 0 || mlp.ValidationPatience < 0 {
+		log.Panicf("trainPatience and validationPatience must be >= 0, got %d and %d.", mlp.TrainPatience, mlp.ValidationPatience)
+	}
+	if mlp.Mixup < 0 {
+		log.Panicf("mixup must be >= 0, got %g", mlp.Mixup)
+	}
+	if mlp.Dropout < 0 || mlp.Dropout >= 1 {
+		log.Panicf("dropout must be >= 0 and < 1, got %g", mlp.Dropout)
+	}
+	if mlp.LabelSmoothing < 0 || mlp.LabelSmoothing >= 1 {
+		log.Panicf("labelSmoothing must be >= 0 and < 1, got %g", mlp.LabelSmoothing)
+	}
 	//# raise ValueError if not registered
 
 	supportedActivations := []string{}
@@ -661,8 +1193,26 @@ func (mlp *BaseMultilayerPerceptron64) validateHyperparameters() {
 	if _, ok := Activations64[mlp.Activation]; !ok {
 		log.Panicf("The activation \"%s\" is not supported. Supported activations are %s.", mlp.Activation, supportedActivations)
 	}
+	if _, ok := Activations64[mlp.OutputActivation]; !ok && mlp.OutputActivation != "" {
+		log.Panicf("The output activation \"%s\" is not supported. Supported activations are %s.", mlp.OutputActivation, supportedActivations)
+	}
+	for _, loss := range mlp.OutputLosses {
+		if _, ok := outputLossName64(loss); !ok {
+			log.Panicf("The output loss \"%s\" is not supported. Supported output losses are square_loss, binary_log_loss and quantile:q with 0<q<1.", loss)
+		}
+	}
+	if len(mlp.OutputLosses) > 0 && mlp.OutputActivation != "" {
+		log.Panicf("OutputLosses and OutputActivation can't be both set.")
+	}
 	switch mlp.LearningRate {
 	case "constant", "invscaling", "adaptive":
+	case "cosine":
+		if mlp.CycleLength <= 0 {
+			log.Panicf("cycleLength must be > 0 for cosine learning rate, got %d.", mlp.CycleLength)
+		}
+		if mlp.LearningRateMin < 0 || mlp.LearningRateMin > mlp.LearningRateInit {
+			log.Panicf("learningRateMin must be >= 0 and <= learningRateInit, got %g.", mlp.LearningRateMin)
+		}
 	default:
 		log.Panicf("learning rate %s is not supported.", mlp.LearningRate)
 	}
@@ -695,9 +1245,11 @@ func (mlp *BaseMultilayerPerceptron64) fitLbfgs(X, y blas64General, activations,
 			mu.Lock()
 			mlp.Loss = float64(loss)
 			mlp.LossCurve = append(mlp.LossCurve, mlp.Loss)
+			mlp.appendLayerGradNorms(coefGrads)
 			if mlp.BestLoss > mlp.Loss {
 				mlp.BestLoss = mlp.Loss
 			}
+			mlp.sendProgress(len(mlp.LossCurve), false)
 			mu.Unlock()
 			return loss
 		},
@@ -722,121 +1274,597 @@ func (mlp *BaseMultilayerPerceptron64) fitLbfgs(X, y blas64General, activations,
 	if err != nil {
 		log.Panic(err)
 	}
-	if res.Status != optimize.GradientThreshold && res.Status != optimize.FunctionConvergence {
+	mlp.Converged = res.Status == optimize.GradientThreshold || res.Status == optimize.FunctionConvergence
+	mlp.StopReason = ""
+	if mlp.Converged {
+		mlp.StopReason = res.Status.String()
+	} else {
 		log.Printf("lbfgs optimizer: Maximum iterations (%d) reached and the optimization hasn't converged yet.\n", mlp.MaxIter)
 	}
 }
 
-func (mlp *BaseMultilayerPerceptron64) fitStochastic(X, y blas64General, activations, deltas, coefGrads []blas64General,
-	interceptGrads [][]float64, packedGrads []float64, layerUnits []int, incremental bool) {
-	if !incremental || mlp.optimizer == Optimizer64(nil) {
-		params := mlp.packedParameters
-		switch mlp.Solver {
-		case "sgd":
-			mlp.optimizer = &SGDOptimizer64{
-				Params:           params,
-				LearningRateInit: mlp.LearningRateInit,
-				LearningRate:     mlp.LearningRateInit,
-				LRSchedule:       mlp.LearningRate,
-				PowerT:           mlp.PowerT,
-				Momentum:         mlp.Momentum,
-				Nesterov:         mlp.NesterovsMomentum}
-		case "adam":
-			mlp.optimizer = &AdamOptimizer64{
-				Params:           params,
-				LearningRateInit: mlp.LearningRateInit,
-				LearningRate:     mlp.LearningRateInit,
-				Beta1:            mlp.Beta1, Beta2: mlp.Beta2, Epsilon: mlp.Epsilon,
-			}
-		}
+// ComputeLossAndGrad runs a single forward and backward pass on X,Y with current weights and returns the loss
+// and the packed gradient (with the packedParameters layout: for each layer, intercepts then coefs row by row),
+// as used by Fit for a minibatch. X is standardized if Standardize is set and Y is binarized for a classifier fitted
+// on labels or standardized if StandardizeTarget is set. Dropout is not applied, so that the result is deterministic. weights (and batch normalization state) are left unchanged
+func (mlp *BaseMultilayerPerceptron64) ComputeLossAndGrad(X, Y *mat.Dense) (loss float64, grad []float64) {
+	if mlp.packedParameters == nil {
+		panic(fmt.Errorf("ComputeLossAndGrad: mlp is not fitted"))
+	}
+	var xg General64
+	xg.Copy(X)
+	xb := xg.RawMatrix()
+	if mlp.Standardize {
+		xb = mlp.standardize(xb)
+	}
+	xb = mlp.maskInput(xb)
+	y := mlp.lossTargets(Y)
+	layerUnits := mlp.layerUnits()
+	packedGrads, coefGrads, interceptGrads := mlp.allocGrads(layerUnits)
+	activations := []blas64General{xb}
+	deltas := make([]blas64General, 0, len(layerUnits)-1)
+	for _, nFanOut := range layerUnits[1:] {
+		activations = append(activations, blas64General{Rows: xb.Rows, Cols: nFanOut, Stride: nFanOut, Data: make([]float64, xb.Rows*nFanOut)})
+		deltas = append(deltas, blas64General{Rows: xb.Rows, Cols: nFanOut, Stride: nFanOut, Data: make([]float64, xb.Rows*nFanOut)})
 	}
-	// # earlyStopping in partialFit doesn"t make sense
-	earlyStopping := mlp.EarlyStopping && !incremental
-	var XVal, yVal blas64General
-	nSamples := X.Rows
-	testSize := 0
-	if earlyStopping {
-		testSize = int(M64.Ceil(mlp.ValidationFraction * float64(nSamples)))
-		XVal = blas64General(General64(X).RowSlice(nSamples-testSize, nSamples))
-		yVal = blas64General(General64(y).RowSlice(nSamples-testSize, nSamples))
-		mlp.bestParameters = make([]float64, len(mlp.packedParameters))
-		// if isClassifier(self):
-		// 	yVal = self.LabelBinarizer64.inverseTransform(yVal)
+	// backprop updates batch norms, so restore them
+	batchNorm := make([][]float64, len(mlp.batchNorm))
+	for i, bn := range mlp.batchNorm {
+		batchNorm[i] = append([]float64{}, bn...)
 	}
-	batchSize := mlp.BatchSize
-	idx := make([]int, nSamples)
-	for i := range idx {
-		idx[i] = i
+	loss = float64(mlp.backpropDropout(xb, y, nil, activations, deltas, coefGrads, interceptGrads))
+	for i, bn := range batchNorm {
+		copy(mlp.batchNorm[i], bn)
 	}
-	type Shuffler interface {
-		Shuffle(n int, swap func(i, j int))
+	grad = make([]float64, len(packedGrads))
+	for i, g := range packedGrads {
+		grad[i] = float64(g)
 	}
-	var rndShuffle func(n int, swap func(i, j int))
-	if shuffler, ok := mlp.RandomState.(Shuffler); ok {
-		rndShuffle = shuffler.Shuffle
+	return
+}
 
-	} else {
-		rndShuffle = rand.New(mlp.RandomState).Shuffle
+// PerSampleLoss returns the loss of each row of X,Y after a forward pass with current weights and no dropout.
+// Y is binarized and label-smoothed as in ComputeLossAndGrad, whose loss is the mean of per-sample losses
+// plus the L2 regularization term when Alpha>0
+func (mlp *BaseMultilayerPerceptron64) PerSampleLoss(X, Y *mat.Dense) []float64 {
+	if mlp.packedParameters == nil {
+		panic(fmt.Errorf("PerSampleLoss: mlp is not fitted"))
+	}
+	activations := mlp.Activations(X)
+	H := ToDense64(activations[len(activations)-1]).RawMatrix()
+	y := mlp.lossTargets(Y)
+	if mlp.LabelSmoothing > 0 && mlp.IsClassifier() {
+		y = mlp.smoothLabels(y)
+	}
+	losses := make([]float64, y.Rows)
+	for i := range losses {
+		yi := blas64General{Rows: 1, Cols: y.Cols, Stride: y.Stride, Data: y.Data[i*y.Stride : i*y.Stride+y.Cols]}
+		hi := blas64General{Rows: 1, Cols: H.Cols, Stride: H.Stride, Data: H.Data[i*H.Stride : i*H.Stride+H.Cols]}
+		losses[i] = float64(mlp.dataLoss(yi, hi))
+	}
+	return losses
+}
+
+// HvpProduct returns the product of the Hessian of the loss (as computed by ComputeLossAndGrad) with v,
+// v having the packedParameters layout. it uses the R-operator: the forward and backward passes are
+// differentiated in the direction v. BatchNormalize is not supported and WeightDecay is ignored
+func (mlp *BaseMultilayerPerceptron64) HvpProduct(X, Y *mat.Dense, v []float64) []float64 {
+	if mlp.packedParameters == nil {
+		panic(fmt.Errorf("HvpProduct: mlp is not fitted"))
 	}
-	func() {
-		if r := recover(); r != nil {
-			// ...
-			log.Panic(r)
+	if len(v) != len(mlp.packedParameters) {
+		panic(fmt.Errorf("HvpProduct: expected %d values in v, got %d", len(mlp.packedParameters), len(v)))
+	}
+	if mlp.BatchNormalize {
+		panic(fmt.Errorf("HvpProduct: BatchNormalize is not supported"))
+	}
+	if mlp.OutActivation == "mixed" {
+		panic(fmt.Errorf("HvpProduct: OutputLosses is not supported"))
+	}
+	var xg General64
+	xg.Copy(X)
+	xb := xg.RawMatrix()
+	if mlp.Standardize {
+		xb = mlp.standardize(xb)
+	}
+	xb = mlp.maskInput(xb)
+	y := mlp.lossTargets(Y)
+	if mlp.LabelSmoothing > 0 && mlp.IsClassifier() {
+		y = mlp.smoothLabels(y)
+	}
+	nSamples := xb.Rows
+	layerUnits := mlp.layerUnits()
+	vPacked, vCoefs, vIntercepts := mlp.allocGrads(layerUnits)
+	for i := range v {
+		vPacked[i] = float64(v[i])
+	}
+	hvPacked, hvCoefs, hvIntercepts := mlp.allocGrads(layerUnits)
+	newMatrix := func(cols int) blas64General {
+		return blas64General{Rows: nSamples, Cols: cols, Stride: cols, Data: make([]float64, nSamples*cols)}
+	}
+
+	// forward pass. rz[i] and ra[i] are the directional derivatives of the input and output of activations[i]
+	activations, rz, ra := []blas64General{xb}, []blas64General{newMatrix(xb.Cols)}, []blas64General{newMatrix(xb.Cols)}
+	for i := 0; i < mlp.NLayers-1; i++ {
+		a, r := newMatrix(layerUnits[i+1]), newMatrix(layerUnits[i+1])
+		gemm64(blas.NoTrans, blas.NoTrans, 1, activations[i], mlp.Coefs[i], 0, a)
+		addIntercepts64(a, mlp.Intercepts[i])
+		gemm64(blas.NoTrans, blas.NoTrans, 1, ra[i], mlp.Coefs[i], 0, r)
+		gemm64(blas.NoTrans, blas.NoTrans, 1, activations[i], vCoefs[i], 1, r)
+		addIntercepts64(r, vIntercepts[i])
+		activation := mlp.Activation
+		if i == mlp.NLayers-2 {
+			activation = mlp.OutActivation
 		}
-		for it := 0; it < mlp.MaxIter; it++ {
-			if mlp.Shuffle {
-				rndShuffle(nSamples, indexedXY{idx: sort.IntSlice(idx), X: general64FastSwap(X), Y: general64FastSwap(y)}.Swap)
-			}
-			accumulatedLoss := float64(0.0)
-			for batch := [2]int{0, batchSize}; batch[0] < nSamples-testSize; batch = [2]int{batch[1], batch[1] + batchSize} {
-				if batch[1] > nSamples-testSize {
-					batch[1] = nSamples - testSize
+		Activations64[activation](a)
+		rOut := newMatrix(r.Cols)
+		if activation == "softmax" {
+			for pos := 0; pos < len(a.Data); pos += a.Stride {
+				dot := float64(0)
+				for o := 0; o < a.Cols; o++ {
+					dot += a.Data[pos+o] * r.Data[pos+o]
 				}
-				// activations[0] = X[batchSlice]
-				Xbatch := blas64General(General64(X).RowSlice(batch[0], batch[1]))
-				Ybatch := blas64General(General64(y).RowSlice(batch[0], batch[1]))
-
-				activations[0] = Xbatch
-				for _, a := range activations {
-					a.Rows = Xbatch.Rows
+				for o := 0; o < a.Cols; o++ {
+					rOut.Data[pos+o] = a.Data[pos+o] * (r.Data[pos+o] - dot)
 				}
-
-				//X, y blas64General, activations, deltas, coefGrads []blas64General, interceptGrads
-				batchLoss := mlp.backprop(Xbatch, Ybatch, activations, deltas, coefGrads, interceptGrads)
-				accumulatedLoss += batchLoss * float64(batch[1]-batch[0])
-
-				//# update weights
-				mlp.optimizer.updateParams(packedGrads)
 			}
-			mlp.NIter++
-			mlp.Loss = accumulatedLoss / float64(nSamples)
+		} else {
+			for pos, av := range a.Data {
+				d1, _ := activationDerivatives64(activation, av)
+				rOut.Data[pos] = d1 * r.Data[pos]
+			}
+		}
+		activations, rz, ra = append(activations, a), append(rz, r), append(ra, rOut)
+	}
+
+	// backward pass
+	last := mlp.NLayers - 2
+	delta, rdelta := newMatrix(layerUnits[last+1]), newMatrix(layerUnits[last+1])
+	{
+		H, RH, RZ := activations[last+1], ra[last+1], rz[last+1]
+		canonical := mlp.canonicalOutput()
+		for pos := range delta.Data {
+			delta.Data[pos], rdelta.Data[pos] = H.Data[pos]-y.Data[pos], RH.Data[pos]
+			if !canonical {
+				d1, d2 := activationDerivatives64(mlp.OutActivation, H.Data[pos])
+				rdelta.Data[pos] = RH.Data[pos]*d1 + delta.Data[pos]*d2*RZ.Data[pos]
+				delta.Data[pos] *= d1
+			}
+		}
+	}
+	for i := last; i >= 0; i-- {
+		// R-operator on computeLossGrad
+		gemm64(blas.Trans, blas.NoTrans, 1/float64(nSamples), ra[i], delta, 0, hvCoefs[i])
+		gemm64(blas.Trans, blas.NoTrans, 1/float64(nSamples), activations[i], rdelta, 1, hvCoefs[i])
+		axpy64(len(hvCoefs[i].Data), mlp.Alpha/float64(nSamples), vCoefs[i].Data, hvCoefs[i].Data)
+		matRowMean64(rdelta, hvIntercepts[i])
+		if mlp.RegularizeIntercept {
+			axpy64(len(hvIntercepts[i]), mlp.Alpha/float64(nSamples), vIntercepts[i], hvIntercepts[i])
+		}
+		if i == 0 {
+			break
+		}
+		e, re := newMatrix(layerUnits[i]), newMatrix(layerUnits[i])
+		gemm64(blas.NoTrans, blas.Trans, 1, delta, mlp.Coefs[i], 0, e)
+		gemm64(blas.NoTrans, blas.Trans, 1, rdelta, mlp.Coefs[i], 0, re)
+		gemm64(blas.NoTrans, blas.Trans, 1, delta, vCoefs[i], 1, re)
+		for pos, av := range activations[i].Data {
+			d1, d2 := activationDerivatives64(mlp.Activation, av)
+			re.Data[pos] = re.Data[pos]*d1 + e.Data[pos]*d2*rz[i].Data[pos]
+			e.Data[pos] *= d1
+		}
+		delta, rdelta = e, re
+	}
+	hv := make([]float64, len(hvPacked))
+	for i, h := range hvPacked {
+		hv[i] = float64(h)
+	}
+	return hv
+}
+
+// InputWeightImportance returns, for each input feature, the L1 norm of its outgoing weights in the first layer,
+// normalized to sum 1. it is a quick feature relevance heuristic. weights apply to standardized features if Standardize is set
+func (mlp *BaseMultilayerPerceptron64) InputWeightImportance() []float64 {
+	if mlp.packedParameters == nil {
+		panic(fmt.Errorf("InputWeightImportance: mlp is not fitted"))
+	}
+	coefs := mlp.Coefs[0]
+	importance := make([]float64, coefs.Rows)
+	sum := 0.
+	for i := range importance {
+		for _, w := range coefs.Data[i*coefs.Stride : i*coefs.Stride+coefs.Cols] {
+			importance[i] += float64(M64.Abs(w))
+		}
+		sum += importance[i]
+	}
+	if sum > 0 {
+		for i := range importance {
+			importance[i] /= sum
+		}
+	}
+	return importance
+}
+
+// Saliency returns, for each sample of X, the gradient of output outputIndex of the output layer (ie PredictProba column for classifiers)
+// with respect to the features of X. the gradient is taken through standardization and InputMask, and weights are left unchanged
+func (mlp *BaseMultilayerPerceptron64) Saliency(X *mat.Dense, outputIndex int) *mat.Dense {
+	if mlp.packedParameters == nil {
+		panic(fmt.Errorf("Saliency: mlp is not fitted"))
+	}
+	if outputIndex < 0 || outputIndex >= mlp.NOutputs {
+		panic(fmt.Errorf("Saliency: outputIndex must be in [0,%d), got %d", mlp.NOutputs, outputIndex))
+	}
+	activations := mlp.Activations(X)
+	toBlas64 := func(dense *mat.Dense) blas64General {
+		return ToDense64(dense).RawMatrix()
+	}
+	last := mlp.NLayers - 2
+	H := toBlas64(activations[last+1])
+	delta := blas64General{Rows: H.Rows, Cols: H.Cols, Stride: H.Cols, Data: make([]float64, H.Rows*H.Cols)}
+	// derivatives of output outputIndex with respect to output layer inputs
+	for pos := 0; pos < len(H.Data); pos += H.Stride {
+		ao := H.Data[pos+outputIndex]
+		if mlp.OutActivation == "softmax" {
+			for o := 0; o < H.Cols; o++ {
+				delta.Data[pos+o] = -ao * H.Data[pos+o]
+			}
+			delta.Data[pos+outputIndex] += ao
+		} else {
+			delta.Data[pos+outputIndex], _ = activationDerivatives64(mlp.outputColumnActivation(outputIndex), ao)
+		}
+	}
+	for i := last; i >= 0; i-- {
+		e := blas64General{Rows: delta.Rows, Cols: mlp.Coefs[i].Rows, Stride: mlp.Coefs[i].Rows, Data: make([]float64, delta.Rows*mlp.Coefs[i].Rows)}
+		gemm64(blas.NoTrans, blas.Trans, 1, delta, mlp.Coefs[i], 0, e)
+		if i > 0 {
+			Derivatives64[mlp.Activation](toBlas64(activations[i]), e)
+		}
+		delta = e
+	}
+	for row, pos := 0, 0; row < delta.Rows; row, pos = row+1, pos+delta.Stride {
+		for col := 0; col < delta.Cols; col++ {
+			if len(mlp.InputMask) == delta.Cols && mlp.InputMask[col] {
+				delta.Data[pos+col] = 0
+			} else if mlp.Standardize {
+				delta.Data[pos+col] /= mlp.XScale[col]
+			}
+		}
+	}
+	saliency := mat.NewDense(delta.Rows, delta.Cols, nil)
+	FromDense64(saliency, General64(delta))
+	return saliency
+}
+
+// activationDerivatives64 returns the first and second derivatives of an elementwise activation, given its output a
+func activationDerivatives64(activation string, a float64) (d1, d2 float64) {
+	switch activation {
+	case "identity":
+		return 1, 0
+	case "logistic":
+		d1 = a * (1 - a)
+		return d1, d1 * (1 - 2*a)
+	case "tanh":
+		d1 = 1 - a*a
+		return d1, -2 * a * d1
+	case "relu":
+		if a > 0 {
+			return 1, 0
+		}
+		return 0, 0
+	}
+	panic(fmt.Errorf("no derivatives for activation %s", activation))
+}
+
+// allocGrads allocates packed gradients and their per layer views, with the layout of packedParameters
+func (mlp *BaseMultilayerPerceptron64) allocGrads(layerUnits []int) (packedGrads []float64, coefGrads []blas64General, interceptGrads [][]float64) {
+	packedGrads = make([]float64, len(mlp.packedParameters))
+	coefGrads = make([]blas64General, mlp.NLayers-1)
+	interceptGrads = make([][]float64, mlp.NLayers-1)
+	off := 0
+	for i := 0; i < mlp.NLayers-1; i++ {
+		interceptGrads[i] = packedGrads[off : off+layerUnits[i+1]]
+		off += layerUnits[i+1]
+		coefGrads[i] = blas64General{Rows: layerUnits[i], Cols: layerUnits[i+1], Stride: layerUnits[i+1], Data: packedGrads[off : off+layerUnits[i]*layerUnits[i+1]]}
+		off += layerUnits[i] * layerUnits[i+1]
+	}
+	return
+}
+
+// decayWeights applies decoupled WeightDecay to weights after an optimizer update
+func (mlp *BaseMultilayerPerceptron64) decayWeights() {
+	if mlp.WeightDecay <= 0 {
+		return
+	}
+	var learningRate float64
+	switch opt := mlp.optimizer.(type) {
+	case *SGDOptimizer64:
+		learningRate = opt.LearningRate
+	case *AdamOptimizer64:
+		learningRate = opt.LearningRateInit
+	}
+	decay := 1 - learningRate*mlp.WeightDecay
+	for iw := range mlp.packedParameters {
+		mlp.packedParameters[iw] *= decay
+	}
+}
+
+// initOptimizer creates the stochastic optimizer for Solver
+func (mlp *BaseMultilayerPerceptron64) initOptimizer() {
+	params := mlp.packedParameters
+	switch mlp.Solver {
+	case "sgd":
+		mlp.optimizer = &SGDOptimizer64{
+			Params:           params,
+			LearningRateInit: mlp.LearningRateInit,
+			LearningRate:     mlp.LearningRateInit,
+			LRSchedule:       mlp.LearningRate,
+			PowerT:           mlp.PowerT,
+			Momentum:         mlp.Momentum,
+			Nesterov:         mlp.NesterovsMomentum,
+			CycleLength:      mlp.CycleLength,
+			CycleMult:        mlp.CycleMult,
+			LearningRateMin:  mlp.LearningRateMin}
+	case "adam":
+		mlp.optimizer = &AdamOptimizer64{
+			Params:           params,
+			LearningRateInit: mlp.LearningRateInit,
+			LearningRate:     mlp.LearningRateInit,
+			Beta1:            mlp.Beta1, Beta2: mlp.Beta2, Epsilon: mlp.Epsilon,
+		}
+	}
+}
+
+// FitDataLoader trains the mlp with the stochastic solver (sgd or adam) on batches pulled from loader instead of in-memory X and Y.
+// each of the MaxIter epochs calls loader.Reset then loader.NextBatch until it returns ok=false, updating weights after each batch.
+// the network is initialized (unless WarmStart is set) from the shapes of the first batch. for classification, Y must be binarized.
+// BatchSize, Shuffle, Standardize, StandardizeTarget, EarlyStopping and AccumulationSteps are not used: batches are those of loader
+func (mlp *BaseMultilayerPerceptron64) FitDataLoader(loader DataLoader) {
+	defer func(start time.Time) { mlp.FitTime = time.Since(start) }(time.Now())
+	mlp.validateHyperparameters()
+	if strings.EqualFold(mlp.Solver, "lbfgs") {
+		panic(fmt.Errorf("FitDataLoader: lbfgs solver is not supported"))
+	}
+	toBlas64 := func(M *mat.Dense) blas64General {
+		var g General64
+		g.Copy(M)
+		return g.RawMatrix()
+	}
+	toInput := func(M *mat.Dense) blas64General { return mlp.maskInput(toBlas64(M)) }
+	loader.Reset()
+	Xbatch, Ybatch, ok := loader.NextBatch()
+	if !ok {
+		panic(fmt.Errorf("FitDataLoader: loader has no batch"))
+	}
+	xb, yb := toInput(Xbatch), toBlas64(Ybatch)
+	layerUnits := append([]int{xb.Cols}, mlp.HiddenLayerSizes...)
+	layerUnits = append(layerUnits, yb.Cols)
+	if mlp.RandomState == nil {
+		mlp.RandomState = base.DefaultSource()
+	}
+	if !mlp.WarmStart || mlp.packedParameters == nil {
+		mlp.initialize(yb.Cols, layerUnits, isBinarized64(yb), yb.Cols > 1)
+		mlp.usePretrained()
+		mlp.initOptimizer()
+	} else if mlp.optimizer == Optimizer64(nil) {
+		mlp.initOptimizer()
+	}
+	mlp.NOutputs = yb.Cols
+	packedGrads, coefGrads, interceptGrads := mlp.allocGrads(layerUnits)
+	// activations and deltas are reallocated when a batch is larger than previous ones
+	activations := make([]blas64General, len(layerUnits))
+	deltas := make([]blas64General, len(layerUnits)-1)
+	capacity := 0
+	setBatch := func(xb blas64General) {
+		if xb.Rows > capacity {
+			capacity = xb.Rows
+			for i, nFanOut := range layerUnits[1:] {
+				activations[i+1] = blas64General{Rows: capacity, Cols: nFanOut, Stride: nFanOut, Data: make([]float64, capacity*nFanOut)}
+				deltas[i] = blas64General{Rows: capacity, Cols: nFanOut, Stride: nFanOut, Data: make([]float64, capacity*nFanOut)}
+			}
+		}
+		activations[0] = xb
+		for i := range deltas {
+			activations[i+1].Rows, deltas[i].Rows = xb.Rows, xb.Rows
+		}
+	}
+	mlp.Converged, mlp.StopReason = false, ""
+	for it := 0; it < mlp.MaxIter; it++ {
+		if it > 0 {
+			loader.Reset()
+			Xbatch, Ybatch, ok = loader.NextBatch()
+		}
+		accumulatedLoss, nSamples := float64(0), 0
+		for ; ok; Xbatch, Ybatch, ok = loader.NextBatch() {
+			xb, yb = toInput(Xbatch), toBlas64(Ybatch)
+			setBatch(xb)
+			batchLoss := mlp.backprop(xb, yb, activations, deltas, coefGrads, interceptGrads)
+			accumulatedLoss += batchLoss * float64(xb.Rows)
+			nSamples += xb.Rows
+			mlp.optimizer.updateParams(packedGrads)
+			mlp.decayWeights()
+		}
+		mlp.NIter++
+		mlp.Loss = accumulatedLoss / float64(nSamples)
+		mlp.t += nSamples
+		mlp.LossCurve = append(mlp.LossCurve, mlp.Loss)
+		mlp.appendLayerGradNorms(coefGrads)
+		if mlp.Verbose {
+			fmt.Printf("Iteration %d, loss = %.8f\n", mlp.NIter, mlp.Loss)
+		}
+		mlp.updateNoImprovementCount(false, blas64General{}, blas64General{})
+		mlp.sendProgress(mlp.NIter, false)
+		mlp.optimizer.iterationEnds(float64(mlp.t))
+		if msg := mlp.patienceExceeded(false); msg != "" {
+			if mlp.optimizer.triggerStopping(msg, mlp.Verbose) {
+				mlp.Converged, mlp.StopReason = true, msg
+				break
+			}
+			mlp.NoImprovementCount, mlp.trainNoImproveCount = 0, 0
+		}
+	}
+	mlp.packedGrads = packedGrads
+}
+
+func (mlp *BaseMultilayerPerceptron64) fitStochastic(X, y blas64General, activations, deltas, coefGrads []blas64General,
+	interceptGrads [][]float64, packedGrads []float64, layerUnits []int, incremental bool) {
+	// with WarmStart, optimizer state (restored by LoadCheckpoint or from a previous Fit) is reused
+	if (!incremental && !mlp.WarmStart) || mlp.optimizer == Optimizer64(nil) {
+		mlp.initOptimizer()
+	}
+	// # earlyStopping in partialFit doesn"t make sense
+	earlyStopping := mlp.EarlyStopping && !incremental
+	// validation split is used for early stopping or for best weights restoration
+	validation := (mlp.EarlyStopping || mlp.RestoreBestWeights) && !incremental
+	var XVal, yVal blas64General
+	nSamples := X.Rows
+	testSize := 0
+	batchSize := mlp.BatchSize
+	// with AccumulationSteps>1, gradients of AccumulationSteps consecutive batches are averaged before each update
+	accumulationSteps := mlp.AccumulationSteps
+	var accumulatedGrads []float64
+	if accumulationSteps > 1 {
+		accumulatedGrads = make([]float64, len(packedGrads))
+	}
+	idx := make([]int, nSamples)
+	for i := range idx {
+		idx[i] = i
+	}
+	if !incremental && !mlp.WarmStart {
+		mlp.ShuffleOrders = nil
+	}
+	type Shuffler interface {
+		Shuffle(n int, swap func(i, j int))
+	}
+	var rndShuffle func(n int, swap func(i, j int))
+	if shuffler, ok := mlp.RandomState.(Shuffler); ok {
+		rndShuffle = shuffler.Shuffle
+
+	} else {
+		rndShuffle = rand.New(mlp.RandomState).Shuffle
+	}
+	// reordered is true when rows of X and y are to be restored in original order at the end of fit
+	reordered := mlp.Shuffle
+	if validation {
+		testSize = int(M64.Ceil(mlp.ValidationFraction * float64(nSamples)))
+		if mlp.IsClassifier() {
+			// validation rows are moved at the end of X and y
+			testSize = stratifiedValidationSplit64(indexedXY{idx: sort.IntSlice(idx), X: general64FastSwap(X), Y: general64FastSwap(y)}, y, testSize, rndShuffle)
+			reordered = true
+		}
+		XVal = blas64General(General64(X).RowSlice(nSamples-testSize, nSamples))
+		yVal = blas64General(General64(y).RowSlice(nSamples-testSize, nSamples))
+		if mlp.targetStandardized() {
+			// validation scores compare predictions in original units
+			var yc General64
+			yc.Copy(General64(yVal))
+			yVal = yc.RawMatrix()
+			mlp.unstandardizeTarget(yVal)
+		}
+		mlp.bestParameters = make([]float64, len(mlp.packedParameters))
+		mlp.BestValidationScore, mlp.BestIter = M64.Inf(-1), 0
+		// if isClassifier(self):
+		// 	yVal = self.LabelBinarizer64.inverseTransform(yVal)
+	}
+	func() {
+		if r := recover(); r != nil {
+			// ...
+			log.Panic(r)
+		}
+		mlp.Converged, mlp.StopReason = false, ""
+		for it := 0; it < mlp.MaxIter; it++ {
+			if mlp.Shuffle {
+				// validation rows are kept at the end
+				rndShuffle(nSamples-testSize, indexedXY{idx: sort.IntSlice(idx), X: general64FastSwap(X), Y: general64FastSwap(y)}.Swap)
+			}
+			if mlp.RecordShuffleOrder && len(mlp.ShuffleOrders) < maxShuffleOrders {
+				mlp.ShuffleOrders = append(mlp.ShuffleOrders, append([]int(nil), idx[:nSamples-testSize]...))
+			}
+			accumulatedLoss := float64(0.0)
+			microBatch, accumulatedSamples := 0, 0
+			for batch := [2]int{0, batchSize}; batch[0] < nSamples-testSize; batch = [2]int{batch[1], batch[1] + batchSize} {
+				if batch[1] > nSamples-testSize {
+					batch[1] = nSamples - testSize
+				}
+				// activations[0] = X[batchSlice]
+				Xbatch := blas64General(General64(X).RowSlice(batch[0], batch[1]))
+				Ybatch := blas64General(General64(y).RowSlice(batch[0], batch[1]))
+				if mlp.Mixup > 0 {
+					Xbatch, Ybatch = mlp.mixup(Xbatch, Ybatch)
+				}
+
+				activations[0] = Xbatch
+				for _, a := range activations {
+					a.Rows = Xbatch.Rows
+				}
+
+				if accumulationSteps > 1 {
+					batchLoss := mlp.backprop(Xbatch, Ybatch, activations, deltas, coefGrads, interceptGrads)
+					accumulatedLoss += batchLoss * float64(batch[1]-batch[0])
+					axpy64(len(packedGrads), float64(batch[1]-batch[0]), packedGrads, accumulatedGrads)
+					microBatch++
+					accumulatedSamples += batch[1] - batch[0]
+					if microBatch < accumulationSteps && batch[1] < nSamples-testSize {
+						continue
+					}
+					// packedGrads is the mean gradient over accumulated samples.
+					// each micro-batch contributed L2 regularization once so keep only one of them
+					for i := range packedGrads {
+						packedGrads[i] = accumulatedGrads[i] / float64(accumulatedSamples)
+						accumulatedGrads[i] = 0
+					}
+					for i := range coefGrads {
+						axpy64(len(coefGrads[i].Data), -float64(microBatch-1)*mlp.Alpha/float64(accumulatedSamples), mlp.Coefs[i].Data, coefGrads[i].Data)
+						if mlp.RegularizeIntercept {
+							axpy64(len(interceptGrads[i]), -float64(microBatch-1)*mlp.Alpha/float64(accumulatedSamples), mlp.Intercepts[i], interceptGrads[i])
+						}
+					}
+					microBatch, accumulatedSamples = 0, 0
+				} else {
+					//X, y blas64General, activations, deltas, coefGrads []blas64General, interceptGrads
+					batchLoss := mlp.backprop(Xbatch, Ybatch, activations, deltas, coefGrads, interceptGrads)
+					accumulatedLoss += batchLoss * float64(batch[1]-batch[0])
+				}
+
+				//# update weights
+				mlp.optimizer.updateParams(packedGrads)
+				mlp.decayWeights()
+			}
+			mlp.NIter++
+			mlp.Loss = accumulatedLoss / float64(nSamples)
 
 			mlp.t += nSamples
 			mlp.LossCurve = append(mlp.LossCurve, mlp.Loss)
+			mlp.appendLayerGradNorms(coefGrads)
 			if mlp.Verbose {
 				fmt.Printf("Iteration %d, loss = %.8f\n", mlp.NIter, mlp.Loss)
 			}
 			// # update noImprovementCount based on training loss or
 			// # validation score according to earlyStopping
 			mlp.updateNoImprovementCount(earlyStopping, XVal, yVal)
+			if validation && !earlyStopping {
+				mlp.updateBestValidationScore(XVal, yVal)
+			}
+			mlp.sendProgress(mlp.NIter, validation)
 
 			// # for learning rate that needs to be updated at iteration end
 			mlp.optimizer.iterationEnds(float64(mlp.t))
 
-			if mlp.NoImprovementCount > mlp.NIterNoChange {
-				// # not better than last `nIterNoChange` iterations by tol
-				// # stop or decrease learning rate
-				var msg string
-				if earlyStopping {
-					msg = fmt.Sprintf("Validation score did not improve more than tol=%f for %d consecutive epochs.", mlp.Tol, mlp.NIterNoChange)
-				} else {
-					msg = fmt.Sprintf("Training loss did not improve more than tol=%f for %d consecutive epochs.", mlp.Tol, mlp.NIterNoChange)
+			if msg := mlp.targetScoreReached(validation, blas64General(General64(X).RowSlice(0, nSamples-testSize)), blas64General(General64(y).RowSlice(0, nSamples-testSize))); msg != "" {
+				if mlp.Verbose {
+					fmt.Println(msg)
 				}
+				mlp.Converged, mlp.StopReason = true, msg
+				break
+			}
+			if msg := mlp.patienceExceeded(earlyStopping); msg != "" {
+				// # not better than last patience iterations by tol
+				// # stop or decrease learning rate
 				isStopping := mlp.optimizer.triggerStopping(msg, mlp.Verbose)
 				if isStopping {
+					mlp.Converged, mlp.StopReason = true, msg
 					break
 				}
-				mlp.NoImprovementCount = 0
+				mlp.NoImprovementCount, mlp.trainNoImproveCount = 0, 0
 			}
 
 			if incremental {
@@ -847,54 +1875,316 @@ func (mlp *BaseMultilayerPerceptron64) fitStochastic(X, y blas64General, activat
 			}
 		}
 	}()
-	if earlyStopping {
+	if validation {
 		// # restore best weights
 		copy(mlp.packedParameters, mlp.bestParameters)
 	}
-	if mlp.Shuffle {
+	if reordered {
 		sort.Sort(indexedXY{idx: sort.IntSlice(idx), X: general64FastSwap(X), Y: general64FastSwap(y)})
 	}
 }
 
-func (mlp *BaseMultilayerPerceptron64) updateNoImprovementCount(earlyStopping bool, XVal, yVal blas64General) {
+// FitSummary returns a summary of the last Fit
+func (mlp *BaseMultilayerPerceptron64) FitSummary() FitSummary {
+	summary := FitSummary{NIter: mlp.NIter, Loss: float64(mlp.Loss), Converged: mlp.Converged, StopReason: mlp.StopReason, Elapsed: mlp.FitTime}
+	if len(mlp.ValidationScores) > 0 {
+		bestValidationScore := float64(mlp.BestValidationScore)
+		summary.BestValidationScore = &bestValidationScore
+	}
+	return summary
+}
 
-	if earlyStopping {
-		//# compute validation score, use that for stopping
-		lastValidScore := mlp.score(XVal, yVal)
+// appendLayerGradNorms appends to LayerGradNorms the L2 norm of each layer coefficient gradients.
+// for stochastic solvers, gradients are the ones of the last batch of the iteration
+func (mlp *BaseMultilayerPerceptron64) appendLayerGradNorms(coefGrads []blas64General) {
+	norms := make([]float64, len(coefGrads))
+	for i, g := range coefGrads {
+		norms[i] = M64.Sqrt(dot64(len(g.Data), 1, 1, g.Data, g.Data))
+	}
+	mlp.LayerGradNorms = append(mlp.LayerGradNorms, norms)
+}
+
+// stratifiedValidationSplit64 moves about testSize rows to the end of xy,
+// sampling each class of y (one-hot columns, or 0/1 single column) in proportion of its frequency.
+// each class having at least 2 samples has at least one validation sample.
+// returns the actual number of validation rows
+func stratifiedValidationSplit64(xy indexedXY, y blas64General, testSize int, rndShuffle func(n int, swap func(i, j int))) int {
+	nSamples := y.Rows
+	classRows := make(map[int][]int)
+	classes := []int{}
+	for i, pos := 0, 0; i < nSamples; i, pos = i+1, pos+y.Stride {
+		var class int
+		if y.Cols > 1 {
+			class = MaxIdx64(y.Data[pos : pos+y.Cols])
+		} else if y.Data[pos] > .5 {
+			class = 1
+		}
+		if _, ok := classRows[class]; !ok {
+			classes = append(classes, class)
+		}
+		classRows[class] = append(classRows[class], i)
+	}
+	sort.Ints(classes)
+	// per-class validation sizes are proportional to class frequencies, with at least 1 sample per class.
+	// the rounding difference is given to the largest class
+	nValidation := make([]int, len(classes))
+	largest, total := 0, 0
+	for ic, class := range classes {
+		rows := classRows[class]
+		nValidation[ic] = testSize * len(rows) / nSamples
+		if nValidation[ic] == 0 && len(rows) >= 2 {
+			nValidation[ic] = 1
+		}
+		total += nValidation[ic]
+		if len(rows) > len(classRows[classes[largest]]) {
+			largest = ic
+		}
+	}
+	nValidation[largest] += testSize - total
+	validation := make([]bool, nSamples)
+	total = 0
+	for ic, class := range classes {
+		rows := classRows[class]
+		n := nValidation[ic]
+		if n >= len(rows) {
+			n = len(rows) - 1
+		}
+		if n < 0 {
+			n = 0
+		}
+		rndShuffle(len(rows), func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+		for _, row := range rows[:n] {
+			validation[row] = true
+		}
+		total += n
+	}
+	// stable partition of rows: training rows followed by validation rows
+	perm := make([]int, 0, nSamples)
+	for _, isValidation := range []bool{false, true} {
+		for i := 0; i < nSamples; i++ {
+			if validation[i] == isValidation {
+				perm = append(perm, i)
+			}
+		}
+	}
+	// position[row] is the current position of original row, rowAt[position] is the original row at position
+	position, rowAt := make([]int, nSamples), make([]int, nSamples)
+	for i := range position {
+		position[i], rowAt[i] = i, i
+	}
+	for target, row := range perm {
+		current := position[row]
+		if current == target {
+			continue
+		}
+		xy.Swap(target, current)
+		other := rowAt[target]
+		rowAt[target], rowAt[current] = row, other
+		position[row], position[other] = target, current
+	}
+	return total
+}
 
-		mlp.ValidationScores = append(mlp.ValidationScores, lastValidScore)
+// sendProgress sends a ProgressEvent for iteration to Progress, with the last of ValidationScores if validation is set
+func (mlp *BaseMultilayerPerceptron64) sendProgress(iteration int, validation bool) {
+	if mlp.Progress == nil {
+		return
+	}
+	ev := ProgressEvent{Iteration: iteration, Loss: float64(mlp.Loss), ValidationScore: float64(M64.NaN())}
+	if validation && len(mlp.ValidationScores) > 0 {
+		ev.ValidationScore = float64(mlp.ValidationScores[len(mlp.ValidationScores)-1])
+	}
+	sendProgress(mlp.Progress, ev)
+}
 
-		if mlp.Verbose {
-			fmt.Printf("Validation score: %g\n", lastValidScore)
+// updateBestValidationScore appends the validation score (MonitorMetric if set) to ValidationScores
+// and keeps the weights in bestParameters if it is the best one
+func (mlp *BaseMultilayerPerceptron64) updateBestValidationScore(XVal, yVal blas64General) float64 {
+	lastValidScore := mlp.monitoredScore(XVal, yVal)
+	mlp.ValidationScores = append(mlp.ValidationScores, lastValidScore)
+	if mlp.Verbose {
+		fmt.Printf("Validation score: %g\n", lastValidScore)
+	}
+	if lastValidScore > mlp.BestValidationScore {
+		mlp.BestValidationScore, mlp.BestIter = lastValidScore, mlp.NIter
+		copy(mlp.bestParameters, mlp.packedParameters)
+	}
+	return lastValidScore
+}
+
+// monitoredScore returns MonitorMetric if set, else accuracy or R2, of predictions for X
+func (mlp *BaseMultilayerPerceptron64) monitoredScore(X, y blas64General) float64 {
+	if mlp.MonitorMetric != nil {
+		H := blas64General{Rows: y.Rows, Cols: y.Cols, Stride: y.Cols, Data: make([]float64, y.Rows*y.Cols)}
+		mlp.predict(X, H)
+		return float64(mlp.MonitorMetric(General64(y), General64(H)))
+	}
+	return mlp.score(X, y)
+}
+
+// targetScoreReached returns the stopping message when TargetScore is set and reached by the last validation score
+// if validation is set, or by the score on X and y otherwise. it returns "" otherwise
+func (mlp *BaseMultilayerPerceptron64) targetScoreReached(validation bool, X, y blas64General) string {
+	if mlp.TargetScore == 0 {
+		return ""
+	}
+	var score float64
+	if validation && len(mlp.ValidationScores) > 0 {
+		score = mlp.ValidationScores[len(mlp.ValidationScores)-1]
+	} else {
+		if mlp.targetStandardized() {
+			var yc General64
+			yc.Copy(General64(y))
+			y = yc.RawMatrix()
+			mlp.unstandardizeTarget(y)
 		}
+		score = mlp.monitoredScore(X, y)
+	}
+	if score < mlp.TargetScore {
+		return ""
+	}
+	return fmt.Sprintf("Score %g reached TargetScore=%g.", score, mlp.TargetScore)
+}
+
+func (mlp *BaseMultilayerPerceptron64) updateNoImprovementCount(earlyStopping bool, XVal, yVal blas64General) {
+
+	if earlyStopping {
+		//# compute validation score, use that for stopping
 		// # update best parameters
 		// # use validationScores_, not lossCurve_
-		// # let's hope no-one overloads .score with mse
-
-		if lastValidScore < (mlp.BestValidationScore + mlp.Tol) {
+		bestValidationScore := mlp.BestValidationScore
+		lastValidScore := mlp.updateBestValidationScore(XVal, yVal)
+		if lastValidScore < (bestValidationScore + mlp.Tol) {
 			mlp.NoImprovementCount++
 		} else {
 			mlp.NoImprovementCount = 0
 		}
-		if lastValidScore > mlp.BestValidationScore {
-			mlp.BestValidationScore = lastValidScore
-			copy(mlp.bestParameters, mlp.packedParameters)
-		}
 	}
 	lastLoss := mlp.LossCurve[len(mlp.LossCurve)-1]
+	if lastLoss > mlp.BestLoss-mlp.Tol {
+		mlp.trainNoImproveCount++
+	} else {
+		mlp.trainNoImproveCount = 0
+	}
 	if !earlyStopping {
-		if lastLoss > mlp.BestLoss-mlp.Tol {
-			mlp.NoImprovementCount++
-		} else {
-			mlp.NoImprovementCount = 0
-		}
+		mlp.NoImprovementCount = mlp.trainNoImproveCount
 	}
 	if lastLoss < mlp.BestLoss {
 		mlp.BestLoss = lastLoss
 	}
 }
 
+// patienceExceeded returns the stopping message when the validation score (with earlyStopping) did not improve
+// for more than ValidationPatience epochs, or the training loss for more than TrainPatience epochs. it returns "" otherwise
+func (mlp *BaseMultilayerPerceptron64) patienceExceeded(earlyStopping bool) string {
+	trainPatience, validationPatience := mlp.TrainPatience, mlp.ValidationPatience
+	if validationPatience == 0 {
+		validationPatience = mlp.NIterNoChange
+	}
+	if trainPatience == 0 && !earlyStopping {
+		trainPatience = mlp.NIterNoChange
+	}
+	if earlyStopping && mlp.NoImprovementCount > validationPatience {
+		return fmt.Sprintf("Validation score did not improve more than tol=%f for %d consecutive epochs.", mlp.Tol, validationPatience)
+	}
+	if trainPatience > 0 && mlp.trainNoImproveCount > trainPatience {
+		return fmt.Sprintf("Training loss did not improve more than tol=%f for %d consecutive epochs.", mlp.Tol, trainPatience)
+	}
+	return ""
+}
+
+// Activations returns the activations of every layer for X after a forward pass: the input layer (standardized if Standardize is set),
+// the hidden layers, then the output layer. returned matrices are copies
+func (mlp *BaseMultilayerPerceptron64) Activations(X *mat.Dense) []*mat.Dense {
+	if mlp.packedParameters == nil {
+		panic(fmt.Errorf("Activations: mlp is not fitted"))
+	}
+	var xg General64
+	xg.Copy(X)
+	xb := xg.RawMatrix()
+	if mlp.Standardize {
+		xb = mlp.standardize(xb)
+	}
+	xb = mlp.maskInput(xb)
+	activations := []blas64General{xb}
+	for _, nFanOut := range mlp.layerUnits()[1:] {
+		activations = append(activations, blas64General{Rows: xb.Rows, Cols: nFanOut, Stride: nFanOut, Data: make([]float64, xb.Rows*nFanOut)})
+	}
+	mlp.forwardPass(activations)
+	dense := make([]*mat.Dense, len(activations))
+	for i, a := range activations {
+		dense[i] = mat.NewDense(a.Rows, a.Cols, nil)
+		FromDense64(dense[i], General64(a))
+	}
+	return dense
+}
+
+// ActivationStats returns, for each hidden layer, the fraction of zero activations and the mean and std of activations for X
+func (mlp *BaseMultilayerPerceptron64) ActivationStats(X *mat.Dense) []ActivationStats {
+	return hiddenActivationStats(mlp.Activations(X))
+}
+
+// PredictMCDropout runs nSamples forward passes of X with Dropout active (Monte Carlo dropout) and returns
+// the mean and the standard deviation over passes of each output layer activation (ie PredictProba for classifiers).
+// std is zero if Dropout is 0. for a regressor trained with StandardizeTarget, mean and std are in original target units
+func (mlp *BaseMultilayerPerceptron64) PredictMCDropout(X *mat.Dense, nSamples int) (mean, std *mat.Dense) {
+	if mlp.packedParameters == nil {
+		panic(fmt.Errorf("PredictMCDropout: mlp is not fitted"))
+	}
+	if nSamples <= 0 {
+		panic(fmt.Errorf("PredictMCDropout: nSamples must be > 0, got %d", nSamples))
+	}
+	nRows, _ := X.Dims()
+	mean, std = mat.NewDense(nRows, mlp.NOutputs, nil), mat.NewDense(nRows, mlp.NOutputs, nil)
+	if mlp.targetStandardized() {
+		defer func() {
+			mean.Apply(func(_, o int, v float64) float64 { return v*float64(mlp.YScale[o]) + float64(mlp.YMean[o]) }, mean)
+			std.Apply(func(_, o int, v float64) float64 { return v * float64(mlp.YScale[o]) }, std)
+		}()
+	}
+	if mlp.Dropout == 0 {
+		activations := mlp.Activations(X)
+		mean.Copy(activations[len(activations)-1])
+		return
+	}
+	var xg General64
+	xg.Copy(X)
+	xb := xg.RawMatrix()
+	if mlp.Standardize {
+		xb = mlp.standardize(xb)
+	}
+	xb = mlp.maskInput(xb)
+	activations := []blas64General{xb}
+	for _, nFanOut := range mlp.layerUnits()[1:] {
+		activations = append(activations, blas64General{Rows: xb.Rows, Cols: nFanOut, Stride: nFanOut, Data: make([]float64, xb.Rows*nFanOut)})
+	}
+	masks := make([]blas64General, len(activations)-2)
+	for i := range masks {
+		a := activations[i+1]
+		masks[i] = blas64General{Rows: a.Rows, Cols: a.Cols, Stride: a.Stride, Data: make([]float64, len(a.Data))}
+	}
+	// accumulate sums and sums of squares in float64
+	sum, sumSquares := mean.RawMatrix().Data, std.RawMatrix().Data
+	for pass := 0; pass < nSamples; pass++ {
+		mlp.forwardPassDropout(activations, masks)
+		for pos, h := range activations[len(activations)-1].Data {
+			sum[pos] += float64(h)
+			sumSquares[pos] += float64(h) * float64(h)
+		}
+	}
+	for pos := range sum {
+		sum[pos] /= float64(nSamples)
+		variance := sumSquares[pos]/float64(nSamples) - sum[pos]*sum[pos]
+		sumSquares[pos] = 0
+		if variance > 0 {
+			sumSquares[pos] = M64.Sqrt(variance)
+		}
+	}
+	return
+}
+
 func (mlp *BaseMultilayerPerceptron64) predictProbas(X, Y blas64General) {
+	X = mlp.maskInput(X)
 	_, nFeatures := X.Rows, X.Cols
 
 	layerUnits := append([]int{nFeatures}, mlp.HiddenLayerSizes...)
@@ -929,13 +2219,28 @@ func (mlp *BaseMultilayerPerceptron64) predict(X, Y blas64General) {
 		Y = tmp.RawMatrix()
 	} else if mlp.IsClassifier() {
 		toLogits64(Y)
+	} else {
+		if mlp.targetStandardized() {
+			mlp.unstandardizeTarget(Y)
+		}
+		if lo, hi := mlp.OutputClip[0], mlp.OutputClip[1]; lo < hi {
+			for row, pos := 0, 0; row < Y.Rows; row, pos = row+1, pos+Y.Stride {
+				for o, y := range Y.Data[pos : pos+Y.Cols] {
+					if y < lo {
+						Y.Data[pos+o] = lo
+					} else if y > hi {
+						Y.Data[pos+o] = hi
+					}
+				}
+			}
+		}
 	}
 }
 
 func (mlp *BaseMultilayerPerceptron64) score(X, Y blas64General) float64 {
 	H := blas64General{Rows: Y.Rows, Cols: Y.Cols, Stride: Y.Stride, Data: make([]float64, len(Y.Data))}
 	mlp.predict(X, H)
-	if mlp.LossFuncName != "square_loss" {
+	if mlp.IsClassifier() {
 		// accuracy
 		return accuracyScore64(Y, H)
 	}
@@ -980,7 +2285,7 @@ func (mlp *BaseMultilayerPerceptron64) Score(Xmatrix, Ymatrix mat.Matrix) float6
 	nSamples, nOutputs := X.RawMatrix().Rows, mlp.GetNOutputs()
 	Ypred := blas64.General{Rows: nSamples, Cols: nOutputs, Stride: nOutputs, Data: make([]float64, nSamples*nOutputs)}
 	mlp.Predict(X, General64(Ypred))
-	if mlp.LossFuncName == "square_loss" {
+	if !mlp.IsClassifier() {
 		return float64(r2Score64(blas64.General(Y), Ypred))
 	}
 	return float64(accuracyScore64(blas64.General(Y), Ypred))
@@ -995,13 +2300,36 @@ type SGDOptimizer64 struct {
 	LRSchedule       string
 	Momentum         float64
 	Nesterov         bool
-	velocities       []float64
+	// CycleLength, CycleMult and LearningRateMin are used by the "cosine" LRSchedule
+	CycleLength     int
+	CycleMult       float64
+	LearningRateMin float64
+	velocities      []float64
+	// cycleEpoch is the number of epochs done in the current cycle of length currentCycle
+	cycleEpoch, currentCycle int
 }
 
 func (opt *SGDOptimizer64) iterationEnds(timeStep float64) {
 	if strings.EqualFold(opt.LRSchedule, "invscaling") {
 		opt.LearningRate = opt.LearningRateInit / M64.Pow(timeStep+1, opt.PowerT)
 	}
+	if strings.EqualFold(opt.LRSchedule, "cosine") {
+		if opt.currentCycle == 0 {
+			opt.currentCycle = opt.CycleLength
+		}
+		opt.cycleEpoch++
+		if opt.cycleEpoch >= opt.currentCycle {
+			// warm restart
+			opt.cycleEpoch = 0
+			mult := opt.CycleMult
+			if mult <= 0 {
+				mult = 1
+			}
+			opt.currentCycle = int(M64.Ceil(float64(opt.currentCycle) * mult))
+		}
+		cos := M64.Cos(M64.Pi * float64(opt.cycleEpoch) / float64(opt.currentCycle))
+		opt.LearningRate = opt.LearningRateMin + (opt.LearningRateInit-opt.LearningRateMin)*(1+cos)/2
+	}
 
 }
 func (opt *SGDOptimizer64) triggerStopping(msg string, verbose bool) bool {
@@ -1095,60 +2423,62 @@ func toLogits64(ym blas64General) {
 }
 
 func r2Score64(yTrue, yPred blas64General) float64 {
-	var r2acc float64
-	for c := 0; c < yTrue.Cols; c++ {
-		// yTrueAvg
-		var yTrueAvg, yNum, yDen float64
-		for r, ypos := 0, 0; r < yTrue.Rows; r, ypos = r+1, ypos+yTrue.Stride {
-			yTrueAvg += yTrue.Data[ypos+c]
-
-		}
-		yTrueAvg /= float64(yTrue.Rows)
-		// yDen = YTrue-YTrueAvg
-
-		for r, ypos := 0, 0; r < yTrue.Rows; r, ypos = r+1, ypos+yTrue.Stride {
-			t := yPred.Data[ypos+c] - yTrue.Data[ypos+c]
-			yNum += t * t
-			t = yTrue.Data[ypos+c] - yTrueAvg
-			yDen += t * t
-		}
-		if yDen == 0 {
-			panic("yDen=0")
-		}
-		r2 := 1 - yNum/yDen
-		r2acc += r2
-	}
-	return r2acc / float64(yTrue.Cols)
+	return metrics.R2Score64(yTrue, yPred)
 }
 
 func accuracyScore64(Y, H blas64General) float64 {
-	N := float64(0)
-	for i, hpos, ypos := 0, 0, 0; i < Y.Rows; i, hpos, ypos = i+1, hpos+H.Stride, ypos+Y.Stride {
-		rowEq := true
-		for c := 0; c < Y.Cols; c++ {
-			rowEq = rowEq && H.Data[hpos+c] == Y.Data[ypos+c]
-		}
-		if rowEq {
-			N++
-		}
-	}
-	return float64(N) / float64(Y.Rows)
-
+	return metrics.AccuracyScore64(Y, H)
 }
 
 // SetParams allow settings params from a map. (used by Unmarshal)
+// keys are field names or json tags. numbers and arrays of numbers (as decoded from json) are converted to the field type,
+// values which can't be converted are ignored
 func (mlp *BaseMultilayerPerceptron64) SetParams(params map[string]interface{}) {
 	r := reflect.Indirect(reflect.ValueOf(mlp))
 	for k, v := range params {
 		field := r.FieldByNameFunc(func(s string) bool {
 			return strings.EqualFold(s, k)
 		})
-		if field.Kind() != 0 {
-			field.Set(reflect.ValueOf(v))
+		if field.Kind() == 0 {
+			if i, ok := jsonFieldIndex(r.Type(), k); ok {
+				field = r.Field(i)
+			}
+		}
+		if field.Kind() != 0 && field.CanSet() {
+			setFieldValue(field, v)
 		}
 	}
 }
 
+// Marshal returns the json serialization of the mlp: json tagged hyperparameters under "params",
+// and fitted state with scikit-learn attribute names ("coefs_", "intercepts_", "out_activation_", "classes_"...).
+// it can be read by Unmarshal. RandomState and training history are not saved
+func (mlp *BaseMultilayerPerceptron64) Marshal() ([]byte, error) {
+	params := make(map[string]interface{})
+	r := reflect.Indirect(reflect.ValueOf(mlp))
+	for i := 0; i < r.NumField(); i++ {
+		tag := strings.Split(r.Type().Field(i).Tag.Get("json"), ",")[0]
+		if tag == "" || tag == "-" || tag == "random_state" || strings.HasSuffix(tag, "_") {
+			continue
+		}
+		params[tag] = r.Field(i).Interface()
+	}
+	dic := map[string]interface{}{
+		"params":          params,
+		"coefs_":          mlp.SklearnCoefs(),
+		"intercepts_":     mlp.SklearnIntercepts(),
+		"out_activation_": mlp.OutActivation,
+		"x_mean_":         mlp.XMean,
+		"x_scale_":        mlp.XScale,
+		"y_mean_":         mlp.YMean,
+		"y_scale_":        mlp.YScale,
+	}
+	if mlp.lb != nil {
+		dic["classes_"] = mlp.lb.Classes
+	}
+	return json.Marshal(dic)
+}
+
 // Unmarshal init params intercepts_ coefs_ from json
 func (mlp *BaseMultilayerPerceptron64) Unmarshal(buf []byte) error {
 	type Map = map[string]interface{}
@@ -1195,7 +2525,15 @@ func (mlp *BaseMultilayerPerceptron64) Unmarshal(buf []byte) error {
 				packedSize += (1 + layerUnits[il]) * layerUnits[il+1]
 			}
 			layerUnits[mlp.NLayers-1] = mlp.NOutputs
+			copy(mlp.HiddenLayerSizes, layerUnits[1:mlp.NLayers-1])
+			lossFuncName := mlp.LossFuncName
 			mlp.initialize(mlp.NOutputs, layerUnits, true, mlp.NOutputs > 1)
+			if lossFuncName != "" {
+				mlp.LossFuncName = lossFuncName
+			}
+			if outActivation, ok := mp["out_activation_"].(string); ok {
+				mlp.OutActivation = outActivation
+			}
 
 			for i := 0; i < mlp.NLayers-1; i++ {
 				intercept64 := floats64FromInterface(intercepts2[i])
@@ -1209,9 +2547,341 @@ func (mlp *BaseMultilayerPerceptron64) Unmarshal(buf []byte) error {
 			return fmt.Errorf("coefs_ must be [][][]float64, found %T", coefs)
 		}
 	}
+	if xMean, ok := mp["x_mean_"].([]interface{}); ok {
+		setFieldValue(reflect.ValueOf(&mlp.XMean).Elem(), xMean)
+	}
+	if xScale, ok := mp["x_scale_"].([]interface{}); ok {
+		setFieldValue(reflect.ValueOf(&mlp.XScale).Elem(), xScale)
+	}
+	if yMean, ok := mp["y_mean_"].([]interface{}); ok {
+		setFieldValue(reflect.ValueOf(&mlp.YMean).Elem(), yMean)
+	}
+	if yScale, ok := mp["y_scale_"].([]interface{}); ok {
+		setFieldValue(reflect.ValueOf(&mlp.YScale).Elem(), yScale)
+	}
+	if classes, ok := mp["classes_"].([]interface{}); ok {
+		mlp.lb = NewLabelBinarizer64(0, 1)
+		setFieldValue(reflect.ValueOf(&mlp.lb.Classes).Elem(), classes)
+	}
+	return err
+}
+
+// SklearnCoefs returns a copy of Coefs with scikit-learn coefs_ layout:
+// coefs_[i] is the (layerUnits[i],layerUnits[i+1]) weight matrix of layer i as a slice of rows
+func (mlp *BaseMultilayerPerceptron64) SklearnCoefs() [][][]float64 {
+	coefs := make([][][]float64, len(mlp.Coefs))
+	for i, c := range mlp.Coefs {
+		coefs[i] = make([][]float64, c.Rows)
+		for r := range coefs[i] {
+			coefs[i][r] = make([]float64, c.Cols)
+			for col, v := range c.Data[r*c.Stride : r*c.Stride+c.Cols] {
+				coefs[i][r][col] = float64(v)
+			}
+		}
+	}
+	return coefs
+}
+
+// SklearnIntercepts returns a copy of Intercepts with scikit-learn intercepts_ layout:
+// intercepts_[i] is the bias vector of layer i, of length layerUnits[i+1]
+func (mlp *BaseMultilayerPerceptron64) SklearnIntercepts() [][]float64 {
+	intercepts := make([][]float64, len(mlp.Intercepts))
+	for i, b := range mlp.Intercepts {
+		intercepts[i] = make([]float64, len(b))
+		for j, v := range b {
+			intercepts[i][j] = float64(v)
+		}
+	}
+	return intercepts
+}
+
+type mlpCheckpoint64 struct {
+	LayerUnits         []int
+	OutActivation      string
+	LossFuncName       string
+	NIter, T           int
+	Loss, BestLoss     float64
+	NoImprovementCount int
+	TrainNoImprovement int `json:",omitempty"`
+	LossCurve          []float64
+	PackedParameters   []float64
+	XMean, XScale      []float64
+	YMean, YScale      []float64 `json:",omitempty"`
+	Classes            [][]float64
+	Optimizer          *optimizerCheckpoint64 `json:",omitempty"`
+}
+
+type optimizerCheckpoint64 struct {
+	Solver         string
+	LearningRate   float64
+	Velocities     []float64 `json:",omitempty"`
+	T              float64
+	Ms, Vs         []float64 `json:",omitempty"`
+	Beta1t, Beta2t float64
+	// position in the cosine learning rate schedule
+	CycleEpoch, CurrentCycle int `json:",omitempty"`
+}
+
+// Checkpoint returns a json serialization of the training state: weights, loss history and optimizer state (sgd velocities or adam moments and timestep).
+// Hyperparameters are not saved.
+func (mlp *BaseMultilayerPerceptron64) Checkpoint() ([]byte, error) {
+	if mlp.NLayers == 0 {
+		return nil, fmt.Errorf("Checkpoint: mlp is not initialized")
+	}
+	cp := mlpCheckpoint64{
+		LayerUnits:         make([]int, mlp.NLayers),
+		OutActivation:      mlp.OutActivation,
+		LossFuncName:       mlp.LossFuncName,
+		NIter:              mlp.NIter,
+		T:                  mlp.t,
+		Loss:               mlp.Loss,
+		BestLoss:           mlp.BestLoss,
+		NoImprovementCount: mlp.NoImprovementCount,
+		TrainNoImprovement: mlp.trainNoImproveCount,
+		LossCurve:          mlp.LossCurve,
+		PackedParameters:   mlp.packedParameters,
+		XMean:              mlp.XMean,
+		XScale:             mlp.XScale,
+		YMean:              mlp.YMean,
+		YScale:             mlp.YScale,
+	}
+	for i, c := range mlp.Coefs {
+		cp.LayerUnits[i], cp.LayerUnits[i+1] = c.Rows, c.Cols
+	}
+	if mlp.lb != nil {
+		cp.Classes = mlp.lb.Classes
+	}
+	switch opt := mlp.optimizer.(type) {
+	case *SGDOptimizer64:
+		cp.Optimizer = &optimizerCheckpoint64{Solver: "sgd", LearningRate: opt.LearningRate, Velocities: opt.velocities, CycleEpoch: opt.cycleEpoch, CurrentCycle: opt.currentCycle}
+	case *AdamOptimizer64:
+		cp.Optimizer = &optimizerCheckpoint64{Solver: "adam", LearningRate: opt.LearningRate, T: opt.t, Ms: opt.ms, Vs: opt.vs, Beta1t: opt.beta1t, Beta2t: opt.beta2t}
+	}
+	return json.Marshal(cp)
+}
+
+// LoadCheckpoint restores the training state saved by Checkpoint.
+// Hyperparameters must be set as for the checkpointed mlp. Set WarmStart to resume training with Fit
+func (mlp *BaseMultilayerPerceptron64) LoadCheckpoint(buf []byte) error {
+	var cp mlpCheckpoint64
+	if err := json.Unmarshal(buf, &cp); err != nil {
+		return err
+	}
+	if len(cp.LayerUnits) < 2 {
+		return fmt.Errorf("LoadCheckpoint: expected at least 2 layers, got %d", len(cp.LayerUnits))
+	}
+	layerUnits := cp.LayerUnits
+	mlp.NLayers = len(layerUnits)
+	mlp.NOutputs = layerUnits[mlp.NLayers-1]
+	mlp.HiddenLayerSizes = layerUnits[1 : mlp.NLayers-1]
+	off := 0
+	for i := 0; i < mlp.NLayers-1; i++ {
+		off += (1 + layerUnits[i]) * layerUnits[i+1]
+	}
+	if len(cp.PackedParameters) != off {
+		return fmt.Errorf("LoadCheckpoint: expected %d parameters, got %d", off, len(cp.PackedParameters))
+	}
+	mlp.packedParameters = cp.PackedParameters
+	mlp.Coefs = make([]blas64General, mlp.NLayers-1)
+	mlp.Intercepts = make([][]float64, mlp.NLayers-1)
+	if mlp.BatchNormalize {
+		mlp.batchNorm = make([][]float64, mlp.NLayers-2)
+	}
+	off = 0
+	for i := 0; i < mlp.NLayers-1; i++ {
+		mlp.Intercepts[i] = mlp.packedParameters[off : off+layerUnits[i+1]]
+		off += layerUnits[i+1]
+		mlp.Coefs[i] = blas64General{Rows: layerUnits[i], Cols: layerUnits[i+1], Stride: layerUnits[i+1], Data: mlp.packedParameters[off : off+layerUnits[i]*layerUnits[i+1]]}
+		off += layerUnits[i] * layerUnits[i+1]
+		if mlp.BatchNormalize && i < mlp.NLayers-2 {
+			mlp.batchNorm[i] = make([]float64, layerUnits[i+1])
+		}
+	}
+	mlp.OutActivation, mlp.LossFuncName = cp.OutActivation, cp.LossFuncName
+	mlp.NIter, mlp.t = cp.NIter, cp.T
+	mlp.Loss, mlp.BestLoss, mlp.NoImprovementCount, mlp.trainNoImproveCount = cp.Loss, cp.BestLoss, cp.NoImprovementCount, cp.TrainNoImprovement
+	mlp.LossCurve = cp.LossCurve
+	mlp.XMean, mlp.XScale = cp.XMean, cp.XScale
+	mlp.YMean, mlp.YScale = cp.YMean, cp.YScale
+	mlp.lb = nil
+	if cp.Classes != nil {
+		mlp.lb = NewLabelBinarizer64(0, 1)
+		mlp.lb.Classes = cp.Classes
+	}
+	mlp.optimizer = nil
+	if o := cp.Optimizer; o != nil {
+		switch o.Solver {
+		case "sgd":
+			mlp.optimizer = &SGDOptimizer64{
+				Params:           mlp.packedParameters,
+				LearningRateInit: mlp.LearningRateInit,
+				LearningRate:     o.LearningRate,
+				LRSchedule:       mlp.LearningRate,
+				PowerT:           mlp.PowerT,
+				Momentum:         mlp.Momentum,
+				Nesterov:         mlp.NesterovsMomentum,
+				CycleLength:      mlp.CycleLength,
+				CycleMult:        mlp.CycleMult,
+				LearningRateMin:  mlp.LearningRateMin,
+				velocities:       o.Velocities,
+				cycleEpoch:       o.CycleEpoch,
+				currentCycle:     o.CurrentCycle}
+		case "adam":
+			mlp.optimizer = &AdamOptimizer64{
+				Params:           mlp.packedParameters,
+				LearningRateInit: mlp.LearningRateInit,
+				LearningRate:     o.LearningRate,
+				Beta1:            mlp.Beta1, Beta2: mlp.Beta2, Epsilon: mlp.Epsilon,
+				t: o.T, ms: o.Ms, vs: o.Vs, beta1t: o.Beta1t, beta2t: o.Beta2t,
+			}
+		default:
+			return fmt.Errorf("LoadCheckpoint: unknown solver %s", o.Solver)
+		}
+	}
+	return nil
+}
+
+// goInferenceActivations64 are go statements applying activations to slice a in code emitted by GenerateGoInference. they mirror Activations64
+var goInferenceActivations64 = map[string]string{
+	"identity": "",
+	"logistic": "\tfor j, v := range a {\n\t\ta[j] = 1 / (1 + float64(math.Exp(float64(-v))))\n\t}\n",
+	"tanh":     "\tfor j, v := range a {\n\t\ta[j] = float64(math.Tanh(float64(v)))\n\t}\n",
+	"relu":     "\tfor j, v := range a {\n\t\tif v < 0 {\n\t\t\ta[j] = 0\n\t\t}\n\t}\n",
+	"softmax":  "\tsum := float64(0)\n\tfor j, v := range a {\n\t\ta[j] = float64(math.Exp(float64(v)))\n\t\tsum += a[j]\n\t}\n\tfor j := range a {\n\t\ta[j] /= sum\n\t}\n",
+}
+
+// GenerateGoInference writes to w the source of a standalone go function named funcName
+// computing Predict for one sample: func funcName(x []float64) []float64.
+// weights, Standardize, InputMask, StandardizeTarget and OutputClip are hardcoded and the emitted code only depends on the math package.
+// classifiers also get a funcName+"MaxIdx" helper function
+func (mlp *BaseMultilayerPerceptron64) GenerateGoInference(w io.Writer, funcName string) error {
+	if len(mlp.Coefs) == 0 {
+		return fmt.Errorf("GenerateGoInference: model is not fitted")
+	}
+	formatSlice := func(a []float64) string {
+		s := make([]string, len(a))
+		for i, v := range a {
+			s[i] = strconv.FormatFloat(float64(v), 'g', -1, 64)
+		}
+		return "{" + strings.Join(s, ", ") + "}"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s computes predictions of a %s MLP with layers %v for one sample x.\n", funcName, mlp.Activation, mlp.layerUnits())
+	fmt.Fprintf(&b, "// Code generated by GenerateGoInference. DO NOT EDIT.\n")
+	fmt.Fprintf(&b, "func %s(x []float64) []float64 {\n", funcName)
+	fmt.Fprintf(&b, "\t_ = math.Exp\n")
+	fmt.Fprintf(&b, "\ta := make([]float64, %d)\n", mlp.Coefs[0].Rows)
+	if mlp.Standardize {
+		fmt.Fprintf(&b, "\txMean := []float64%s\n", formatSlice(mlp.XMean))
+		fmt.Fprintf(&b, "\txScale := []float64%s\n", formatSlice(mlp.XScale))
+		fmt.Fprintf(&b, "\tfor j := range a {\n\t\ta[j] = (x[j] - xMean[j]) / xScale[j]\n\t}\n")
+	} else {
+		fmt.Fprintf(&b, "\tcopy(a, x)\n")
+	}
+	if len(mlp.InputMask) > 0 && len(mlp.InputMask) != mlp.Coefs[0].Rows {
+		return fmt.Errorf("GenerateGoInference: InputMask has %d features, expected %d", len(mlp.InputMask), mlp.Coefs[0].Rows)
+	}
+	for j, masked := range mlp.InputMask {
+		if masked {
+			fmt.Fprintf(&b, "\ta[%d] = 0\n", j)
+		}
+	}
+	for i, coefs := range mlp.Coefs {
+		activation := mlp.Activation
+		if i == len(mlp.Coefs)-1 {
+			activation = mlp.OutActivation
+		}
+		code, ok := goInferenceActivations64[activation]
+		if !ok {
+			return fmt.Errorf("GenerateGoInference: unknown activation %s", activation)
+		}
+		fmt.Fprintf(&b, "\t// layer %d\n", i+1)
+		fmt.Fprintf(&b, "\tcoefs%d := [][]float64{\n", i)
+		for r, pos := 0, 0; r < coefs.Rows; r, pos = r+1, pos+coefs.Stride {
+			fmt.Fprintf(&b, "\t\t%s,\n", formatSlice(coefs.Data[pos:pos+coefs.Cols]))
+		}
+		fmt.Fprintf(&b, "\t}\n")
+		fmt.Fprintf(&b, "\tintercepts%d := []float64%s\n", i, formatSlice(mlp.Intercepts[i]))
+		fmt.Fprintf(&b, "\t{\n\t\tout := make([]float64, %d)\n", coefs.Cols)
+		fmt.Fprintf(&b, "\t\tfor i, v := range a {\n\t\t\tfor j := range out {\n\t\t\t\tout[j] += v * coefs%d[i][j]\n\t\t\t}\n\t\t}\n", i)
+		fmt.Fprintf(&b, "\t\tfor j := range out {\n\t\t\tout[j] += intercepts%d[j]\n\t\t}\n", i)
+		fmt.Fprintf(&b, "\t\ta = out\n\t}\n")
+		if code != "" {
+			b.WriteString("\t{\n")
+			for _, line := range strings.SplitAfter(code, "\n") {
+				if line != "" {
+					b.WriteString("\t" + line)
+				}
+			}
+			b.WriteString("\t}\n")
+		}
+	}
+	switch {
+	case mlp.lb != nil:
+		// one output per binarized column of Y
+		fmt.Fprintf(&b, "\tvar y []float64\n")
+		for j, baseCol := 0, 0; j < len(mlp.lb.Classes); j, baseCol = j+1, baseCol+len(mlp.lb.Classes[j]) {
+			fmt.Fprintf(&b, "\tclasses%d := []float64%s\n", j, formatSlice(mlp.lb.Classes[j]))
+			fmt.Fprintf(&b, "\ty = append(y, classes%[1]d[%[4]sMaxIdx(a[%[2]d:%[3]d])])\n", j, baseCol, baseCol+len(mlp.lb.Classes[j]), funcName)
+		}
+		fmt.Fprintf(&b, "\treturn y\n")
+	case mlp.IsClassifier() && mlp.NOutputs == 1:
+		fmt.Fprintf(&b, "\tif a[0] > .5 {\n\t\treturn []float64{1}\n\t}\n\treturn []float64{0}\n")
+	case mlp.IsClassifier():
+		fmt.Fprintf(&b, "\ty := make([]float64, len(a))\n\ty[%sMaxIdx(a)] = 1\n\treturn y\n", funcName)
+	default:
+		if mlp.targetStandardized() {
+			fmt.Fprintf(&b, "\tyMean := []float64%s\n", formatSlice(mlp.YMean))
+			fmt.Fprintf(&b, "\tyScale := []float64%s\n", formatSlice(mlp.YScale))
+			fmt.Fprintf(&b, "\tfor o := range a {\n\t\ta[o] = a[o]*yScale[o] + yMean[o]\n\t}\n")
+		}
+		if lo, hi := mlp.OutputClip[0], mlp.OutputClip[1]; lo < hi {
+			fmt.Fprintf(&b, "\tfor o := range a {\n\t\tif a[o] < %[1]s {\n\t\t\ta[o] = %[1]s\n\t\t} else if a[o] > %[2]s {\n\t\t\ta[o] = %[2]s\n\t\t}\n\t}\n",
+				strconv.FormatFloat(float64(lo), 'g', -1, 64), strconv.FormatFloat(float64(hi), 'g', -1, 64))
+		}
+		fmt.Fprintf(&b, "\treturn a\n")
+	}
+	if mlp.IsClassifier() && (mlp.lb != nil || mlp.NOutputs > 1) {
+		fmt.Fprintf(&b, "}\n\n// %sMaxIdx returns the index of the max of a\n", funcName)
+		fmt.Fprintf(&b, "func %sMaxIdx(a []float64) int {\n\tvar mi int\n\tfor i := range a {\n\t\tif a[i] > a[mi] {\n\t\t\tmi = i\n\t\t}\n\t}\n\treturn mi\n", funcName)
+	}
+	b.WriteString("}\n")
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
 	return err
 }
 
+// NumParameters returns the number of trainable parameters (coefs and intercepts), ie the length of packedParameters.
+// the architecture must be known (ie after Fit or Unmarshal), otherwise 0 is returned
+func (mlp *BaseMultilayerPerceptron64) NumParameters() int {
+	total := 0
+	for _, n := range mlp.LayerNumParameters() {
+		total += n
+	}
+	return total
+}
+
+// LayerNumParameters returns the number of coefs and intercepts of each layer, the first one being fed by the input
+func (mlp *BaseMultilayerPerceptron64) LayerNumParameters() []int {
+	perLayer := make([]int, len(mlp.Coefs))
+	for i, c := range mlp.Coefs {
+		perLayer[i] = (c.Rows + 1) * c.Cols
+	}
+	return perLayer
+}
+
+// layerUnits returns the number of units of each layer, including input and output layers
+func (mlp *BaseMultilayerPerceptron64) layerUnits() []int {
+	units := []int{mlp.Coefs[0].Rows}
+	for _, c := range mlp.Coefs {
+		units = append(units, c.Cols)
+	}
+	return units
+}
+
 // ToDense64 returns w view of m if m is a RawMatrixer, et returns a dense copy of m
 func ToDense64(m Matrix) General64 {
 	if d, ok := m.(General64); ok {
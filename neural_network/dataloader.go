@@ -0,0 +1,11 @@
+package neuralnetwork
+
+import "gonum.org/v1/gonum/mat"
+
+// DataLoader is a source of training batches for FitDataLoader.
+// NextBatch returns the next batch of the current epoch, or ok=false at the end of the epoch.
+// Reset starts a new epoch. it allows data augmentation or custom sampling
+type DataLoader interface {
+	NextBatch() (X, Y *mat.Dense, ok bool)
+	Reset()
+}
@@ -10,3 +10,6 @@ type indexedXY struct {
 func (s indexedXY) Len() int           { return s.idx.Len() }
 func (s indexedXY) Less(i, j int) bool { return s.idx.Less(i, j) }
 func (s indexedXY) Swap(i, j int)      { s.idx.Swap(i, j); s.X.Swap(i, j); s.Y.Swap(i, j) }
+
+// maxShuffleOrders is the maximum number of epochs whose sample order is recorded when RecordShuffleOrder is set
+const maxShuffleOrders = 1000
@@ -25,11 +25,14 @@ var M32 = struct {
 	MaxFloat32 float32
 	Inf        func(int) float32
 	IsNaN      func(float32) bool
+	NaN        func() float32
 	Nextafter  func(x, y float32) float32
+	Cos        func(float32) float32
+	Pi         float32
 	MaxFloatXX floatXX
 }{
 	Ceil: m32.Ceil, Sqrt: m32.Sqrt, Pow: m32.Pow, IsInf: m32.IsInf, Abs: m32.Abs, Exp: m32.Exp, Tanh: m32.Tanh, Log: m32.Log, Log1p: m32.Log1p,
-	MaxFloat32: m32.MaxFloat32, Inf: m32.Inf, IsNaN: m32.IsNaN, Nextafter: m32.Nextafter, MaxFloatXX: m32.MaxFloat32}
+	MaxFloat32: m32.MaxFloat32, Inf: m32.Inf, IsNaN: m32.IsNaN, NaN: m32.NaN, Nextafter: m32.Nextafter, Cos: m32.Cos, Pi: m32.Pi, MaxFloatXX: m32.MaxFloat32}
 
 // M64 has funcs for float64 math
 var M64 = struct {
@@ -45,9 +48,12 @@ var M64 = struct {
 	MaxFloat64 float64
 	Inf        func(int) float64
 	IsNaN      func(float64) bool
+	NaN        func() float64
 	Nextafter  func(x, y float64) float64
+	Cos        func(float64) float64
+	Pi         float64
 }{Ceil: m64.Ceil, Sqrt: m64.Sqrt, Pow: m64.Pow, IsInf: m64.IsInf, Abs: m64.Abs, Exp: m64.Exp, Tanh: m64.Tanh, Log: m64.Log, Log1p: m64.Log1p,
-	MaxFloat64: m64.MaxFloat64, Inf: m64.Inf, IsNaN: m64.IsNaN, Nextafter: m64.Nextafter}
+	MaxFloat64: m64.MaxFloat64, Inf: m64.Inf, IsNaN: m64.IsNaN, NaN: m64.NaN, Nextafter: m64.Nextafter, Cos: m64.Cos, Pi: m64.Pi}
 
 // MXX has funcs for floatXX math
 var MXX = M32
@@ -57,7 +63,7 @@ type blas64Vector = blas64.Vector
 type blasXXVector = blas32.Vector
 
 func dot32(n, xinc, yinc int, x, y []float32) float32 {
-	return blas32.Dot(blas32.Vector{N: n, Inc: xinc, Data: x}, blas32.Vector{Inc: yinc, Data: y})
+	return blas32.Dot(blas32.Vector{N: n, Inc: xinc, Data: x}, blas32.Vector{N: n, Inc: yinc, Data: y})
 }
 func dot64(n, xinc, yinc int, x, y []float64) float64 {
 	return blas64.Dot(blas64.Vector{N: n, Inc: xinc, Data: x}, blas64.Vector{N: n, Inc: yinc, Data: y})
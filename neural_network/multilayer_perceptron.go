@@ -1,7 +1,11 @@
 package neuralnetwork
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
 
 	"gonum.org/v1/gonum/mat"
 )
@@ -12,6 +16,13 @@ type MLPRegressor struct{ BaseMultilayerPerceptron64 }
 // Regressors is the list of regressors in this package
 var Regressors = []base.Predicter{&MLPRegressor{}}
 
+func init() {
+	base.RegisterMarshaler("MLPRegressor", func() base.Marshaler { return &MLPRegressor{} })
+	base.RegisterMarshaler("MLPClassifier", func() base.Marshaler { return &MLPClassifier{} })
+	base.RegisterMarshaler("MLPRegressor32", func() base.Marshaler { return &MLPRegressor32{} })
+	base.RegisterMarshaler("MLPClassifier32", func() base.Marshaler { return &MLPClassifier32{} })
+}
+
 // NewMLPRegressor returns a *MLPRegressor with defaults
 // activation is one of identity,logistic,tanh,relu
 // solver is on of sgd,adam  defaults to "adam"
@@ -45,19 +56,25 @@ func (mlp *MLPRegressor) PredicterClone() base.Predicter {
 // Fit ...
 func (mlp *MLPRegressor) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	checkArrays("MLPRegressor.Fit", X, Y)
 	mlp.fit(X.RawMatrix(), Y.RawMatrix(), false)
 	return mlp
 }
 
 // Predict return the forward result
 func (mlp *MLPRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	checkArrays("MLPRegressor.Predict", base.ToDense(X), nil)
 	Y := base.ToDense(Ymutable)
 	nSamples, _ := X.Dims()
 	if Y.IsEmpty() {
 		*Y = *mat.NewDense(nSamples, mlp.GetNOutputs(), nil)
 	}
 
-	mlp.BaseMultilayerPerceptron64.predict(base.ToDense(X).RawMatrix(), Y.RawMatrix())
+	xb := base.ToDense(X).RawMatrix()
+	if mlp.Standardize {
+		xb = mlp.standardize(xb)
+	}
+	mlp.BaseMultilayerPerceptron64.predict(xb, Y.RawMatrix())
 	return base.FromDense(Ymutable, Y)
 }
 
@@ -70,8 +87,45 @@ func (mlp *MLPRegressor) Score(X, Y mat.Matrix) float64 {
 	return r2Score64(base.ToDense(Y).RawMatrix(), Ypred.RawMatrix())
 }
 
+// FitQuantiles trains the regressor on single output Y with one output per quantile,
+// setting OutputLosses to the "quantile:q" pinball losses. predictions columns are the quantiles in the given order
+func (mlp *MLPRegressor) FitQuantiles(Xmatrix, Ymatrix mat.Matrix, quantiles ...float64) base.Fiter {
+	Y := base.ToDense(Ymatrix)
+	nSamples, nOutputs := Y.Dims()
+	if nOutputs != 1 {
+		panic(fmt.Errorf("FitQuantiles: Y must have a single column, got %d", nOutputs))
+	}
+	mlp.OutputLosses = make([]string, len(quantiles))
+	Yq := mat.NewDense(nSamples, len(quantiles), nil)
+	for o, q := range quantiles {
+		mlp.OutputLosses[o] = fmt.Sprintf("quantile:%g", q)
+		for i := 0; i < nSamples; i++ {
+			Yq.Set(i, o, Y.At(i, 0))
+		}
+	}
+	return mlp.Fit(Xmatrix, Yq)
+}
+
+// FitInterval is FitQuantiles for the 0.05, 0.5 and 0.95 quantiles, to be used with PredictInterval
+func (mlp *MLPRegressor) FitInterval(X, Y mat.Matrix) base.Fiter {
+	return mlp.FitQuantiles(X, Y, .05, .5, .95)
+}
+
+// PredictInterval returns the lower, median and upper predictions (columns 0, 1 and 2) of a regressor trained with FitInterval
+func (mlp *MLPRegressor) PredictInterval(X mat.Matrix) *mat.Dense {
+	if len(mlp.OutputLosses) != 3 {
+		panic(fmt.Errorf("PredictInterval: expected a regressor trained with FitInterval"))
+	}
+	return mlp.Predict(X, nil)
+}
+
 // MLPClassifier ...
-type MLPClassifier struct{ BaseMultilayerPerceptron64 }
+// Classes and ClassesFrequencies are the class values and their proportions in the training labels of last Fit.
+// for a binarized (one-hot or multilabel) Y, Classes are the column indices
+type MLPClassifier struct {
+	BaseMultilayerPerceptron64
+	Classes, ClassesFrequencies []float64
+}
 
 // NewMLPClassifier returns a *MLPClassifier with defaults
 // activation is one of logistic,tanh,relu
@@ -101,12 +155,15 @@ func (*MLPClassifier) IsClassifier() bool { return true }
 // Fit ...
 func (mlp *MLPClassifier) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	checkArrays("MLPClassifier.Fit", X, Y)
+	mlp.Classes, mlp.ClassesFrequencies = classesFrequencies(Y)
 	mlp.BaseMultilayerPerceptron64.Fit(X, Y)
 	return mlp
 }
 
 // Predict return the forward result for MLPClassifier
 func (mlp *MLPClassifier) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	checkArrays("MLPClassifier.Predict", base.ToDense(X), nil)
 	Y := base.ToDense(Ymutable)
 	nSamples, _ := X.Dims()
 	if Y.IsEmpty() {
@@ -132,3 +189,99 @@ func (mlp *MLPClassifier) Score(Xmatrix, Ymatrix mat.Matrix) float64 {
 
 	return accuracyScore64(Y.RawMatrix(), Ypred.RawMatrix())
 }
+
+// PredictProba return the output layer activations for MLPClassifier
+// for binarized Y, columns are the probabilities of each output. for other Y, columns are the probabilities of each class
+func (mlp *MLPClassifier) PredictProba(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsEmpty() {
+		*Y = *mat.NewDense(nSamples, mlp.NOutputs, nil)
+	}
+	xb := base.ToDense(X).RawMatrix()
+	if mlp.Standardize {
+		xb = mlp.standardize(xb)
+	}
+	mlp.predictProbas(xb, Y.RawMatrix())
+	return base.FromDense(Ymutable, Y)
+}
+
+// ROCAUC returns metrics.ROCAUCScore of PredictProba(X) against Y, macro-averaged over outputs.
+// Y labels are binarized by classes seen at Fit
+func (mlp *MLPClassifier) ROCAUC(X, Y mat.Matrix) float64 {
+	Ybin, Yscore := mlp.binarizedScores(X, Y)
+	return metrics.ROCAUCScore(Ybin, Yscore, "macro", nil)
+}
+
+// AveragePrecision returns metrics.AveragePrecisionScore of PredictProba(X) against Y, macro-averaged over outputs.
+// Y labels are binarized by classes seen at Fit
+func (mlp *MLPClassifier) AveragePrecision(X, Y mat.Matrix) float64 {
+	Ybin, Yscore := mlp.binarizedScores(X, Y)
+	return metrics.AveragePrecisionScore(Ybin, Yscore, "macro", nil)
+}
+
+// binarizedScores returns Y with the columns of PredictProba (binarized if labels were binarized at Fit), and PredictProba(X)
+func (mlp *MLPClassifier) binarizedScores(X, Y mat.Matrix) (Ybin, Yscore *mat.Dense) {
+	Ybin = base.ToDense(Y)
+	if mlp.lb != nil {
+		_, yg := mlp.lb.Transform(nil, Ybin)
+		Ybin = mat.NewDense(yg.Rows, yg.Cols, yg.Data)
+	}
+	return Ybin, mlp.PredictProba(X, &mat.Dense{})
+}
+
+// PredictThreshold predicts 1 for each output whose probability is >= thresholds[output], 0 otherwise.
+// thresholds are typically found with metrics.TuneThresholds. Y must have been binarized at Fit
+func (mlp *MLPClassifier) PredictThreshold(X mat.Matrix, Ymutable mat.Mutable, thresholds []float64) *mat.Dense {
+	if mlp.lb != nil {
+		panic(fmt.Errorf("PredictThreshold: Y was not binarized at Fit"))
+	}
+	if len(thresholds) != mlp.NOutputs {
+		panic(fmt.Errorf("PredictThreshold: expected %d thresholds, got %d", mlp.NOutputs, len(thresholds)))
+	}
+	Y := mlp.PredictProba(X, Ymutable)
+	Y.Apply(func(_, o int, v float64) float64 {
+		if v >= thresholds[o] {
+			return 1
+		}
+		return 0
+	}, Y)
+	return base.FromDense(Ymutable, Y)
+}
+
+// checkArrays panics if X or Y contains NaN or Inf
+func checkArrays(op string, X, Y *mat.Dense) {
+	if err := base.CheckArray(X, false); err != nil {
+		panic(fmt.Errorf("%s: X %s", op, err))
+	}
+	if err := base.CheckArray(Y, false); err != nil {
+		panic(fmt.Errorf("%s: Y %s", op, err))
+	}
+}
+
+// classesFrequencies returns the sorted class values of a single column Y and their proportions,
+// or the column indices and column means of a multi-column (binarized) Y
+func classesFrequencies(Y *mat.Dense) (classes, frequencies []float64) {
+	nSamples, nOutputs := Y.Dims()
+	if nOutputs > 1 {
+		classes, frequencies = make([]float64, nOutputs), make([]float64, nOutputs)
+		for o := range classes {
+			classes[o] = float64(o)
+			frequencies[o] = mat.Sum(Y.ColView(o)) / float64(nSamples)
+		}
+		return
+	}
+	counts := make(map[float64]int)
+	for i := 0; i < nSamples; i++ {
+		counts[Y.At(i, 0)]++
+	}
+	for class := range counts {
+		classes = append(classes, class)
+	}
+	sort.Float64s(classes)
+	frequencies = make([]float64, len(classes))
+	for c, class := range classes {
+		frequencies[c] = float64(counts[class]) / float64(nSamples)
+	}
+	return
+}
@@ -0,0 +1,114 @@
+package neuralnetwork
+
+import (
+	"github.com/pa-m/sklearn/base"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// MLPRegressor32 is a MLPRegressor whose training and prediction use float32 (blas32) matrices,
+// halving memory use. X and Y are copied as float32 at Fit
+type MLPRegressor32 struct{ BaseMultilayerPerceptron32 }
+
+// NewMLPRegressor32 returns a *MLPRegressor32 with defaults. parameters are those of NewMLPRegressor
+func NewMLPRegressor32(hiddenLayerSizes []int, activation string, solver string, Alpha float32) *MLPRegressor32 {
+	mlp := &MLPRegressor32{
+		BaseMultilayerPerceptron32: *NewBaseMultilayerPerceptron32(),
+	}
+	mlp.HiddenLayerSizes = hiddenLayerSizes
+	mlp.Activation = activation
+	mlp.Solver = solver
+	mlp.Alpha = Alpha
+	return mlp
+}
+
+// IsClassifier returns false for MLPRegressor32
+func (*MLPRegressor32) IsClassifier() bool { return false }
+
+// PredicterClone allow clone predicter for pipeline on model_selection
+func (mlp *MLPRegressor32) PredicterClone() base.Predicter {
+	if mlp == nil {
+		return nil
+	}
+	clone := *mlp
+	if sourceCloner, ok := clone.RandomState.(base.SourceCloner); ok && sourceCloner != base.SourceCloner(nil) {
+		clone.RandomState = sourceCloner.SourceClone()
+	}
+	return &clone
+}
+
+// Fit ...
+func (mlp *MLPRegressor32) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	checkArrays("MLPRegressor32.Fit", base.ToDense(Xmatrix), base.ToDense(Ymatrix))
+	var X, Y General32
+	X.Copy(Xmatrix)
+	Y.Copy(Ymatrix)
+	mlp.fit(X.RawMatrix(), Y.RawMatrix(), false)
+	return mlp
+}
+
+// Predict return the forward result
+func (mlp *MLPRegressor32) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	checkArrays("MLPRegressor32.Predict", base.ToDense(X), nil)
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsEmpty() {
+		*Y = *mat.NewDense(nSamples, mlp.GetNOutputs(), nil)
+	}
+	mlp.BaseMultilayerPerceptron32.Predict(X, Y)
+	return base.FromDense(Ymutable, Y)
+}
+
+// Score for MLPRegressor32 returns R2Score
+func (mlp *MLPRegressor32) Score(X, Y mat.Matrix) float64 {
+	return mlp.BaseMultilayerPerceptron32.Score(X, Y)
+}
+
+// MLPClassifier32 is a MLPClassifier whose training and prediction use float32 (blas32) matrices,
+// halving memory use. X and Y are copied as float32 at Fit
+type MLPClassifier32 struct{ BaseMultilayerPerceptron32 }
+
+// NewMLPClassifier32 returns a *MLPClassifier32 with defaults. parameters are those of NewMLPClassifier
+func NewMLPClassifier32(hiddenLayerSizes []int, activation string, solver string, Alpha float32) *MLPClassifier32 {
+	mlp := &MLPClassifier32{
+		BaseMultilayerPerceptron32: *NewBaseMultilayerPerceptron32(),
+	}
+	mlp.HiddenLayerSizes = hiddenLayerSizes
+	mlp.Activation = activation
+	mlp.Solver = solver
+	mlp.Alpha = Alpha
+	return mlp
+}
+
+// PredicterClone returns an (possibly unfitted) copy of predicter
+func (mlp *MLPClassifier32) PredicterClone() base.Predicter {
+	clone := *mlp
+	return &clone
+}
+
+// IsClassifier returns true for MLPClassifier32
+func (*MLPClassifier32) IsClassifier() bool { return true }
+
+// Fit ...
+func (mlp *MLPClassifier32) Fit(X, Y mat.Matrix) base.Fiter {
+	checkArrays("MLPClassifier32.Fit", base.ToDense(X), base.ToDense(Y))
+	mlp.BaseMultilayerPerceptron32.Fit(X, Y)
+	return mlp
+}
+
+// Predict return the forward result for MLPClassifier32
+func (mlp *MLPClassifier32) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	checkArrays("MLPClassifier32.Predict", base.ToDense(X), nil)
+	Y := base.ToDense(Ymutable)
+	nSamples, _ := X.Dims()
+	if Y.IsEmpty() {
+		*Y = *mat.NewDense(nSamples, mlp.GetNOutputs(), nil)
+	}
+	mlp.BaseMultilayerPerceptron32.Predict(X, Y)
+	return base.FromDense(Ymutable, Y)
+}
+
+// Score for MLPClassifier32 computes accuracy score
+func (mlp *MLPClassifier32) Score(X, Y mat.Matrix) float64 {
+	return mlp.BaseMultilayerPerceptron32.Score(X, Y)
+}
@@ -0,0 +1,67 @@
+package neuralnetwork
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+var _ base.Predicter = &MLPClassifier32{}
+var _ base.Predicter = &MLPRegressor32{}
+
+func TestMLPClassifier32Mnist(t *testing.T) {
+	X, Y := datasets.LoadMnist()
+	mlp64 := NewMLPClassifier([]int{25}, "logistic", "adam", 0)
+	mlp32 := NewMLPClassifier32([]int{25}, "logistic", "adam", 0)
+	mlp64.RandomState, mlp32.RandomState = base.NewLockedSource(7), base.NewLockedSource(7)
+	mlp64.MaxIter, mlp32.MaxIter = 20, 20
+	mlp64.Fit(X, Y)
+	mlp32.Fit(X, Y)
+	acc64 := mlp64.Score(X, Y)
+	acc32 := mlp32.Score(X, Y)
+	if acc32 < .9 || math.Abs(acc32-acc64) > .01 {
+		t.Errorf("expected comparable accuracies, got float64:%.4f float32:%.4f", acc64, acc32)
+	}
+	if len(mlp32.Predict(X, nil).RawMatrix().Data) != len(Y.RawMatrix().Data) {
+		t.Error("wrong prediction shape")
+	}
+}
+
+func TestMLPRegressor32(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 200, "n_features": 5, "n_informative": 3, "random_state": rand.New(base.NewSource(7))})
+	mlp := NewMLPRegressor32([]int{}, "identity", "adam", 0)
+	mlp.RandomState = base.NewLockedSource(7)
+	mlp.LearningRateInit = .01
+	mlp.MaxIter = 2000
+	mlp.Fit(X, Y)
+	if score := mlp.Score(X, Y); score < .99 {
+		t.Errorf("expected R2 >= .99, got %g", score)
+	}
+}
+
+func TestMLP32FitNaN(t *testing.T) {
+	X := mat.NewDense(4, 2, []float64{0, 0, 0, 1, 1, math.NaN(), 1, 1})
+	Y := mat.NewDense(4, 1, []float64{0, 1, 1, 0})
+	for _, tc := range []struct {
+		expected string
+		fit      func()
+	}{
+		{"MLPRegressor32.Fit: X input contains NaN at row 2, col 1", func() { NewMLPRegressor32([]int{}, "relu", "adam", 0).Fit(X, Y) }},
+		{"MLPClassifier32.Fit: X input contains NaN at row 2, col 1", func() { NewMLPClassifier32([]int{}, "relu", "adam", 0).Fit(X, Y) }},
+	} {
+		func() {
+			defer func() {
+				r := recover()
+				err, ok := r.(error)
+				if !ok || err.Error() != tc.expected {
+					t.Errorf("unexpected panic %v", r)
+				}
+			}()
+			tc.fit()
+		}()
+	}
+}
@@ -1,13 +1,19 @@
 package neuralnetwork
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"image/color"
+	"io"
 	"log"
 	"math"
 	"os"
 	"os/exec"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -397,6 +403,9 @@ func Test_r2Score32(t *testing.T) {
 	if math32.Abs(0.948-r2Score) > eps {
 		t.Errorf("expected 0.948 got %g", r2Score)
 	}
+	if exported := metrics.R2Score32(yTrue, yPred); exported != r2Score {
+		t.Errorf("expected metrics.R2Score32 %g, got %g", r2Score, exported)
+	}
 
 	yTrue = blas32.General{Rows: 3, Cols: 1, Stride: 1, Data: []float32{1, 2, 3}}
 	yPred = blas32.General{Rows: 3, Cols: 1, Stride: 1, Data: []float32{1, 2, 3}}
@@ -422,6 +431,9 @@ func Test_accuracyScore32(t *testing.T) {
 	if actual != expected {
 		t.Errorf("expected %g, got %g", expected, actual)
 	}
+	if exported := metrics.AccuracyScore32(Ytrue, Ypred); exported != actual {
+		t.Errorf("expected metrics.AccuracyScore32 %g, got %g", actual, exported)
+	}
 	Ypred, Ytrue = blas32.General{Rows: 2, Cols: 2, Stride: 2, Data: []float32{0, 1, 1, 1}}, blas32.General{Rows: 2, Cols: 2, Stride: 2, Data: []float32{1, 1, 1, 1}}
 	expected, actual = float32(0.5), accuracyScore32(Ytrue, Ypred)
 	if actual != expected {
@@ -433,7 +445,8 @@ func TestMLPRegressor(t *testing.T) {
 	mlp := NewMLPRegressor([]int{}, "relu", "adam", 0)
 	mlp = mlp.PredicterClone().(*MLPRegressor) // for coverage
 	mlp.IsClassifier()                         // for coverage
-	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 2})
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 2, "random_state": rand.New(base.NewSource(1))})
+	mlp.RandomState = base.NewLockedSource(1)
 	mlp.LearningRateInit = .1
 	mlp.Fit(X, Y)
 	if mlp.Score(X, Y) < .95 {
@@ -548,3 +561,1784 @@ func ExampleMLPClassifier_Fit_iris() {
 	// Output:
 	// ok
 }
+
+func TestMLPClassifierStandardize(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	X, Y := ds.X, ds.Y
+	newMLP := func() *MLPClassifier {
+		mlp := NewMLPClassifier([]int{10}, "logistic", "adam", 0)
+		mlp.RandomState = base.NewLockedSource(1)
+		mlp.Shuffle = false
+		mlp.MaxIter = 50
+		mlp.LearningRateInit = .01
+		return mlp
+	}
+	log.SetPrefix("TestMLPClassifierStandardize:")
+	defer log.SetPrefix("")
+
+	p := pipeline.MakePipeline(preprocessing.NewStandardScaler(), newMLP())
+	p.Fit(X, Y)
+	expected := p.Score(X, Y)
+
+	mlp := newMLP()
+	mlp.Standardize = true
+	mlp.Fit(X, Y)
+	if len(mlp.XMean) != 30 || len(mlp.XScale) != 30 {
+		t.Fatalf("expected 30 feature statistics, got %d,%d", len(mlp.XMean), len(mlp.XScale))
+	}
+	actual := mlp.Score(X, Y)
+	if expected < .95 || math.Abs(expected-actual) > 1e-3 {
+		t.Errorf("expected accuracy %g, got %g", expected, actual)
+	}
+}
+
+func TestMLPRegressorAccumulationSteps(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 48, "n_features": 3, "random_state": rand.New(base.NewLockedSource(1))})
+	newMLP := func(batchSize, accumulationSteps int) *MLPRegressor {
+		mlp := NewMLPRegressor([]int{5}, "tanh", "sgd", 1e-2)
+		mlp.RandomState = base.NewLockedSource(1)
+		mlp.Shuffle = false
+		mlp.WeightDecay = 1e-3
+		mlp.MaxIter = 5
+		mlp.BatchSize = batchSize
+		mlp.AccumulationSteps = accumulationSteps
+		return mlp
+	}
+	log.SetPrefix("TestMLPRegressorAccumulationSteps:")
+	defer log.SetPrefix("")
+
+	expected := newMLP(16, 0)
+	expected.Fit(X, Y)
+	actual := newMLP(8, 2)
+	actual.Fit(X, Y)
+	if !floats.EqualApprox(expected.packedParameters, actual.packedParameters, 1e-12) {
+		t.Errorf("expected\n%g\ngot\n%g", expected.packedParameters, actual.packedParameters)
+	}
+}
+
+func TestMLPRegressorCheckpoint(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 3, "random_state": rand.New(base.NewLockedSource(1))})
+	newMLP := func(maxIter int) *MLPRegressor {
+		mlp := NewMLPRegressor([]int{5}, "relu", "adam", 1e-4)
+		mlp.RandomState = base.NewLockedSource(1)
+		mlp.Shuffle = false
+		mlp.BatchSize = 20
+		mlp.LearningRateInit = .01
+		mlp.MaxIter = maxIter
+		return mlp
+	}
+	log.SetPrefix("TestMLPRegressorCheckpoint:")
+	defer log.SetPrefix("")
+
+	expected := newMLP(10)
+	expected.Fit(X, Y)
+
+	mlp := newMLP(5)
+	mlp.Fit(X, Y)
+	buf, err := mlp.Checkpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := newMLP(5)
+	if err := resumed.LoadCheckpoint(buf); err != nil {
+		t.Fatal(err)
+	}
+	resumed.WarmStart = true
+	resumed.Fit(X, Y)
+	if resumed.NIter != expected.NIter {
+		t.Errorf("expected NIter %d, got %d", expected.NIter, resumed.NIter)
+	}
+	if !floats.Equal(expected.LossCurve, resumed.LossCurve) {
+		t.Errorf("expected loss curve\n%g\ngot\n%g", expected.LossCurve, resumed.LossCurve)
+	}
+}
+
+func TestMLPClassifierPredictThreshold(t *testing.T) {
+	X0, Y0 := datasets.LoadMicroChipTest()
+	// make the set imbalanced by keeping only one positive sample out of 4
+	var rows []int
+	nPositives := 0
+	for i := 0; i < Y0.RawMatrix().Rows; i++ {
+		if Y0.At(i, 0) == 1 {
+			nPositives++
+			if nPositives%4 != 0 {
+				continue
+			}
+		}
+		rows = append(rows, i)
+	}
+	X, Y := mat.NewDense(len(rows), 2, nil), mat.NewDense(len(rows), 1, nil)
+	for i, row := range rows {
+		X.SetRow(i, X0.RawRowView(row))
+		Y.SetRow(i, Y0.RawRowView(row))
+	}
+	poly := preprocessing.NewPolynomialFeatures(6)
+	poly.IncludeBias = false
+	Xp, _ := poly.FitTransform(X, nil)
+
+	mlp := NewMLPClassifier([]int{}, "logistic", "lbfgs", 1)
+	mlp.RandomState = base.NewLockedSource(1)
+	log.SetPrefix("TestMLPClassifierPredictThreshold:")
+	defer log.SetPrefix("")
+	mlp.Fit(Xp, Y)
+
+	Yproba := mlp.PredictProba(Xp, nil)
+	thresholds, scores := metrics.TuneThresholds(Y, Yproba, "f1")
+
+	f1 := func(Ypred *mat.Dense) float64 {
+		_, _, f, _ := metrics.PrecisionRecallFScoreSupport(Y, Ypred, 1, nil, 1, "", nil, nil)
+		return f
+	}
+	f1Default := f1(mlp.PredictThreshold(Xp, nil, []float64{.5}))
+	f1Tuned := f1(mlp.PredictThreshold(Xp, nil, thresholds))
+	if math.Abs(f1Tuned-scores[0]) > 1e-9 {
+		t.Errorf("expected f1 %g at threshold %g, got %g", scores[0], thresholds[0], f1Tuned)
+	}
+	if f1Tuned <= f1Default {
+		t.Errorf("expected tuned threshold %g f1 %g > f1 at .5 %g", thresholds[0], f1Tuned, f1Default)
+	}
+}
+
+func TestMLPClassifierFitMatrixViews(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	nSamples, _ := ds.X.Dims()
+	X0, _ := preprocessing.NewStandardScaler().FitTransform(ds.X, nil)
+	// Xview is a strided view on the first 10 features
+	Xview := X0.Slice(0, nSamples, 0, 10)
+	Xdense := mat.DenseCopyOf(Xview)
+	// Xmatrix is neither a *mat.Dense nor a RawMatrixer
+	Xmatrix := base.MatTranspose{Matrix: Xview.T()}
+
+	fit := func(X mat.Matrix, shuffle bool) *MLPClassifier {
+		mlp := NewMLPClassifier([]int{5}, "logistic", "adam", 1e-4)
+		mlp.RandomState = base.NewLockedSource(1)
+		mlp.Shuffle = shuffle
+		mlp.MaxIter = 20
+		mlp.LearningRateInit = .01
+		mlp.Fit(X, ds.Y)
+		return mlp
+	}
+	log.SetPrefix("TestMLPClassifierFitMatrixViews:")
+	defer log.SetPrefix("")
+	for _, shuffle := range []bool{false, true} {
+		expected := fit(Xdense, shuffle)
+		for name, X := range map[string]mat.Matrix{"slice": Xview, "matrix": Xmatrix} {
+			actual := fit(X, shuffle)
+			if !floats.Equal(expected.packedParameters, actual.packedParameters) {
+				t.Errorf("shuffle=%v %s: parameters differ from dense fit", shuffle, name)
+			}
+			if accuracy := actual.Score(X, ds.Y); accuracy < .9 {
+				t.Errorf("shuffle=%v %s: expected accuracy >= .9, got %g", shuffle, name, accuracy)
+			}
+		}
+	}
+}
+
+func TestStratifiedValidationSplit(t *testing.T) {
+	// 95/5 imbalanced set with positive samples first, so that the last 10% rows are all negative
+	nSamples := 100
+	X, Y := mat.NewDense(nSamples, 1, nil), mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		X.Set(i, 0, float64(i))
+		if i < 5 {
+			Y.Set(i, 0, 1)
+		}
+	}
+	x, y := X.RawMatrix(), Y.RawMatrix()
+	idx := make([]int, nSamples)
+	for i := range idx {
+		idx[i] = i
+	}
+	rndShuffle := rand.New(base.NewLockedSource(1)).Shuffle
+	testSize := stratifiedValidationSplit64(indexedXY{idx: sort.IntSlice(idx), X: general64FastSwap(x), Y: general64FastSwap(y)}, y, 10, rndShuffle)
+	if testSize != 10 {
+		t.Errorf("expected 10 validation samples, got %d", testSize)
+	}
+	counts := map[float64]int{}
+	for i := nSamples - testSize; i < nSamples; i++ {
+		counts[Y.At(i, 0)]++
+	}
+	if counts[0] == 0 || counts[1] == 0 {
+		t.Errorf("expected both classes in validation set, got %v", counts)
+	}
+	for i := 0; i < nSamples; i++ {
+		if X.At(i, 0) != float64(idx[i]) || Y.At(i, 0) != map[bool]float64{true: 1, false: 0}[idx[i] < 5] {
+			t.Fatalf("rows of X and Y are not consistently moved")
+		}
+	}
+
+	// Fit with early stopping must restore X and Y order
+	sort.Sort(indexedXY{idx: sort.IntSlice(idx), X: general64FastSwap(x), Y: general64FastSwap(y)})
+	mlp := NewMLPClassifier([]int{}, "logistic", "adam", 0)
+	mlp.RandomState = base.NewLockedSource(1)
+	mlp.Shuffle = false
+	mlp.EarlyStopping = true
+	mlp.MaxIter = 5
+	log.SetPrefix("TestStratifiedValidationSplit:")
+	defer log.SetPrefix("")
+	mlp.BaseMultilayerPerceptron64.Fit(X, Y)
+	if len(mlp.ValidationScores) == 0 {
+		t.Error("expected validation scores")
+	}
+	for i := 0; i < nSamples; i++ {
+		if X.At(i, 0) != float64(i) {
+			t.Fatalf("X order was not restored")
+		}
+	}
+}
+
+func TestMLPClassifierLayerGradNorms(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	X, Y := preprocessing.NewStandardScaler().FitTransform(ds.X, ds.Y)
+	mlp := NewMLPClassifier([]int{10, 10, 10}, "logistic", "sgd", 0)
+	mlp.RandomState = base.NewLockedSource(1)
+	mlp.MaxIter = 5
+	log.SetPrefix("TestMLPClassifierLayerGradNorms:")
+	defer log.SetPrefix("")
+	mlp.Fit(X, Y)
+	if len(mlp.LayerGradNorms) != mlp.NIter {
+		t.Fatalf("expected %d LayerGradNorms entries, got %d", mlp.NIter, len(mlp.LayerGradNorms))
+	}
+	for _, norms := range mlp.LayerGradNorms {
+		if len(norms) != 4 {
+			t.Fatalf("expected 4 layer norms, got %d", len(norms))
+		}
+		// vanishing gradient: norms shrink toward the input layer
+		for i := 0; i < len(norms)-1; i++ {
+			if !(norms[i] < norms[i+1]) {
+				t.Errorf("expected decreasing gradient norms toward input layer, got %g", norms)
+				break
+			}
+		}
+	}
+}
+
+func TestGenerateGoInference(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	log.SetPrefix("TestGenerateGoInference:")
+	defer log.SetPrefix("")
+	ds := datasets.LoadIris()
+	X, Y := ds.X, ds.Y
+	reg := NewMLPRegressor([]int{5, 4}, "logistic", "lbfgs", 1e-4)
+	reg.RandomState = base.NewLockedSource(7)
+	reg.Standardize = true
+	reg.MaxIter = 50
+	Xreg := mat.DenseCopyOf(X.Slice(0, 150, 0, 3))
+	reg.Fit(Xreg, mat.DenseCopyOf(X.Slice(0, 150, 3, 4)))
+	// InputMask, StandardizeTarget and OutputClip are applied by the generated code as by Predict
+	regMasked := NewMLPRegressor([]int{5}, "tanh", "lbfgs", 1e-4)
+	regMasked.RandomState = base.NewLockedSource(7)
+	regMasked.Standardize = true
+	regMasked.StandardizeTarget = true
+	regMasked.InputMask = []bool{false, true, false}
+	regMasked.OutputClip = [2]float64{.3, 2}
+	regMasked.MaxIter = 50
+	regMasked.Fit(Xreg, mat.DenseCopyOf(X.Slice(0, 150, 3, 4)))
+	if Ypred := regMasked.Predict(Xreg, nil); Ypred.At(0, 0) != .3 || Ypred.At(120, 0) != 2 {
+		t.Fatalf("expected clipped predictions for rows 0 and 120, got %g and %g", Ypred.At(0, 0), Ypred.At(120, 0))
+	}
+	clf := NewMLPClassifier([]int{8}, "relu", "adam", 1e-4)
+	clf.RandomState = base.NewLockedSource(7)
+	clf.Standardize = true
+	clf.MaxIter = 100
+	clf.Fit(X, Y)
+
+	type model struct {
+		name string
+		mlp  interface {
+			GenerateGoInference(io.Writer, string) error
+			Predict(mat.Matrix, mat.Mutable) *mat.Dense
+		}
+		X mat.Matrix
+	}
+	models := []model{{"regressor", reg, Xreg}, {"regressorMasked", regMasked, Xreg}, {"classifier", clf, X}}
+	src := &strings.Builder{}
+	src.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"math\"\n)\n\n")
+	main := &strings.Builder{}
+	main.WriteString("func main() {\n")
+	var expected []string
+	for _, m := range models {
+		if err := m.mlp.GenerateGoInference(src, m.name); err != nil {
+			t.Fatal(err)
+		}
+		src.WriteString("\n")
+		Ypred := m.mlp.Predict(m.X, nil)
+		for _, i := range []int{0, 60, 120, 149} {
+			row := mat.Row(nil, i, m.X)
+			fmt.Fprintf(main, "\tfmt.Println(%s(%#v))\n", m.name, row)
+			expected = append(expected, fmt.Sprint(Ypred.RawRowView(i)))
+		}
+	}
+	main.WriteString("}\n")
+	dir, err := os.MkdirTemp("", "gogeninference")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := dir + "/main.go"
+	if err := os.WriteFile(filename, []byte(src.String()+main.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(goTool, "run", filename)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=off", "GOFLAGS=")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	actual := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(expected), len(actual), out)
+	}
+	for i := range expected {
+		var e, a []float64
+		for _, s := range strings.Fields(strings.Trim(expected[i], "[]")) {
+			v, _ := strconv.ParseFloat(s, 64)
+			e = append(e, v)
+		}
+		for _, s := range strings.Fields(strings.Trim(actual[i], "[]")) {
+			v, _ := strconv.ParseFloat(s, 64)
+			a = append(a, v)
+		}
+		if !floats.EqualApprox(e, a, 1e-9) {
+			t.Errorf("line %d: expected %s, got %s", i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestPredictStream(t *testing.T) {
+	X, Y := datasets.LoadMicroChipTest()
+	mlp := NewMLPClassifier([]int{10}, "relu", "lbfgs", 1e-4)
+	mlp.RandomState = base.NewLockedSource(7)
+	mlp.MaxIter = 100
+	log.SetPrefix("TestPredictStream:")
+	defer log.SetPrefix("")
+	mlp.Fit(X, Y)
+	expected := mlp.Predict(X, nil)
+
+	in := &strings.Builder{}
+	nSamples, _ := X.Dims()
+	for i := 0; i < nSamples; i++ {
+		fmt.Fprintf(in, "%s,%s\n", strconv.FormatFloat(X.At(i, 0), 'g', -1, 64), strconv.FormatFloat(X.At(i, 1), 'g', -1, 64))
+	}
+	out := &strings.Builder{}
+	// batch size not dividing nSamples to check the last partial batch
+	if err := base.PredictStream(mlp, strings.NewReader(in.String()), out, 25); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != nSamples {
+		t.Fatalf("expected %d predictions, got %d", nSamples, len(lines))
+	}
+	for i, line := range lines {
+		v, err := strconv.ParseFloat(line, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != expected.At(i, 0) {
+			t.Errorf("row %d: expected %g, got %g", i, expected.At(i, 0), v)
+		}
+	}
+	if err := base.PredictStream(mlp, strings.NewReader("1,a\n"), out, 25); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestWritePredictionsCSV(t *testing.T) {
+	ds := datasets.LoadIris()
+	X, _ := preprocessing.NewStandardScaler().FitTransform(ds.X, nil)
+	mlp := NewMLPClassifier([]int{10}, "relu", "lbfgs", 1e-4)
+	mlp.RandomState = base.NewLockedSource(7)
+	mlp.MaxIter = 50
+	log.SetPrefix("TestWritePredictionsCSV:")
+	defer log.SetPrefix("")
+	mlp.Fit(X, ds.Y)
+	out := &strings.Builder{}
+	if err := base.WritePredictionsCSV(out, mlp.Predict(X, &mat.Dense{}), mlp.Classes, mlp.PredictProba(X, &mat.Dense{})); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if expected := "label,proba_0,proba_1,proba_2"; lines[0] != expected {
+		t.Errorf("expected header %s, got %s", expected, lines[0])
+	}
+	if nSamples, _ := X.Dims(); len(lines) != nSamples+1 {
+		t.Fatalf("expected %d rows, got %d", nSamples, len(lines)-1)
+	}
+	for i, line := range lines[1:] {
+		fields := strings.Split(line, ",")
+		proba := make([]float64, len(fields)-1)
+		for c := range proba {
+			proba[c], _ = strconv.ParseFloat(fields[c+1], 64)
+		}
+		if expected := strconv.FormatFloat(mlp.Classes[floats.MaxIdx(proba)], 'g', -1, 64); fields[0] != expected {
+			t.Errorf("row %d: expected label %s (argmax of %v), got %s", i, expected, proba, fields[0])
+		}
+	}
+	// without predictions, labels are the argmax of probabilities
+	out2 := &strings.Builder{}
+	if err := base.WritePredictionsCSV(out2, nil, mlp.Classes, mlp.PredictProba(X, &mat.Dense{})); err != nil {
+		t.Fatal(err)
+	}
+	if out2.String() != out.String() {
+		t.Error("expected the same CSV without predictions")
+	}
+}
+
+func TestMLPClassifierSklearnCoefs(t *testing.T) {
+	X, Y := datasets.LoadMnist()
+	mlp := NewMLPClassifier([]int{25}, "logistic", "adam", 0)
+	mlp.RandomState = base.NewLockedSource(7)
+	mlp.MaxIter = 2
+	log.SetPrefix("TestMLPClassifierSklearnCoefs:")
+	defer log.SetPrefix("")
+	mlp.Fit(X, Y)
+	buf, err := json.Marshal(map[string]interface{}{"coefs_": mlp.SklearnCoefs(), "intercepts_": mlp.SklearnIntercepts()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sk struct {
+		Coefs      [][][]float64 `json:"coefs_"`
+		Intercepts [][]float64   `json:"intercepts_"`
+	}
+	if err = json.Unmarshal(buf, &sk); err != nil {
+		t.Fatal(err)
+	}
+	shapes := make([][2]int, len(sk.Coefs))
+	for i, c := range sk.Coefs {
+		shapes[i] = [2]int{len(c), len(c[0])}
+	}
+	if fmt.Sprint(shapes) != "[[400 25] [25 10]]" || len(sk.Intercepts) != 2 || len(sk.Intercepts[0]) != 25 || len(sk.Intercepts[1]) != 10 {
+		t.Errorf("wrong shapes: coefs %v intercepts %d", shapes, len(sk.Intercepts))
+	}
+	if sk.Coefs[1][3][7] != mlp.Coefs[1].Data[3*mlp.Coefs[1].Stride+7] || sk.Intercepts[1][7] != mlp.Intercepts[1][7] {
+		t.Error("wrong coefs_ layout")
+	}
+	// the scikit-learn layout is the one expected by Unmarshal
+	var mlp2 BaseMultilayerPerceptron64
+	if err = mlp2.Unmarshal(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mlp2.SklearnCoefs(), sk.Coefs) || !reflect.DeepEqual(mlp2.SklearnIntercepts(), sk.Intercepts) {
+		t.Error("Unmarshal of SklearnCoefs and SklearnIntercepts differs")
+	}
+}
+
+func TestMLPClassifierPretrain(t *testing.T) {
+	X, Y := datasets.LoadMnist()
+	nSamples, _ := X.Dims()
+	// 100 labeled samples (samples are sorted by class), the others are used for evaluation
+	var labeled, unlabeled []int
+	for i := 0; i < nSamples; i++ {
+		if i%50 == 0 {
+			labeled = append(labeled, i)
+		} else {
+			unlabeled = append(unlabeled, i)
+		}
+	}
+	rows := func(M *mat.Dense, indices []int) *mat.Dense {
+		_, c := M.Dims()
+		out := mat.NewDense(len(indices), c, nil)
+		for i, j := range indices {
+			out.SetRow(i, M.RawRowView(j))
+		}
+		return out
+	}
+	Xl, Yl, Xt, Yt := rows(X, labeled), rows(Y, labeled), rows(X, unlabeled), rows(Y, unlabeled)
+	log.SetPrefix("TestMLPClassifierPretrain:")
+	defer log.SetPrefix("")
+	newMLP := func() *MLPClassifier {
+		mlp := NewMLPClassifier([]int{25}, "logistic", "adam", 1e-4)
+		mlp.RandomState = base.NewLockedSource(7)
+		mlp.MaxIter = 30
+		return mlp
+	}
+	random := newMLP()
+	random.Fit(Xl, Yl)
+
+	pretrained := newMLP()
+	// pretrain the autoencoder on all (unlabeled) samples with lbfgs
+	pretrained.Solver, pretrained.MaxIter = "lbfgs", 50
+	pretrained.Pretrain(X)
+	pretrained.Solver, pretrained.MaxIter = "adam", 30
+	pretrained.Fit(Xl, Yl)
+
+	accRandom, accPretrained := random.Score(Xt, Yt), pretrained.Score(Xt, Yt)
+	if accPretrained < accRandom+.03 {
+		t.Errorf("expected pretraining to improve accuracy, got %.4f with random init and %.4f with pretraining", accRandom, accPretrained)
+	}
+}
+
+// sliceLoader yields consecutive row slices of X and Y
+type sliceLoader struct {
+	X, Y      *mat.Dense
+	batchSize int
+	pos       int
+	resets    int
+}
+
+func (l *sliceLoader) Reset() { l.pos = 0; l.resets++ }
+
+func (l *sliceLoader) NextBatch() (X, Y *mat.Dense, ok bool) {
+	nSamples, nFeatures := l.X.Dims()
+	_, nOutputs := l.Y.Dims()
+	if l.pos >= nSamples {
+		return nil, nil, false
+	}
+	end := l.pos + l.batchSize
+	if end > nSamples {
+		end = nSamples
+	}
+	X, Y = l.X.Slice(l.pos, end, 0, nFeatures).(*mat.Dense), l.Y.Slice(l.pos, end, 0, nOutputs).(*mat.Dense)
+	l.pos = end
+	return X, Y, true
+}
+
+func TestMLPRegressorFitDataLoader(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 4, "random_state": rand.New(base.NewSource(7))})
+	newMLP := func() *MLPRegressor {
+		mlp := NewMLPRegressor([]int{8}, "relu", "adam", 1e-4)
+		mlp.RandomState = base.NewLockedSource(7)
+		mlp.Shuffle = false
+		mlp.BatchSize = 20
+		mlp.MaxIter = 10
+		return mlp
+	}
+	inMemory := newMLP()
+	inMemory.Fit(X, Y)
+	loader := &sliceLoader{X: X, Y: Y, batchSize: 20}
+	fromLoader := newMLP()
+	fromLoader.FitDataLoader(loader)
+	if loader.resets != 10 {
+		t.Errorf("expected one Reset per epoch, got %d", loader.resets)
+	}
+	if !floats.EqualApprox(inMemory.LossCurve, fromLoader.LossCurve, 1e-12) {
+		t.Errorf("loss curves differ:\n%.6g\n%.6g", inMemory.LossCurve, fromLoader.LossCurve)
+	}
+	if !floats.EqualApprox(inMemory.packedParameters, fromLoader.packedParameters, 1e-12) {
+		t.Error("weights differ")
+	}
+}
+
+func TestMLPClassifierComputeLossAndGrad(t *testing.T) {
+	X, Y := datasets.LoadMicroChipTest()
+	mlp := NewMLPClassifier([]int{5}, "relu", "adam", 1e-4)
+	mlp.RandomState = base.NewLockedSource(7)
+	mlp.Shuffle = false
+	mlp.WeightDecay = 1e-3
+	mlp.MaxIter = 1
+	log.SetPrefix("TestMLPClassifierComputeLossAndGrad:")
+	defer log.SetPrefix("")
+	mlp.Fit(X, Y)
+
+	params := append([]float64{}, mlp.packedParameters...)
+	loss, grad := mlp.ComputeLossAndGrad(X, Y)
+	if !floats.Equal(params, mlp.packedParameters) {
+		t.Error("ComputeLossAndGrad changed weights")
+	}
+	// a warm started Fit on one minibatch computes its gradient with the same weights
+	mlp.WarmStart = true
+	mlp.Fit(X, Y)
+	if math.Abs(loss-mlp.Loss) > 1e-12 {
+		t.Errorf("expected loss %g, got %g", mlp.Loss, loss)
+	}
+	if !floats.EqualApprox(grad, mlp.packedGrads, 1e-12) {
+		t.Errorf("expected gradient %.4g, got %.4g", mlp.packedGrads, grad)
+	}
+}
+
+func TestMLPRegressorOutputClip(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 3, "random_state": rand.New(base.NewSource(7))})
+	mlp := NewMLPRegressor([]int{8}, "relu", "adam", 1e-4)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 20
+	mlp.Fit(X, Y)
+	unclipped := mlp.Predict(X, nil)
+	lo, hi := mat.Min(unclipped), mat.Max(unclipped)
+	clipLo, clipHi := lo+(hi-lo)/4, hi-(hi-lo)/4
+	// default OutputClip leaves outputs untouched
+	mlp.OutputClip = [2]float64{}
+	if !mat.Equal(unclipped, mlp.Predict(X, nil)) {
+		t.Error("unset OutputClip changed predictions")
+	}
+	mlp.OutputClip = [2]float64{clipLo, clipHi}
+	clipped := mlp.Predict(X, nil)
+	if mat.Min(clipped) != clipLo || mat.Max(clipped) != clipHi {
+		t.Errorf("expected predictions clamped to [%g,%g], got [%g,%g]", clipLo, clipHi, mat.Min(clipped), mat.Max(clipped))
+	}
+	r, c := clipped.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			yu, yc := unclipped.At(i, j), clipped.At(i, j)
+			expected := math.Max(clipLo, math.Min(clipHi, yu))
+			if yc != expected {
+				t.Errorf("sample %d: expected %g got %g", i, expected, yc)
+			}
+		}
+	}
+	// OutputClip survives Marshal/Unmarshal
+	buf, err := mlp.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &MLPRegressor{}
+	if err = loaded.Unmarshal(buf); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.OutputClip != mlp.OutputClip {
+		t.Errorf("expected OutputClip %v got %v", mlp.OutputClip, loaded.OutputClip)
+	}
+}
+
+func TestMLPClassifierNInit(t *testing.T) {
+	// xor with 2 hidden units has poor local minima
+	X := mat.NewDense(4, 2, []float64{0, 0, 0, 1, 1, 0, 1, 1})
+	Y := mat.NewDense(4, 1, []float64{0, 1, 1, 0})
+	meanLoss := func(nInit int) float64 {
+		sum := 0.
+		for seed := uint64(1); seed <= 10; seed++ {
+			mlp := NewMLPClassifier([]int{2}, "logistic", "lbfgs", 0)
+			mlp.RandomState = base.NewSource(seed)
+			mlp.MaxIter = 100
+			mlp.NInit = nInit
+			mlp.Fit(X, Y)
+			if nInit > 1 && mlp.NInit != nInit {
+				t.Errorf("NInit changed to %d", mlp.NInit)
+			}
+			sum += mlp.Loss
+		}
+		return sum / 10
+	}
+	if loss1, loss5 := meanLoss(1), meanLoss(5); loss5 >= loss1 {
+		t.Errorf("expected lower mean loss with NInit=5, got %g >= %g", loss5, loss1)
+	}
+}
+
+func TestMLPRegressorFitNaN(t *testing.T) {
+	X := mat.NewDense(4, 2, []float64{0, 0, 0, 1, 1, math.NaN(), 1, 1})
+	Y := mat.NewDense(4, 1, []float64{0, 1, 1, 0})
+	defer func() {
+		r := recover()
+		err, ok := r.(error)
+		if !ok || err.Error() != "MLPRegressor.Fit: X input contains NaN at row 2, col 1" {
+			t.Errorf("unexpected panic %v", r)
+		}
+	}()
+	NewMLPRegressor([]int{}, "relu", "adam", 0).Fit(X, Y)
+}
+
+func TestMLPRegressorOutputActivation(t *testing.T) {
+	nSamples := 200
+	X, Y := mat.NewDense(nSamples, 1, nil), mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		x := 6*float64(i)/float64(nSamples) - 3
+		X.Set(i, 0, x)
+		Y.Set(i, 0, math.Tanh(2*x))
+	}
+	mlp := NewMLPRegressor([]int{5}, "relu", "lbfgs", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 200
+	mlp.OutputActivation = "tanh"
+	mlp.Fit(X, Y)
+	if mlp.OutActivation != "tanh" || mlp.LossFuncName != "square_loss" {
+		t.Fatalf("unexpected output activation %s and loss %s", mlp.OutActivation, mlp.LossFuncName)
+	}
+	Ypred := mlp.Predict(X, nil)
+	// final layer is tanh(relu(X*Coefs[0]+Intercepts[0])*Coefs[1]+Intercepts[1])
+	for i := 0; i < nSamples; i++ {
+		z := mlp.Intercepts[1][0]
+		for j := 0; j < 5; j++ {
+			z += math.Max(0, X.At(i, 0)*mlp.Coefs[0].Data[j]+mlp.Intercepts[0][j]) * mlp.Coefs[1].Data[j]
+		}
+		if expected := math.Tanh(z); math.Abs(expected-Ypred.At(i, 0)) > 1e-12 {
+			t.Fatalf("sample %d: expected %g got %g", i, expected, Ypred.At(i, 0))
+		}
+	}
+	if score := mlp.Score(X, Y); score < .95 {
+		t.Errorf("expected R2>=.95, got %g", score)
+	}
+}
+
+func TestMLPClassifierClassesFrequencies(t *testing.T) {
+	// imbalanced binary set: 10% of positive samples
+	nSamples := 100
+	X, Y := mat.NewDense(nSamples, 1, nil), mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		X.Set(i, 0, float64(i))
+		if i%10 == 0 {
+			Y.Set(i, 0, 1)
+		}
+	}
+	mlp := NewMLPClassifier([]int{}, "relu", "adam", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 5
+	mlp.Fit(X, Y)
+	if !floats.Equal([]float64{0, 1}, mlp.Classes) || !floats.EqualApprox([]float64{.9, .1}, mlp.ClassesFrequencies, 1e-12) {
+		t.Errorf("unexpected classes %g frequencies %g", mlp.Classes, mlp.ClassesFrequencies)
+	}
+	// frequencies reflect training labels, not predictions
+	mlp.Predict(X, nil)
+	if !floats.EqualApprox([]float64{.9, .1}, mlp.ClassesFrequencies, 1e-12) {
+		t.Errorf("frequencies changed at Predict: %g", mlp.ClassesFrequencies)
+	}
+	// class values are sorted
+	Y = mat.NewDense(4, 1, []float64{7, 2, 7, 5})
+	mlp.Fit(mat.NewDense(4, 1, []float64{0, 1, 2, 3}), Y)
+	if !floats.Equal([]float64{2, 5, 7}, mlp.Classes) || !floats.EqualApprox([]float64{.25, .25, .5}, mlp.ClassesFrequencies, 1e-12) {
+		t.Errorf("unexpected classes %g frequencies %g", mlp.Classes, mlp.ClassesFrequencies)
+	}
+}
+
+func TestMLPRegressorRecordShuffleOrder(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 50, "n_features": 3, "random_state": rand.New(base.NewSource(7))})
+	newMLP := func(maxIter int, shuffle bool) *MLPRegressor {
+		mlp := NewMLPRegressor([]int{5}, "relu", "sgd", 1e-4)
+		mlp.RandomState = base.NewSource(7)
+		mlp.Shuffle = shuffle
+		mlp.BatchSize = 10
+		mlp.MaxIter = maxIter
+		mlp.RecordShuffleOrder = true
+		return mlp
+	}
+	log.SetPrefix("TestMLPRegressorRecordShuffleOrder:")
+	defer log.SetPrefix("")
+
+	mlp1, mlp2 := newMLP(3, true), newMLP(3, true)
+	mlp1.Fit(X, Y)
+	mlp2.Fit(X, Y)
+	if len(mlp1.ShuffleOrders) != 3 || !reflect.DeepEqual(mlp1.ShuffleOrders, mlp2.ShuffleOrders) {
+		t.Errorf("expected identical orders for 3 epochs, got\n%v\n%v", mlp1.ShuffleOrders, mlp2.ShuffleOrders)
+	}
+	if reflect.DeepEqual(mlp1.ShuffleOrders[0], mlp1.ShuffleOrders[1]) {
+		t.Error("expected different orders for each epoch")
+	}
+	sorted := append([]int(nil), mlp1.ShuffleOrders[0]...)
+	sort.Ints(sorted)
+	for i, io := range sorted {
+		if i != io {
+			t.Fatalf("expected a permutation of 50 samples, got %v", mlp1.ShuffleOrders[0])
+		}
+	}
+	// training one epoch without shuffling on rows in the recorded order gives the same weights
+	shuffled := newMLP(1, true)
+	shuffled.Fit(X, Y)
+	order := shuffled.ShuffleOrders[0]
+	Xo, Yo := mat.NewDense(50, 3, nil), mat.NewDense(50, 1, nil)
+	for i, io := range order {
+		Xo.SetRow(i, X.RawRowView(io))
+		Yo.SetRow(i, Y.RawRowView(io))
+	}
+	ordered := newMLP(1, false)
+	ordered.Fit(Xo, Yo)
+	if !floats.Equal(shuffled.packedParameters, ordered.packedParameters) {
+		t.Error("recorded order does not match the order samples were trained on")
+	}
+}
+
+func TestMLPRegressorSetInitialWeights(t *testing.T) {
+	X := mat.NewDense(2, 2, []float64{1, 2, -1, 1})
+	Y := mat.NewDense(2, 1, []float64{5, 1})
+	mlp := NewMLPRegressor([]int{2}, "relu", "lbfgs", 0)
+	mlp.MaxIter = 1
+	mlp.SetInitialWeights([][][]float64{{{1, -1}, {2, .5}}, {{1}, {-2}}}, [][]float64{{.5, -1}, {.25}})
+	var firstForward *mat.Dense
+	mlp.beforeMinimize = func(optimize.Problem, []float64) {
+		firstForward = mlp.Predict(X, nil)
+	}
+	mlp.Fit(X, Y)
+	// hidden: relu(1*1+2*2+.5, 1*-1+2*.5-1)=(5.5,0) and relu(-1*1+1*2+.5, -1*-1+1*.5-1)=(1.5,.5)
+	// output: 5.5*1+0*-2+.25 and 1.5*1+.5*-2+.25
+	if expected := mat.NewDense(2, 1, []float64{5.75, .75}); !mat.EqualApprox(expected, firstForward, 1e-12) {
+		t.Errorf("expected first forward pass %v, got %v", mat.Formatted(expected.T()), mat.Formatted(firstForward.T()))
+	}
+	expectPanic := func(f func()) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+		f()
+	}
+	expectPanic(func() { mlp.SetInitialWeights([][][]float64{{{1}}}, [][]float64{{1}}) })
+	expectPanic(func() {
+		mlp.SetInitialWeights([][][]float64{{{1, 1, 1}}, {{1}, {1}, {1}}}, [][]float64{{1, 1, 1}, {1}})
+	})
+	// input size is checked at Fit
+	mlp.SetInitialWeights([][][]float64{{{1, 1}}, {{1}, {1}}}, [][]float64{{1, 1}, {1}})
+	expectPanic(func() { mlp.Fit(X, Y) })
+}
+
+func TestHvpProduct(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 20, "n_features": 3, "n_targets": 2, "random_state": rand.New(base.NewSource(7))})
+	Ycls := mat.NewDense(20, 1, nil)
+	Ycls.Apply(func(i, _ int, _ float64) float64 { return float64(i % 3) }, Ycls)
+	check := func(name string, mlp *BaseMultilayerPerceptron64, X, Y *mat.Dense) {
+		rnd := rand.New(base.NewSource(1))
+		v := make([]float64, len(mlp.packedParameters))
+		for i := range v {
+			v[i] = rnd.NormFloat64()
+		}
+		hv := mlp.HvpProduct(X, Y, v)
+		w := append([]float64{}, mlp.packedParameters...)
+		eps := 1e-5
+		for i := range w {
+			mlp.packedParameters[i] = w[i] + eps*v[i]
+		}
+		_, gPlus := mlp.ComputeLossAndGrad(X, Y)
+		for i := range w {
+			mlp.packedParameters[i] = w[i] - eps*v[i]
+		}
+		_, gMinus := mlp.ComputeLossAndGrad(X, Y)
+		copy(mlp.packedParameters, w)
+		for i := range hv {
+			fd := (gPlus[i] - gMinus[i]) / (2 * eps)
+			if math.Abs(fd-hv[i]) > 1e-6*(1+math.Abs(fd)) {
+				t.Errorf("%s: Hvp[%d]=%g, finite differences %g", name, i, hv[i], fd)
+				return
+			}
+		}
+	}
+	for _, activation := range []string{"tanh", "logistic", "relu"} {
+		regr := NewMLPRegressor([]int{4, 3}, activation, "adam", 1e-2)
+		regr.RandomState = base.NewSource(7)
+		regr.MaxIter = 5
+		regr.Fit(X, Y)
+		check("regressor "+activation, &regr.BaseMultilayerPerceptron64, X, Y)
+
+		regr.OutputActivation = "tanh"
+		regr.Fit(X, Y)
+		check("regressor with tanh output "+activation, &regr.BaseMultilayerPerceptron64, X, Y)
+
+		clf := NewMLPClassifier([]int{4}, activation, "adam", 1e-2)
+		clf.RandomState = base.NewSource(7)
+		clf.MaxIter = 5
+		clf.Fit(X, Ycls)
+		check("classifier "+activation, &clf.BaseMultilayerPerceptron64, X, Ycls)
+	}
+}
+
+func TestMLPRegressorInputMask(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 200, "n_features": 3, "n_informative": 3, "random_state": rand.New(base.NewSource(7))})
+	mlp := NewMLPRegressor([]int{8}, "relu", "adam", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 50
+	mlp.InputMask = []bool{true, false, false}
+	mlp.Fit(X, Y)
+	Xref := mat.DenseCopyOf(X)
+	Xperturbed := mat.DenseCopyOf(X)
+	for i := 0; i < 200; i++ {
+		Xperturbed.Set(i, 0, 100*float64(i%7))
+	}
+	expected, actual := mlp.Predict(X, nil), mlp.Predict(Xperturbed, nil)
+	if !mat.Equal(expected, actual) {
+		t.Error("predictions depend on the masked feature")
+	}
+	if !mat.Equal(Xref, X) {
+		t.Error("X was modified")
+	}
+	// without the mask, the feature is informative
+	mlp.InputMask = nil
+	mlp.Fit(X, Y)
+	if mat.EqualApprox(mlp.Predict(X, nil), mlp.Predict(Xperturbed, nil), 1e-6) {
+		t.Error("expected predictions to depend on unmasked feature")
+	}
+}
+
+func TestMLPClassifierLabelSmoothing(t *testing.T) {
+	ds := datasets.LoadIris()
+	maxProbaMean := func(labelSmoothing float64) float64 {
+		mlp := NewMLPClassifier([]int{10}, "relu", "adam", 0)
+		mlp.RandomState = base.NewSource(7)
+		mlp.MaxIter = 300
+		mlp.LearningRateInit = .02
+		mlp.Standardize = true
+		mlp.LabelSmoothing = labelSmoothing
+		mlp.Fit(ds.X, ds.Y)
+		probas := mlp.PredictProba(ds.X, nil)
+		sum := 0.
+		nSamples, _ := probas.Dims()
+		for i := 0; i < nSamples; i++ {
+			sum += floats.Max(probas.RawRowView(i))
+		}
+		if score := mlp.Score(ds.X, ds.Y); score < .9 {
+			t.Errorf("LabelSmoothing=%g: expected accuracy >= .9, got %g", labelSmoothing, score)
+		}
+		return sum / float64(nSamples)
+	}
+	hard, smoothed := maxProbaMean(0), maxProbaMean(.2)
+	if smoothed >= hard || smoothed > .85 {
+		t.Errorf("expected less extreme probabilities with smoothing, got mean max proba %g with and %g without", smoothed, hard)
+	}
+}
+
+func TestMLPRegressorActivations(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 50, "n_features": 3, "n_targets": 2, "random_state": rand.New(base.NewSource(7))})
+	mlp := NewMLPRegressor([]int{5, 4}, "tanh", "adam", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 20
+	mlp.Standardize = true
+	mlp.Fit(X, Y)
+	activations := mlp.Activations(X)
+	if len(activations) != mlp.NLayers || mlp.NLayers != 4 {
+		t.Fatalf("expected %d activations, got %d", mlp.NLayers, len(activations))
+	}
+	for i, units := range []int{3, 5, 4, 2} {
+		if r, c := activations[i].Dims(); r != 50 || c != units {
+			t.Errorf("layer %d: expected 50x%d, got %dx%d", i, units, r, c)
+		}
+	}
+	if !mat.Equal(mlp.Predict(X, nil), activations[3]) {
+		t.Error("last activations differ from Predict")
+	}
+	// activations are copies
+	activations[3].Zero()
+	if mat.Equal(mlp.Activations(X)[3], activations[3]) {
+		t.Error("expected copies of activations")
+	}
+}
+
+func TestSGDOptimizerCosineLearningRate(t *testing.T) {
+	opt := &SGDOptimizer64{LearningRateInit: .1, LearningRate: .1, LRSchedule: "cosine", CycleLength: 4, CycleMult: 2, LearningRateMin: .01}
+	rates := []float64{opt.LearningRate}
+	for epoch := 0; epoch < 20; epoch++ {
+		opt.iterationEnds(0)
+		rates = append(rates, opt.LearningRate)
+	}
+	// cycles of 4 then 8 then 16 epochs
+	position, cycle := 0, 4
+	for epoch, rate := range rates {
+		expected := .01 + (.1-.01)*(1+math.Cos(math.Pi*float64(position)/float64(cycle)))/2
+		if math.Abs(rate-expected) > 1e-12 {
+			t.Errorf("epoch %d: expected learning rate %g, got %g", epoch, expected, rate)
+		}
+		if position == 0 && rate != .1 {
+			t.Errorf("epoch %d: expected restart at LearningRateInit, got %g", epoch, rate)
+		}
+		if position > 0 && rate >= rates[epoch-1] {
+			t.Errorf("epoch %d: expected decreasing learning rate within a cycle", epoch)
+		}
+		if position++; position == cycle {
+			position, cycle = 0, 2*cycle
+		}
+	}
+	for _, epoch := range []int{0, 4, 12} {
+		if rates[epoch] != .1 {
+			t.Errorf("expected restart at epoch %d", epoch)
+		}
+	}
+
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 3, "random_state": rand.New(base.NewSource(7))})
+	mlp := NewMLPRegressor([]int{}, "identity", "sgd", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.LearningRate, mlp.CycleLength, mlp.LearningRateInit = "cosine", 10, .01
+	mlp.MaxIter = 15
+	mlp.NIterNoChange = 100
+	mlp.Fit(X, Y)
+	// after 15 epochs, 5 epochs into the second cycle
+	if expected, actual := .01*(1+math.Cos(math.Pi*5/10))/2, mlp.optimizer.(*SGDOptimizer64).LearningRate; math.Abs(expected-actual) > 1e-12 {
+		t.Errorf("expected learning rate %g after fit, got %g", expected, actual)
+	}
+}
+
+func TestMLPRegressorRestoreBestWeights(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 3, "random_state": rand.New(base.NewSource(7))})
+	// noisy training targets make the network overfit
+	rnd := rand.New(base.NewSource(7))
+	for i := 0; i < 80; i++ {
+		Y.Set(i, 0, Y.At(i, 0)+rnd.NormFloat64())
+	}
+	negMAE := func(Ytrue, Ypred mat.Matrix) float64 { return -metrics.MeanAbsoluteError(Ytrue, Ypred, nil, "").At(0, 0) }
+	mlp := NewMLPRegressor([]int{50}, "relu", "sgd", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.Shuffle = false
+	mlp.LearningRateInit = .05
+	mlp.BatchSize = 5
+	mlp.MaxIter = 30
+	mlp.NIterNoChange = 100
+	mlp.ValidationFraction = .2
+	mlp.MonitorMetric = negMAE
+	mlp.RestoreBestWeights = true
+	mlp.Fit(X, Y)
+	if len(mlp.ValidationScores) != 30 {
+		t.Fatalf("expected 30 validation scores, got %d", len(mlp.ValidationScores))
+	}
+	best, bestEpoch := math.Inf(-1), 0
+	for epoch, score := range mlp.ValidationScores {
+		if score > best {
+			best, bestEpoch = score, epoch
+		}
+	}
+	// the test is meaningful only if the last weights are not the best ones
+	if bestEpoch == 29 {
+		t.Errorf("expected validation score to degrade after best epoch, got %v", mlp.ValidationScores)
+	}
+	// validation split is made of the last rows
+	Xval, Yval := X.Slice(80, 100, 0, 3), Y.Slice(80, 100, 0, 1)
+	if actual := negMAE(Yval, mlp.Predict(Xval, nil)); math.Abs(actual-best) > 1e-9 {
+		t.Errorf("expected restored weights to score %g (epoch %d), got %g", best, bestEpoch, actual)
+	}
+	if best != mlp.BestValidationScore {
+		t.Errorf("expected BestValidationScore %g, got %g", best, mlp.BestValidationScore)
+	}
+}
+
+func TestMLPSaliency(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 50, "n_features": 3, "n_targets": 2, "random_state": rand.New(base.NewSource(7))})
+	mlp := NewMLPRegressor([]int{4}, "identity", "adam", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 20
+	mlp.Standardize = true
+	mlp.Fit(X, Y)
+	// a linear net has a constant saliency: the effective weights of the output, in X units
+	c0, c1 := mlp.Coefs[0], mlp.Coefs[1]
+	effective := &mat.Dense{}
+	effective.Mul(mat.NewDense(c0.Rows, c0.Cols, c0.Data), mat.NewDense(c1.Rows, c1.Cols, c1.Data))
+	params := append([]float64{}, mlp.packedParameters...)
+	saliency := mlp.Saliency(X, 1)
+	if !floats.Equal(params, mlp.packedParameters) {
+		t.Error("Saliency changed weights")
+	}
+	for i := 0; i < 50; i++ {
+		for j := 0; j < 3; j++ {
+			if expected := effective.At(j, 1) / mlp.XScale[j]; math.Abs(saliency.At(i, j)-expected) > 1e-12 {
+				t.Fatalf("sample %d feature %d: expected %g, got %g", i, j, expected, saliency.At(i, j))
+			}
+		}
+	}
+
+	// softmax classifier saliency matches finite differences of PredictProba
+	ds := datasets.LoadIris()
+	clf := NewMLPClassifier([]int{5}, "tanh", "adam", 0)
+	clf.RandomState = base.NewSource(7)
+	clf.MaxIter = 50
+	clf.Fit(ds.X, ds.Y)
+	x := mat.DenseCopyOf(ds.X.Slice(60, 61, 0, 4))
+	saliency = clf.Saliency(x, 2)
+	const h = 1e-6
+	for j := 0; j < 4; j++ {
+		xp, xm := mat.DenseCopyOf(x), mat.DenseCopyOf(x)
+		xp.Set(0, j, x.At(0, j)+h)
+		xm.Set(0, j, x.At(0, j)-h)
+		expected := (clf.PredictProba(xp, nil).At(0, 2) - clf.PredictProba(xm, nil).At(0, 2)) / (2 * h)
+		if math.Abs(saliency.At(0, j)-expected) > 1e-6 {
+			t.Errorf("feature %d: expected %g, got %g", j, expected, saliency.At(0, j))
+		}
+	}
+}
+
+func TestMLPRegressorOutputLosses(t *testing.T) {
+	X, Yreg, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 200, "n_features": 3, "random_state": rand.New(base.NewSource(7))})
+	// one regression target and one binary target
+	Y := mat.NewDense(200, 2, nil)
+	for i := 0; i < 200; i++ {
+		Y.Set(i, 0, Yreg.At(i, 0))
+		if X.At(i, 0)+X.At(i, 1) > 0 {
+			Y.Set(i, 1, 1)
+		}
+	}
+	mlp := NewMLPRegressor([]int{10}, "tanh", "lbfgs", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 300
+	mlp.OutputLosses = []string{"square", "log"}
+	mlp.Fit(X, Y)
+	if mlp.OutActivation != "mixed" || mlp.IsClassifier() {
+		t.Fatalf("unexpected output activation %s", mlp.OutActivation)
+	}
+	Ypred := mlp.Predict(X, nil)
+	if r2 := metrics.R2Score(Y.ColView(0), Ypred.ColView(0), nil, "").At(0, 0); r2 < .95 {
+		t.Errorf("expected regression output R2>=.95, got %g", r2)
+	}
+	correct := 0
+	for i := 0; i < 200; i++ {
+		p := Ypred.At(i, 1)
+		if p < 0 || p > 1 {
+			t.Fatalf("sample %d: binary output %g is not a probability", i, p)
+		}
+		if (p >= .5) == (Y.At(i, 1) == 1) {
+			correct++
+		}
+	}
+	if correct < 190 {
+		t.Errorf("expected binary output accuracy >= .95, got %d/200", correct)
+	}
+	buf, err := mlp.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &MLPRegressor{}
+	if err = loaded.Unmarshal(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !mat.EqualApprox(Ypred, loaded.Predict(X, nil), 1e-12) {
+		t.Error("predictions of reloaded mlp differ")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for OutputLosses length mismatch")
+		}
+	}()
+	mlp.OutputLosses = []string{"square"}
+	mlp.Fit(X, Y)
+}
+
+func TestMLPRegressorFreezeBatchNorm(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 3, "random_state": rand.New(base.NewSource(7))})
+	mlp := NewMLPRegressor([]int{5}, "relu", "adam", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 10
+	mlp.BatchNormalize = true
+	mlp.Fit(X, Y)
+	stats := append([]float64{}, mlp.batchNorm[0]...)
+
+	// eval mode: a forward pass on rescaled hidden activations uses and keeps the stored statistics
+	mlp.FreezeBatchNorm = true
+	hidden := mat.NewDense(2, 5, []float64{10, 20, 30, 40, 50, 1, 2, 3, 4, 5})
+	activations := []blas64General{{}, hidden.RawMatrix()}
+	mlp.batchNormalize(activations)
+	for o, stat := range stats {
+		if mlp.batchNorm[0][o] != stat {
+			t.Errorf("unit %d: statistic changed from %g to %g", o, stat, mlp.batchNorm[0][o])
+		}
+		if expected := 10 * float64(o+1) / stat; stat > 0 && hidden.At(0, o) != expected {
+			t.Errorf("unit %d: expected activation divided by stored statistic %g, got %g", o, expected, hidden.At(0, o))
+		}
+	}
+	// fine-tuning does not update the statistics
+	mlp.WarmStart = true
+	Xs := &mat.Dense{}
+	Xs.Scale(10, X)
+	mlp.Fit(Xs, Y)
+	if !floats.Equal(stats, mlp.batchNorm[0]) {
+		t.Errorf("statistics changed at fine-tuning: %v %v", stats, mlp.batchNorm[0])
+	}
+	// train mode uses batch statistics
+	mlp.FreezeBatchNorm = false
+	mlp.Fit(Xs, Y)
+	if floats.Equal(stats, mlp.batchNorm[0]) {
+		t.Error("expected statistics to be updated in train mode")
+	}
+}
+
+func TestMLPPerSampleLoss(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 50, "n_features": 3, "n_targets": 2, "random_state": rand.New(base.NewSource(7))})
+	regr := NewMLPRegressor([]int{5}, "tanh", "adam", 0)
+	regr.RandomState = base.NewSource(7)
+	regr.MaxIter = 20
+	regr.Standardize = true
+	regr.Fit(X, Y)
+	ds := datasets.LoadIris()
+	clf := NewMLPClassifier([]int{5}, "relu", "adam", 0)
+	clf.RandomState = base.NewSource(7)
+	clf.MaxIter = 20
+	clf.Fit(ds.X, ds.Y)
+	for _, test := range []struct {
+		name string
+		mlp  *BaseMultilayerPerceptron64
+		X, Y *mat.Dense
+	}{{"regressor", &regr.BaseMultilayerPerceptron64, X, Y}, {"classifier", &clf.BaseMultilayerPerceptron64, ds.X, ds.Y}} {
+		losses := test.mlp.PerSampleLoss(test.X, test.Y)
+		if nSamples, _ := test.X.Dims(); len(losses) != nSamples {
+			t.Fatalf("%s: expected %d losses, got %d", test.name, nSamples, len(losses))
+		}
+		loss, _ := test.mlp.ComputeLossAndGrad(test.X, test.Y)
+		if mean := floats.Sum(losses) / float64(len(losses)); math.Abs(mean-loss) > 1e-12 {
+			t.Errorf("%s: expected mean per-sample loss %g, got %g", test.name, loss, mean)
+		}
+		if floats.Min(losses) == floats.Max(losses) {
+			t.Errorf("%s: expected different per-sample losses", test.name)
+		}
+	}
+}
+
+func TestMLPRegressorDropoutGradient(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 20, "n_features": 3, "n_targets": 2, "random_state": rand.New(base.NewSource(7))})
+	for _, activation := range []string{"tanh", "logistic", "relu"} {
+		mlp := NewMLPRegressor([]int{6, 5}, activation, "adam", 0)
+		mlp.RandomState = base.NewSource(7)
+		mlp.MaxIter = 5
+		mlp.Fit(X, Y)
+		mlp.Dropout = .3
+		layerUnits := mlp.layerUnits()
+		packedGrads, coefGrads, interceptGrads := mlp.allocGrads(layerUnits)
+		activations, deltas := []blas64General{X.RawMatrix()}, []blas64General{}
+		for _, nFanOut := range layerUnits[1:] {
+			activations = append(activations, mat.NewDense(20, nFanOut, nil).RawMatrix())
+			deltas = append(deltas, mat.NewDense(20, nFanOut, nil).RawMatrix())
+		}
+		// same masks for each loss evaluation
+		lossAndGrad := func() (float64, []float64) {
+			mlp.RandomState = base.NewSource(11)
+			loss := mlp.backprop(X.RawMatrix(), Y.RawMatrix(), activations, deltas, coefGrads, interceptGrads)
+			return loss, append([]float64{}, packedGrads...)
+		}
+		_, grad := lossAndGrad()
+		const h = 1e-6
+		for k, w := range mlp.packedParameters {
+			mlp.packedParameters[k] = w + h
+			lp, _ := lossAndGrad()
+			mlp.packedParameters[k] = w - h
+			lm, _ := lossAndGrad()
+			mlp.packedParameters[k] = w
+			if expected := (lp - lm) / (2 * h); math.Abs(expected-grad[k]) > 1e-6 {
+				t.Errorf("%s: parameter %d: expected gradient %g, got %g", activation, k, expected, grad[k])
+			}
+		}
+		// ComputeLossAndGrad doesn't apply dropout
+		loss1, grad1 := mlp.ComputeLossAndGrad(X, Y)
+		loss2, grad2 := mlp.ComputeLossAndGrad(X, Y)
+		if loss1 != loss2 || !floats.Equal(grad1, grad2) {
+			t.Errorf("%s: expected deterministic ComputeLossAndGrad, got losses %g and %g", activation, loss1, loss2)
+		}
+	}
+}
+
+func TestMLPRegressorDropoutValidationLoss(t *testing.T) {
+	// few samples and many hidden units: training without dropout overfits
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 240, "n_features": 20, "n_informative": 5, "random_state": rand.New(base.NewSource(7))})
+	Xtrain, Ytrain := X.Slice(0, 40, 0, 20), Y.Slice(0, 40, 0, 1)
+	Xval, Yval := X.Slice(40, 240, 0, 20), Y.Slice(40, 240, 0, 1)
+	validationLoss := func(dropout float64) float64 {
+		mlp := NewMLPRegressor([]int{200}, "relu", "adam", 0)
+		mlp.RandomState = base.NewLockedSource(7)
+		mlp.LearningRateInit = .005
+		mlp.MaxIter = 300
+		mlp.NIterNoChange = 300
+		mlp.Dropout = dropout
+		mlp.Fit(Xtrain, Ytrain)
+		return metrics.MeanSquaredError(Yval, mlp.Predict(Xval, nil), nil, "").At(0, 0)
+	}
+	log.SetPrefix("TestMLPRegressorDropoutValidationLoss:")
+	defer log.SetPrefix("")
+	if withDropout, without := validationLoss(.5), validationLoss(0); withDropout >= without {
+		t.Errorf("expected Dropout .5 to reduce validation loss, got %g with and %g without", withDropout, without)
+	}
+}
+
+func TestMLPPredictMCDropout(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 50, "n_features": 3, "random_state": rand.New(base.NewSource(7))})
+	mlp := NewMLPRegressor([]int{20}, "relu", "adam", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 50
+	mlp.Dropout = .2
+	mlp.Fit(X, Y)
+	mean, std := mlp.PredictMCDropout(X, 30)
+	if r, c := std.Dims(); r != 50 || c != 1 {
+		t.Fatalf("expected 50x1 std, got %dx%d", r, c)
+	}
+	for i := 0; i < 50; i++ {
+		if std.At(i, 0) <= 0 {
+			t.Errorf("sample %d: expected std > 0, got %g", i, std.At(i, 0))
+		}
+	}
+	// the MC mean is close to the deterministic prediction
+	if r2 := metrics.R2Score(mlp.Predict(X, nil), mean, nil, "").At(0, 0); r2 < .9 {
+		t.Errorf("expected MC mean close to Predict, got R2 %g", r2)
+	}
+	mlp.Dropout = 0
+	mean, std = mlp.PredictMCDropout(X, 30)
+	if mat.Max(std) != 0 || mat.Min(std) != 0 {
+		t.Error("expected zero std without dropout")
+	}
+	if !mat.Equal(mean, mlp.Predict(X, nil)) {
+		t.Error("expected mean to be Predict without dropout")
+	}
+}
+
+func TestMLPNumParameters(t *testing.T) {
+	mlp := NewMLPClassifier([]int{25}, "logistic", "adam", 0)
+	if n := mlp.NumParameters(); n != 0 {
+		t.Errorf("expected 0 parameters before the architecture is known, got %d", n)
+	}
+	// MNIST architecture
+	mlp.initialize(10, []int{400, 25, 10}, true, true)
+	if n := mlp.NumParameters(); n != 401*25+26*10 || n != len(mlp.packedParameters) {
+		t.Errorf("expected %d parameters, got %d", 401*25+26*10, n)
+	}
+	if perLayer := mlp.LayerNumParameters(); len(perLayer) != 2 || perLayer[0] != 401*25 || perLayer[1] != 26*10 {
+		t.Errorf("wrong per layer parameters %v", perLayer)
+	}
+}
+
+func TestMLPClassifierMixup(t *testing.T) {
+	ds := datasets.LoadIris()
+	mlp := NewMLPClassifier([]int{10}, "relu", "adam", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 300
+	mlp.LearningRateInit = .01
+	mlp.BatchSize = 20
+	mlp.Mixup = .4
+	mlp.Standardize = true
+	mlp.Fit(ds.X, ds.Y)
+	if accuracy := mlp.Score(ds.X, ds.Y); accuracy < .9 {
+		t.Errorf("expected accuracy >= .9 with mixup, got %g", accuracy)
+	}
+	// minibatches get fractional one-hot targets whose rows still sum to 1
+	Y := mat.NewDense(20, 3, nil)
+	for i := 0; i < 20; i++ {
+		Y.Set(i, i%3, 1)
+	}
+	Xm, Ym := mlp.mixup(ds.X.Slice(0, 20, 0, 4).(*mat.Dense).RawMatrix(), Y.RawMatrix())
+	if Xm.Rows != 20 || Ym.Rows != 20 {
+		t.Fatalf("wrong mixup dims %d %d", Xm.Rows, Ym.Rows)
+	}
+	fractional := false
+	for i := 0; i < 20; i++ {
+		row := Ym.Data[i*3 : i*3+3]
+		fractional = fractional || row[0] > 0 && row[0] < 1 || row[1] > 0 && row[1] < 1
+		if sum := floats.Sum(row); math.Abs(sum-1) > 1e-12 {
+			t.Errorf("row %d: blended labels sum to %g", i, sum)
+		}
+	}
+	if !fractional {
+		t.Error("expected fractional targets with mixup")
+	}
+}
+
+func TestMLPRegressorRegularizeIntercept(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 3, "random_state": rand.New(base.NewSource(7))})
+	Y.Apply(func(_, _ int, y float64) float64 { return 100 + y }, Y)
+	fit := func(regularizeIntercept bool) *MLPRegressor {
+		mlp := NewMLPRegressor([]int{}, "identity", "lbfgs", 50)
+		mlp.RandomState = base.NewSource(7)
+		mlp.MaxIter = 50
+		mlp.RegularizeIntercept = regularizeIntercept
+		mlp.Fit(X, Y)
+		return mlp
+	}
+	free, penalized := fit(false), fit(true)
+	if b := free.Intercepts[0][0]; math.Abs(b-100) > 1 {
+		t.Errorf("expected unpenalized intercept close to 100, got %g", b)
+	}
+	if b := penalized.Intercepts[0][0]; b > 90 {
+		t.Errorf("expected penalized intercept shrunk, got %g", b)
+	}
+	// the intercept gradient excludes the penalty term unless RegularizeIntercept
+	_, grad := free.ComputeLossAndGrad(X, Y)
+	free.Alpha = 0
+	_, gradNoPenalty := free.ComputeLossAndGrad(X, Y)
+	if grad[0] != gradNoPenalty[0] {
+		t.Errorf("expected intercept gradient without penalty %g, got %g", gradNoPenalty[0], grad[0])
+	}
+	if grad[1] == gradNoPenalty[1] {
+		t.Error("expected coef gradient to include penalty")
+	}
+	free.Alpha, free.RegularizeIntercept = 50, true
+	_, grad = free.ComputeLossAndGrad(X, Y)
+	if expected := gradNoPenalty[0] + 50*free.Intercepts[0][0]/100; math.Abs(grad[0]-expected) > 1e-9 {
+		t.Errorf("expected penalized intercept gradient %g, got %g", expected, grad[0])
+	}
+}
+
+func TestMLPRegressorBestIter(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 5, "random_state": rand.New(base.NewSource(7))})
+	rnd := rand.New(base.NewSource(7))
+	Y.Apply(func(_, _ int, y float64) float64 { return y + rnd.NormFloat64() }, Y)
+	mlp := NewMLPRegressor([]int{100}, "relu", "adam", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.LearningRateInit = .01
+	mlp.MaxIter = 1000
+	mlp.EarlyStopping = true
+	mlp.ValidationFraction = .2
+	mlp.Fit(X, Y)
+	if mlp.BestIter < 1 || mlp.BestIter >= mlp.NIter {
+		t.Errorf("expected 1 <= BestIter < NIter=%d, got %d", mlp.NIter, mlp.BestIter)
+	}
+	if score := float64(mlp.ValidationScores[mlp.BestIter-1]); score != mlp.BestValidationScore {
+		t.Errorf("expected ValidationScores[BestIter-1]=%g to be BestValidationScore %g", score, mlp.BestValidationScore)
+	}
+	// validation rows are the last ones for regressors
+	Xval, Yval := X.Slice(80, 100, 0, 5), Y.Slice(80, 100, 0, 1)
+	if score := mlp.Score(Xval, Yval); math.Abs(score-mlp.BestValidationScore) > 1e-10 {
+		t.Errorf("expected restored weights to score %g on validation split, got %g", mlp.BestValidationScore, score)
+	}
+}
+
+func TestMLPRegressorStandardizeTarget(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 200, "n_features": 4, "n_targets": 2, "random_state": rand.New(base.NewSource(7))})
+	// targets in large units with offsets
+	Y.Apply(func(_, o int, y float64) float64 { return 1000*float64(o+1) + 50*y }, Y)
+	newRegressor := func() *MLPRegressor {
+		mlp := NewMLPRegressor([]int{10}, "tanh", "lbfgs", 0)
+		mlp.RandomState = base.NewSource(7)
+		mlp.MaxIter = 100
+		return mlp
+	}
+	mlp := newRegressor()
+	mlp.StandardizeTarget = true
+	mlp.Fit(X, Y)
+	Ypred := mlp.Predict(X, nil)
+	for o := 0; o < 2; o++ {
+		if mean := mat.Sum(Ypred.ColView(o)) / 200; math.Abs(mean-1000*float64(o+1)) > 100 {
+			t.Errorf("output %d: expected predictions in original units, got mean %g", o, mean)
+		}
+	}
+	score := mlp.Score(X, Y)
+	if score < .99 {
+		t.Errorf("expected R2>=.99, got %g", score)
+	}
+	// losses are computed on standardized targets as in Fit
+	loss, _ := mlp.ComputeLossAndGrad(X, Y)
+	perSampleLoss := floats.Sum(mlp.PerSampleLoss(X, Y))/200 + .5*mlp.Alpha*mlp.sumCoefSquares()/200
+	for _, actual := range []float64{loss, perSampleLoss} {
+		if math.Abs(actual-mlp.Loss) > 1e-9*mlp.Loss {
+			t.Errorf("expected training loss %g, got %g", mlp.Loss, actual)
+		}
+	}
+	// same as an external target scaling
+	regr := pipeline.NewTransformedTargetRegressor(newRegressor(), preprocessing.NewStandardScaler())
+	regr.Fit(X, Y)
+	if expected := regr.Score(X, Y); math.Abs(score-expected) > 1e-6 {
+		t.Errorf("expected R2 %g as with TransformedTargetRegressor, got %g", expected, score)
+	}
+	buf, err := mlp.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &MLPRegressor{}
+	if err = loaded.Unmarshal(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !mat.EqualApprox(Ypred, loaded.Predict(X, nil), 1e-9) {
+		t.Error("predictions of reloaded regressor differ")
+	}
+}
+
+func TestMLPRegressorProgress(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 3, "random_state": rand.New(base.NewSource(7))})
+	progress := make(chan ProgressEvent, 100)
+	mlp := NewMLPRegressor([]int{10}, "relu", "adam", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 20
+	mlp.RestoreBestWeights = true
+	mlp.Progress = progress
+	mlp.Fit(X, Y)
+	if len(progress) != mlp.NIter {
+		t.Fatalf("expected %d events, got %d", mlp.NIter, len(progress))
+	}
+	for i := 1; len(progress) > 0; i++ {
+		ev := <-progress
+		if ev.Iteration != i || math.IsNaN(ev.Loss) || math.IsInf(ev.Loss, 0) || math.IsNaN(ev.ValidationScore) {
+			t.Errorf("unexpected event %d: %+v", i, ev)
+		}
+	}
+	// events are dropped when the channel is full
+	progress = make(chan ProgressEvent, 1)
+	mlp.Progress = progress
+	mlp.RestoreBestWeights = false
+	mlp.Fit(X, Y)
+	if ev := <-progress; ev.Iteration != 1 || !math.IsNaN(ev.ValidationScore) {
+		t.Errorf("expected first event without validation score, got %+v", ev)
+	}
+}
+
+func TestMLPRegressorPredictInterval(t *testing.T) {
+	// heteroscedastic noise growing with |x|
+	rnd := rand.New(base.NewSource(7))
+	X, Y := mat.NewDense(1000, 1, nil), mat.NewDense(1000, 1, nil)
+	for i := 0; i < 1000; i++ {
+		x := 4*rnd.Float64() - 2
+		X.Set(i, 0, x)
+		Y.Set(i, 0, x+(.1+math.Abs(x))*rnd.NormFloat64())
+	}
+	mlp := NewMLPRegressor([]int{20}, "tanh", "adam", 0)
+	mlp.RandomState = base.NewSource(7)
+	mlp.MaxIter = 300
+	mlp.LearningRateInit = .01
+	mlp.FitInterval(X, Y)
+	Yi := mlp.PredictInterval(X)
+	if _, c := Yi.Dims(); c != 3 {
+		t.Fatalf("expected 3 columns, got %d", c)
+	}
+	ordered, below, above := 0, 0, 0
+	for i := 0; i < 1000; i++ {
+		lo, med, hi := Yi.At(i, 0), Yi.At(i, 1), Yi.At(i, 2)
+		if lo <= med && med <= hi {
+			ordered++
+		}
+		if y := Y.At(i, 0); y < lo {
+			below++
+		} else if y > hi {
+			above++
+		}
+	}
+	if ordered < 950 {
+		t.Errorf("expected lower <= median <= upper for most samples, got %d/1000", ordered)
+	}
+	// about 5% of targets lie below the lower and above the upper quantile
+	if below < 20 || below > 90 || above < 20 || above > 90 {
+		t.Errorf("expected about 50 samples outside each bound, got %d below and %d above", below, above)
+	}
+	// the interval is wider where the noise is larger
+	Xt := mat.NewDense(2, 1, []float64{0, 1.8})
+	Yt := mlp.PredictInterval(Xt)
+	if w0, w1 := Yt.At(0, 2)-Yt.At(0, 0), Yt.At(1, 2)-Yt.At(1, 0); w1 < 2*w0 {
+		t.Errorf("expected wider interval at x=1.8, got widths %g at 0 and %g at 1.8", w0, w1)
+	}
+}
+
+func TestMLPRegressorDecoupledWeightDecay(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 5, "n_informative": 5, "random_state": rand.New(base.NewSource(7))})
+	// on zero data, the only gradient of coefs is the one of the L2 penalty
+	Xz, Yz := mat.NewDense(100, 5, nil), mat.NewDense(100, 1, nil)
+	var coefs []float64
+	shrink := func(weightDecay, alpha float64) []float64 {
+		mlp := NewMLPRegressor([]int{}, "identity", "lbfgs", 0)
+		mlp.RandomState = base.NewSource(7)
+		mlp.Fit(X, Y)
+		coefs = append([]float64{}, mlp.Coefs[0].Data...)
+		mlp.Solver, mlp.WarmStart, mlp.LearningRateInit, mlp.MaxIter, mlp.NIterNoChange = "adam", true, .01, 20, 1000
+		mlp.WeightDecay, mlp.Alpha = weightDecay, alpha
+		mlp.Fit(Xz, Yz)
+		ratios := make([]float64, len(coefs))
+		for i := range ratios {
+			ratios[i] = mlp.Coefs[0].Data[i] / coefs[i]
+		}
+		return ratios
+	}
+	// decoupled decay shrinks every weight by (1-lr*WeightDecay) at each step
+	expected := math.Pow(1-.01, 20)
+	for i, ratio := range shrink(1, 0) {
+		if math.Abs(ratio-expected) > 1e-9 {
+			t.Errorf("coef %d: expected decoupled decay ratio %g, got %g", i, expected, ratio)
+		}
+	}
+	// adam normalizes the L2 gradient, so that all weights move by about lr per step whatever their magnitude
+	ratios := shrink(0, 100)
+	if spread := floats.Max(ratios) - floats.Min(ratios); spread < .1 {
+		t.Errorf("expected L2 shrink ratios to depend on weights magnitude, got %v for coefs %v", ratios, coefs)
+	}
+}
+
+func TestMLPClassifierFitSummary(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	X, _ := preprocessing.NewStandardScaler().FitTransform(ds.X, nil)
+	log.SetPrefix("TestMLPClassifierFitSummary:")
+	defer log.SetPrefix("")
+	for _, maxIter := range []int{5, 1000} {
+		mlp := NewMLPClassifier([]int{10}, "relu", "adam", 1e-4)
+		mlp.RandomState = base.NewLockedSource(1)
+		mlp.MaxIter = maxIter
+		mlp.LearningRateInit = .01
+		mlp.Fit(X, ds.Y)
+		summary := mlp.FitSummary()
+		if summary.NIter != mlp.NIter || summary.Loss != float64(mlp.Loss) {
+			t.Errorf("MaxIter=%d: summary %+v does not match NIter %d and Loss %g", maxIter, summary, mlp.NIter, mlp.Loss)
+		}
+		if expected := mlp.NIter < maxIter; summary.Converged != expected || summary.Converged != (maxIter > 5) {
+			t.Errorf("MaxIter=%d NIter=%d: expected Converged=%v", maxIter, mlp.NIter, expected)
+		}
+		if summary.BestValidationScore != nil || summary.Elapsed <= 0 {
+			t.Errorf("MaxIter=%d: unexpected summary %+v", maxIter, summary)
+		}
+		if _, err := json.Marshal(summary); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestMLPRegressorIntRandomState(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 5, "n_targets": 1, "random_state": rand.New(base.NewSource(7))})
+	fit := func(setRandomState func(*MLPRegressor)) []float64 {
+		mlp := NewMLPRegressor([]int{10}, "relu", "adam", 1e-4)
+		mlp.MaxIter = 10
+		setRandomState(mlp)
+		mlp.Fit(X, Y)
+		return mlp.packedParameters
+	}
+	log.SetPrefix("TestMLPRegressorIntRandomState:")
+	defer log.SetPrefix("")
+	expected := fit(func(mlp *MLPRegressor) { mlp.RandomState = base.NewLockedSource(42) })
+	for name, setRandomState := range map[string]func(*MLPRegressor){
+		"SetParams": func(mlp *MLPRegressor) { mlp.SetParams(map[string]interface{}{"RandomState": 42}) },
+		"Unmarshal": func(mlp *MLPRegressor) {
+			if err := mlp.Unmarshal([]byte(`{"params":{"random_state":42}}`)); err != nil {
+				t.Fatal(err)
+			}
+		},
+	} {
+		if actual := fit(setRandomState); !floats.Equal(expected, actual) {
+			t.Errorf("%s: parameters differ from fit with NewLockedSource(42)", name)
+		}
+	}
+	// values which can't be converted are ignored
+	mlp := NewMLPRegressor([]int{10}, "relu", "adam", 1e-4)
+	mlp.SetParams(map[string]interface{}{"RandomState": 1.5, "MaxIter": 10.})
+	mlp.SetParams(map[string]interface{}{"RandomState": "seed"})
+	if mlp.RandomState != nil || mlp.MaxIter != 10 {
+		t.Errorf("expected RandomState to be left nil and MaxIter=10, got %v and %d", mlp.RandomState, mlp.MaxIter)
+	}
+}
+
+func TestMLPRegressorInputWeightImportance(t *testing.T) {
+	// the first 3 of 8 features are informative
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 200, "n_features": 8, "n_informative": 3, "n_targets": 1, "random_state": rand.New(base.NewSource(7))})
+	mlp := NewMLPRegressor([]int{10}, "relu", "lbfgs", 1)
+	mlp.RandomState = base.NewLockedSource(7)
+	mlp.MaxIter = 200
+	log.SetPrefix("TestMLPRegressorInputWeightImportance:")
+	defer log.SetPrefix("")
+	mlp.Fit(X, Y)
+	importance := mlp.InputWeightImportance()
+	if math.Abs(floats.Sum(importance)-1) > 1e-9 {
+		t.Errorf("expected importances summing to 1, got %v", importance)
+	}
+	if min, max := floats.Min(importance[:3]), floats.Max(importance[3:]); min <= max {
+		t.Errorf("expected informative features to have higher importance than noise ones, got %.3f", importance)
+	}
+}
+
+func TestMLPRegressorTrainPatience(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 200, "n_features": 5, "n_targets": 1, "random_state": rand.New(base.NewSource(7))})
+	fit := func(trainPatience int) *MLPRegressor {
+		mlp := NewMLPRegressor([]int{10}, "relu", "adam", 1e-4)
+		mlp.RandomState = base.NewLockedSource(7)
+		mlp.EarlyStopping = true
+		mlp.MaxIter = 100
+		// any loss change is a plateau
+		mlp.Tol = 1e6
+		mlp.TrainPatience, mlp.ValidationPatience = trainPatience, 1000
+		mlp.Fit(X, Y)
+		return mlp
+	}
+	log.SetPrefix("TestMLPRegressorTrainPatience:")
+	defer log.SetPrefix("")
+	mlp := fit(3)
+	if mlp.NIter != 5 || !mlp.Converged || !strings.HasPrefix(mlp.StopReason, "Training loss did not improve") {
+		t.Errorf("expected to stop on training plateau at iteration 5, got NIter=%d Converged=%v StopReason=%q", mlp.NIter, mlp.Converged, mlp.StopReason)
+	}
+	if summary := mlp.FitSummary(); summary.StopReason != mlp.StopReason {
+		t.Errorf("expected summary StopReason %q, got %q", mlp.StopReason, summary.StopReason)
+	}
+	// without TrainPatience, EarlyStopping only stops on validation plateau
+	if mlp = fit(0); mlp.NIter != mlp.MaxIter || mlp.Converged || mlp.StopReason != "" {
+		t.Errorf("expected to reach MaxIter, got NIter=%d Converged=%v StopReason=%q", mlp.NIter, mlp.Converged, mlp.StopReason)
+	}
+}
+
+func TestMLPClassifierTargetScore(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	X, Y := preprocessing.NewStandardScaler().FitTransform(ds.X, ds.Y)
+	mlp := NewMLPClassifier([]int{10}, "relu", "adam", 1e-4)
+	mlp.RandomState = base.NewLockedSource(7)
+	mlp.MaxIter = 200
+	mlp.TargetScore = .95
+	mlp.Fit(X, Y)
+	if !mlp.Converged || !strings.HasPrefix(mlp.StopReason, "Score") || mlp.NIter >= mlp.MaxIter/2 {
+		t.Errorf("expected to stop early on TargetScore, got NIter=%d Converged=%v StopReason=%q", mlp.NIter, mlp.Converged, mlp.StopReason)
+	}
+	if accuracy := mlp.Score(X, Y); accuracy < .95 {
+		t.Errorf("expected accuracy >= .95, got %g", accuracy)
+	}
+}
+
+func TestMLPScoreMatchesMetrics(t *testing.T) {
+	log.SetPrefix("TestMLPScoreMatchesMetrics:")
+	defer log.SetPrefix("")
+	ds := datasets.LoadBoston()
+	X, Y := preprocessing.NewStandardScaler().FitTransform(ds.X, ds.Y)
+	// two outputs for uniform_average
+	Y2 := mat.NewDense(len(Y.RawMatrix().Data), 2, nil)
+	for i, y := range Y.RawMatrix().Data {
+		Y2.Set(i, 0, y)
+		Y2.Set(i, 1, -y*y)
+	}
+	regr := NewMLPRegressor([]int{20}, "relu", "lbfgs", 1e-4)
+	regr.RandomState = base.NewLockedSource(7)
+	regr.MaxIter = 50
+	regr.Fit(X, Y2)
+	expected := metrics.R2Score(Y2, regr.Predict(X, nil), nil, "uniform_average").At(0, 0)
+	if score := regr.Score(X, Y2); math.Abs(score-expected) > 1e-6 || math.IsNaN(score) {
+		t.Errorf("expected MLPRegressor Score %g, got %g", expected, score)
+	}
+	// constant target: predictions are not exact, so each output scores 0
+	Yconst := mat.NewDense(len(Y.RawMatrix().Data), 2, nil)
+	if score := regr.Score(X, Yconst); score != 0 {
+		t.Errorf("expected score 0 for a constant target, got %g", score)
+	}
+
+	bc := datasets.LoadBreastCancer()
+	X, Y = preprocessing.NewStandardScaler().FitTransform(bc.X, bc.Y)
+	clf := NewMLPClassifier([]int{10}, "relu", "lbfgs", 1e-4)
+	clf.RandomState = base.NewLockedSource(7)
+	clf.MaxIter = 50
+	clf.Fit(X, Y)
+	expected = metrics.AccuracyScore(Y, clf.Predict(X, nil), true, nil)
+	if score := clf.Score(X, Y); score != expected {
+		t.Errorf("expected MLPClassifier Score %g, got %g", expected, score)
+	}
+}
+
+func TestMLPClassifierROCAUCAveragePrecision(t *testing.T) {
+	X, Y := datasets.LoadMicroChipTest()
+	poly := preprocessing.NewPolynomialFeatures(6)
+	poly.IncludeBias = false
+	poly.Fit(X, nil)
+	Xp, _ := poly.Transform(X, nil)
+	mlp := NewMLPClassifier([]int{}, "logistic", "lbfgs", 1)
+	mlp.RandomState = base.NewLockedSource(7)
+	mlp.MaxIter = 100
+	log.SetPrefix("TestMLPClassifierROCAUCAveragePrecision:")
+	defer log.SetPrefix("")
+	mlp.Fit(Xp, Y)
+	Yscore := mlp.PredictProba(Xp, &mat.Dense{})
+	if expected, actual := metrics.ROCAUCScore(Y, Yscore, "macro", nil), mlp.ROCAUC(Xp, Y); expected != actual || actual < .8 {
+		t.Errorf("ROCAUC: expected %g, got %g", expected, actual)
+	}
+	if expected, actual := metrics.AveragePrecisionScore(Y, Yscore, "macro", nil), mlp.AveragePrecision(Xp, Y); expected != actual || actual < .8 {
+		t.Errorf("AveragePrecision: expected %g, got %g", expected, actual)
+	}
+}
+
+func TestMLPRegressorDefaultSource(t *testing.T) {
+	defer base.SetDefaultSource(nil)
+	// no RandomState is set for data, fold shuffling, nor mlp weights init and batches shuffling
+	crossValidate := func() []float64 {
+		base.SetDefaultSource(base.NewLockedSource(7))
+		X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 5})
+		mlp := NewMLPRegressor([]int{10}, "relu", "adam", 1e-4)
+		mlp.MaxIter = 5
+		scorer := func(Ytrue, Ypred mat.Matrix) float64 { return metrics.R2Score(Ytrue, Ypred, nil, "").At(0, 0) }
+		return modelselection.CrossValidate(mlp, X, Y, nil, scorer, &modelselection.KFold{NSplits: 3, Shuffle: true}, 1).TestScore
+	}
+	log.SetPrefix("TestMLPRegressorDefaultSource:")
+	defer log.SetPrefix("")
+	if a, b := crossValidate(), crossValidate(); !floats.Equal(a, b) {
+		t.Errorf("expected identical scores with a default source, got %v and %v", a, b)
+	}
+}
+
+func TestMLPRegressorActivationStats(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 100, "n_features": 5, "n_targets": 1, "random_state": rand.New(base.NewSource(7))})
+	rnd := rand.New(base.NewSource(7))
+	weights := func(fanIn, fanOut int) [][]float64 {
+		w := make([][]float64, fanIn)
+		for i := range w {
+			w[i] = make([]float64, fanOut)
+			for j := range w[i] {
+				w[i][j] = rnd.NormFloat64() / 3
+			}
+		}
+		return w
+	}
+	fit := func(hiddenIntercept float64) []ActivationStats {
+		mlp := NewMLPRegressor([]int{20, 10}, "relu", "adam", 1e-4)
+		mlp.RandomState = base.NewLockedSource(7)
+		mlp.MaxIter = 5
+		intercepts := [][]float64{make([]float64, 20), make([]float64, 10), {0}}
+		floats.AddConst(hiddenIntercept, intercepts[0])
+		mlp.SetInitialWeights([][][]float64{weights(5, 20), weights(20, 10), weights(10, 1)}, intercepts)
+		mlp.Fit(X, Y)
+		return mlp.ActivationStats(X)
+	}
+	log.SetPrefix("TestMLPRegressorActivationStats:")
+	defer log.SetPrefix("")
+	if stats := fit(0); len(stats) != 2 || stats[0].ZeroFraction > .7 || stats[0].Mean <= 0 || stats[0].Std <= 0 {
+		t.Errorf("unexpected hidden layers stats %+v", stats)
+	}
+	// a large negative bias kills the first hidden layer units
+	if stats := fit(-100); stats[0].ZeroFraction != 1 || stats[0].Mean != 0 || stats[0].Std != 0 {
+		t.Errorf("expected dead first hidden layer, got %+v", stats)
+	}
+}
@@ -0,0 +1,34 @@
+package neuralnetwork
+
+import "time"
+
+// ProgressEvent is sent on the Progress channel of an mlp at the end of each training iteration.
+// ValidationScore is NaN when no validation split is evaluated
+type ProgressEvent struct {
+	Iteration       int
+	Loss            float64
+	ValidationScore float64
+}
+
+// sendProgress sends ev on ch without blocking. the event is dropped if ch is full
+func sendProgress(ch chan<- ProgressEvent, ev ProgressEvent) {
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- ev:
+	default:
+	}
+}
+
+// FitSummary is returned by mlp FitSummary method.
+// Converged is false when Fit stopped because MaxIter was reached, StopReason tells why it stopped otherwise.
+// BestValidationScore is nil when no validation split was evaluated
+type FitSummary struct {
+	NIter               int           `json:"n_iter"`
+	Loss                float64       `json:"loss"`
+	Converged           bool          `json:"converged"`
+	StopReason          string        `json:"stop_reason,omitempty"`
+	BestValidationScore *float64      `json:"best_validation_score,omitempty"`
+	Elapsed             time.Duration `json:"elapsed"`
+}
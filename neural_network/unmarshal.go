@@ -1,5 +1,14 @@
 package neuralnetwork
 
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+var randomStateType = reflect.TypeOf((*base.RandomState)(nil)).Elem()
+
 func floats64FromInterface(in interface{}) []float64 {
 	t1 := in.([]interface{})
 	t2 := make([]float64, len(t1))
@@ -24,3 +33,42 @@ func blas64FromInterface(in interface{}) blas64General {
 	}
 	return t2
 }
+
+// jsonFieldIndex returns the index of the field of struct type t whose json tag is name
+func jsonFieldIndex(t reflect.Type, name string) (int, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if tag := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; tag == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// setFieldValue sets field to v, converting numbers and (nested) arrays as decoded by json to the field type.
+// a RandomState field also accepts an integer seed (see base.ConvertRandomState). other values which can't be converted are ignored
+func setFieldValue(field reflect.Value, v interface{}) {
+	if v == nil {
+		return
+	}
+	val := reflect.ValueOf(v)
+	switch {
+	case field.Type() == randomStateType:
+		if rs, ok := base.ConvertRandomState(v); ok {
+			field.Set(reflect.ValueOf(rs))
+		}
+	case val.Type().AssignableTo(field.Type()):
+		field.Set(val)
+	case val.Kind() == reflect.Float64 && field.Kind() >= reflect.Int && field.Kind() <= reflect.Float64:
+		field.Set(val.Convert(field.Type()))
+	case val.Kind() == reflect.Slice && field.Kind() == reflect.Slice:
+		slice := reflect.MakeSlice(field.Type(), val.Len(), val.Len())
+		for i := 0; i < val.Len(); i++ {
+			setFieldValue(slice.Index(i), val.Index(i).Interface())
+		}
+		field.Set(slice)
+	case val.Kind() == reflect.Slice && field.Kind() == reflect.Array && val.Len() == field.Len():
+		for i := 0; i < val.Len(); i++ {
+			setFieldValue(field.Index(i), val.Index(i).Interface())
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package pipeline
+
+import (
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/preprocessing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// FrozenTransformer wraps an already fitted Transformer whose Fit is a no-op,
+// so that an intentionally pre-fitted step (a scaler fitted on reference data for example)
+// keeps its statistics when the pipeline is fitted, cloned or cross validated
+type FrozenTransformer struct {
+	Transformer base.Transformer
+}
+
+// NewFrozenTransformer returns a *FrozenTransformer wrapping the fitted transformer
+func NewFrozenTransformer(transformer base.Transformer) *FrozenTransformer {
+	return &FrozenTransformer{Transformer: transformer}
+}
+
+// Fit does nothing as Transformer is already fitted
+func (f *FrozenTransformer) Fit(X, Y mat.Matrix) base.Fiter { return f }
+
+// Transform calls Transformer.Transform
+func (f *FrozenTransformer) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	return f.Transformer.Transform(X, Y)
+}
+
+// FitTransform only transforms X and Y
+func (f *FrozenTransformer) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	return f.Transform(X, Y)
+}
+
+// TransformerClone returns a FrozenTransformer sharing the (never refitted) Transformer
+func (f *FrozenTransformer) TransformerClone() base.Transformer {
+	clone := *f
+	return &clone
+}
+
+// InverseTransform calls Transformer.InverseTransform if it's an InverseTransformer. otherwise it returns X,Y
+func (f *FrozenTransformer) InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	if inverseTransformer, ok := f.Transformer.(preprocessing.InverseTransformer); ok {
+		return inverseTransformer.InverseTransform(X, Y)
+	}
+	return X, Y
+}
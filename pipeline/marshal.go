@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+// pipelineJSON is the JSON form of a Pipeline. each step state is the output of its Marshal method
+type pipelineJSON struct {
+	NOutputs int        `json:"n_outputs"`
+	Steps    []stepJSON `json:"steps"`
+}
+
+type stepJSON struct {
+	Name  string          `json:"name"`
+	Type  string          `json:"type"`
+	State json.RawMessage `json:"state"`
+}
+
+// Marshal returns the JSON serialization of the fitted pipeline.
+// each step must be a base.Marshaler whose type is registered with base.RegisterMarshaler
+func (p *Pipeline) Marshal() ([]byte, error) {
+	pj := pipelineJSON{NOutputs: p.NOutputs}
+	for _, step := range p.NamedSteps {
+		marshaler, ok := step.Fiter.(base.Marshaler)
+		if !ok {
+			return nil, fmt.Errorf("pipeline step %s (%T) is not a Marshaler", step.Name, step.Fiter)
+		}
+		typeName, ok := base.MarshalerTypeName(step.Fiter)
+		if !ok {
+			return nil, fmt.Errorf("pipeline step %s type %T is not registered", step.Name, step.Fiter)
+		}
+		state, err := marshaler.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("pipeline step %s: %s", step.Name, err)
+		}
+		pj.Steps = append(pj.Steps, stepJSON{Name: step.Name, Type: typeName, State: state})
+	}
+	return json.Marshal(pj)
+}
+
+// Unmarshal rebuilds the steps of a pipeline serialized by Marshal
+func (p *Pipeline) Unmarshal(buf []byte) error {
+	var pj pipelineJSON
+	if err := json.Unmarshal(buf, &pj); err != nil {
+		return err
+	}
+	steps := make([]NamedStep, len(pj.Steps))
+	for i, sj := range pj.Steps {
+		marshaler, err := base.NewMarshaler(sj.Type)
+		if err != nil {
+			return fmt.Errorf("pipeline step %s: %s", sj.Name, err)
+		}
+		if err = marshaler.Unmarshal(sj.State); err != nil {
+			return fmt.Errorf("pipeline step %s: %s", sj.Name, err)
+		}
+		fiter, ok := marshaler.(base.Fiter)
+		if !ok {
+			return fmt.Errorf("pipeline step %s type %s is not a Fiter", sj.Name, sj.Type)
+		}
+		steps[i] = NamedStep{Name: sj.Name, Fiter: fiter}
+	}
+	p.NamedSteps, p.NOutputs = steps, pj.NOutputs
+	return nil
+}
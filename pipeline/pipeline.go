@@ -148,6 +148,24 @@ func (p *Pipeline) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
 	return p.Transform(X, Y)
 }
 
+// InverseTransform applies InverseTransform of each step in reverse order.
+// a last step which is a Predicter but not an InverseTransformer is skipped
+func (p *Pipeline) InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	Xout, Yout = X, Y
+	for istep := len(p.NamedSteps) - 1; istep >= 0; istep-- {
+		step := p.NamedSteps[istep]
+		inverseTransformer, ok := step.Fiter.(preprocessing.InverseTransformer)
+		if !ok {
+			if _, isPredicter := step.Fiter.(base.Predicter); isPredicter && istep == len(p.NamedSteps)-1 {
+				continue
+			}
+			panic(fmt.Errorf("pipeline step %d (%s) is not an InverseTransformer", istep, step.Name))
+		}
+		Xout, Yout = inverseTransformer.InverseTransform(Xout, Yout)
+	}
+	return
+}
+
 // MakePipeline returns a Pipeline from unnamed steps
 func MakePipeline(steps ...base.Fiter) *Pipeline {
 	p := &Pipeline{}
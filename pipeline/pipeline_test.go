@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"fmt"
+	"testing"
 
 	"github.com/pa-m/sklearn/base"
 
@@ -59,3 +60,99 @@ func ExamplePipeline() {
 	// accuracy>0.999 ? true
 
 }
+
+func TestFrozenTransformer(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	nSamples, _ := ds.X.Dims()
+	half := nSamples / 2
+	Xa, Ya := ds.X.Slice(0, half, 0, 30), ds.Y.Slice(0, half, 0, 1)
+	Xb, Yb := ds.X.Slice(half, nSamples, 0, 30), ds.Y.Slice(half, nSamples, 0, 1)
+
+	scaler := preprocessing.NewStandardScaler()
+	scaler.Fit(Xa, Ya)
+	mean := mat.DenseCopyOf(scaler.Mean)
+
+	m := nn.NewMLPClassifier([]int{}, "relu", "adam", 0)
+	m.RandomState = base.NewLockedSource(7)
+	m.MaxIter = 200
+	m.LearningRateInit = .01
+	pl := NewPipeline(NamedStep{"scaler", NewFrozenTransformer(scaler)}, NamedStep{"mlp", m})
+	pl.Fit(Xb, Yb)
+	pl.PredicterClone().Fit(Xb, Yb)
+	if !mat.Equal(mean, scaler.Mean) {
+		t.Error("frozen scaler statistics changed at pipeline Fit")
+	}
+	if accuracy := pl.Score(Xb, Yb); accuracy < .9 {
+		t.Errorf("expected accuracy >= .9, got %g", accuracy)
+	}
+}
+
+func TestPipelineMarshalUnmarshal(t *testing.T) {
+	ds := datasets.LoadBreastCancer()
+	m := nn.NewMLPClassifier([]int{10}, "relu", "adam", 1e-4)
+	m.RandomState = base.NewLockedSource(7)
+	m.MaxIter = 50
+	pl := NewPipeline(NamedStep{"scaler", preprocessing.NewStandardScaler()}, NamedStep{"pca", preprocessing.NewPCA()}, NamedStep{"mlp", m})
+	pl.Fit(ds.X, ds.Y)
+	buf, err := pl.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &Pipeline{}
+	if err = loaded.Unmarshal(buf); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.NamedSteps) != 3 || loaded.NamedSteps[2].Name != "mlp" {
+		t.Fatalf("wrong steps %v", loaded.NamedSteps)
+	}
+	expected, actual := pl.Predict(ds.X, nil), loaded.Predict(ds.X, nil)
+	if !mat.EqualApprox(expected, actual, 1e-12) {
+		t.Error("predictions of reloaded pipeline differ")
+	}
+	if pl.Score(ds.X, ds.Y) != loaded.Score(ds.X, ds.Y) {
+		t.Error("scores of reloaded pipeline differ")
+	}
+	if _, err = NewPipeline(NamedStep{"poly", preprocessing.NewPolynomialFeatures(2)}).Marshal(); err == nil {
+		t.Error("expected error for a step which is not a Marshaler")
+	}
+}
+
+func TestPipelineInverseTransform(t *testing.T) {
+	ds := datasets.LoadIris()
+	pl := NewPipeline(NamedStep{"scaler", preprocessing.NewStandardScaler()}, NamedStep{"pca", preprocessing.NewPCA()})
+	pl.Fit(ds.X, ds.Y)
+	Xt, _ := pl.NamedSteps[1].Fiter.(base.Transformer).Transform(pl.NamedSteps[0].Fiter.(base.Transformer).Transform(ds.X, ds.Y))
+	X, _ := pl.InverseTransform(Xt, nil)
+	if !mat.EqualApprox(ds.X, X, 1e-10) {
+		t.Error("InverseTransform did not recover X")
+	}
+}
+
+func TestTransformedTargetRegressor(t *testing.T) {
+	X, Y, _ := datasets.MakeRegression(map[string]interface{}{"n_samples": 200, "n_features": 4, "random_state": rand.New(base.NewSource(7))})
+	// target in large units with an offset
+	Y.Apply(func(_, _ int, y float64) float64 { return 1000 + 50*y }, Y)
+	m := nn.NewMLPRegressor([]int{}, "relu", "lbfgs", 0)
+	m.RandomState = base.NewSource(7)
+	m.MaxIter = 200
+	scaler := preprocessing.NewStandardScaler()
+	regr := NewTransformedTargetRegressor(m, scaler)
+	regr.Fit(X, Y)
+	if mean := mat.Sum(scaler.Mean); mean < 900 || mean > 1100 {
+		t.Errorf("expected scaler fitted on Y, got mean %g", mean)
+	}
+	Ypred := regr.Predict(X, nil)
+	if mean := mat.Sum(Ypred) / 200; mean < 900 || mean > 1100 {
+		t.Errorf("expected predictions in original units, got mean %g", mean)
+	}
+	// regressor predicts in the scaled space
+	if mean := mat.Sum(m.Predict(X, nil)) / 200; mean < -1 || mean > 1 {
+		t.Errorf("expected regressor predictions in scaled units, got mean %g", mean)
+	}
+	if score := regr.Score(X, Y); score < .99 {
+		t.Errorf("expected R2>=.99, got %g", score)
+	}
+	if score := regr.PredicterClone().Fit(X, Y).(base.Predicter).Score(X, Y); score < .99 {
+		t.Errorf("expected clone R2>=.99, got %g", score)
+	}
+}
@@ -0,0 +1,61 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"github.com/pa-m/sklearn/preprocessing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// TransformedTargetRegressor fits Regressor on Y transformed by Transformer (Y columns being fed as Transformer X)
+// and maps its predictions back to the original target space with Transformer InverseTransform
+type TransformedTargetRegressor struct {
+	Regressor   base.Predicter
+	Transformer preprocessing.InverseTransformer
+}
+
+// NewTransformedTargetRegressor returns a *TransformedTargetRegressor
+func NewTransformedTargetRegressor(regressor base.Predicter, transformer preprocessing.InverseTransformer) *TransformedTargetRegressor {
+	return &TransformedTargetRegressor{Regressor: regressor, Transformer: transformer}
+}
+
+// IsClassifier returns false for TransformedTargetRegressor
+func (*TransformedTargetRegressor) IsClassifier() bool { return false }
+
+// PredicterClone clones Regressor and Transformer
+func (m *TransformedTargetRegressor) PredicterClone() base.Predicter {
+	clone := *m
+	clone.Regressor = m.Regressor.PredicterClone()
+	transformer, ok := m.Transformer.TransformerClone().(preprocessing.InverseTransformer)
+	if !ok {
+		panic(fmt.Errorf("TransformedTargetRegressor: %T clone is not an InverseTransformer", m.Transformer))
+	}
+	clone.Transformer = transformer
+	return &clone
+}
+
+// GetNOutputs returns Regressor GetNOutputs
+func (m *TransformedTargetRegressor) GetNOutputs() int { return m.Regressor.GetNOutputs() }
+
+// Fit fits Transformer on Y, then Regressor on X and transformed Y
+func (m *TransformedTargetRegressor) Fit(X, Y mat.Matrix) base.Fiter {
+	Yt, _ := m.Transformer.FitTransform(Y, nil)
+	m.Regressor.Fit(X, Yt)
+	return m
+}
+
+// Predict predicts with Regressor and inverse transforms its predictions
+func (m *TransformedTargetRegressor) Predict(X mat.Matrix, Ymutable mat.Mutable) *mat.Dense {
+	nSamples, _ := X.Dims()
+	Yt := m.Regressor.Predict(X, mat.NewDense(nSamples, m.GetNOutputs(), nil))
+	Y, _ := m.Transformer.InverseTransform(Yt, nil)
+	return base.FromDense(Ymutable, Y)
+}
+
+// Score returns R2Score of predictions in the original target space
+func (m *TransformedTargetRegressor) Score(X, Y mat.Matrix) float64 {
+	return metrics.R2Score(Y, m.Predict(X, nil), nil, "").At(0, 0)
+}
@@ -129,10 +129,12 @@ func (scaler *MinMaxScaler) InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat.D
 }
 
 // StandardScaler scales data by removing Mean and dividing by stddev
+// Clip bounds InverseTransform outputs to [Clip[0],Clip[1]]. no clipping occurs unless Clip[0]<Clip[1]
 type StandardScaler struct {
 	WithMean, WithStd bool
 	Scale, Mean, Var  *mat.Dense
 	NSamplesSeen      int
+	Clip              [2]float64
 }
 
 // NewStandardScaler creates a *StandardScaler
@@ -155,6 +157,9 @@ func (scaler *StandardScaler) Reset() *StandardScaler {
 // Fit computes Mean snd Std
 func (scaler *StandardScaler) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	X, Y := base.ToDense(Xmatrix), base.ToDense(Ymatrix)
+	if err := base.CheckArray(X, false); err != nil {
+		panic(fmt.Errorf("StandardScaler.Fit: X %s", err))
+	}
 	scaler.Reset()
 	return scaler.PartialFit(X, Y)
 }
@@ -183,6 +188,9 @@ func (scaler *StandardScaler) PartialFit(X, Y *mat.Dense) Transformer {
 
 // Transform scales data
 func (scaler *StandardScaler) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	if err := base.CheckArray(base.ToDense(X), false); err != nil {
+		panic(fmt.Errorf("StandardScaler.Transform: X %s", err))
+	}
 	Xmat := base.ToDense(X).RawMatrix()
 	Xout = mat.NewDense(Xmat.Rows, Xmat.Cols, nil)
 	Xoutmat := Xout.RawMatrix()
@@ -208,7 +216,7 @@ func (scaler *StandardScaler) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Den
 	return scaler.Transform(X, Y)
 }
 
-// InverseTransform unscales data
+// InverseTransform unscales data, then clips it if Clip is set
 func (scaler *StandardScaler) InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	if X == nil {
 		return X, Y
@@ -229,6 +237,11 @@ func (scaler *StandardScaler) InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat
 			Xoutmat.Data[jXout+i] = mean + Xmat.Data[jX+i]*scale
 		}
 	}
+	if scaler.Clip[0] < scaler.Clip[1] {
+		Xout.Apply(func(_, _ int, v float64) float64 {
+			return math.Max(scaler.Clip[0], math.Min(scaler.Clip[1], v))
+		}, Xout)
+	}
 	return Xout, Y
 }
 
@@ -803,7 +816,7 @@ func (m *Shuffler) TransformerClone() base.Transformer {
 // Fit for Shuffler
 func (m *Shuffler) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 	X := base.ToDense(Xmatrix)
-	Perm := rand.Perm
+	Perm := rand.New(base.DefaultSource()).Perm
 	if m.RandomState != base.Source(nil) {
 		Perm = rand.New(m.RandomState).Perm
 	}
@@ -76,6 +76,17 @@ func TestStandardScaler(t *testing.T) {
 	}
 }
 
+func TestStandardScalerInverseTransformClip(t *testing.T) {
+	m := NewStandardScaler()
+	// Mean is 5 and Scale 1
+	m.Fit(mat.NewDense(2, 1, []float64{4, 6}), nil)
+	m.Clip = [2]float64{3, 7}
+	X, _ := m.InverseTransform(mat.NewDense(4, 1, []float64{-3, -1, .5, 10}), nil)
+	if expected := []float64{3, 4, 5.5, 7}; !floats.Equal(expected, X.RawMatrix().Data) {
+		t.Errorf("expected %v, got %v", expected, X.RawMatrix().Data)
+	}
+}
+
 func TestRobustScaler(t *testing.T) {
 	m := NewDefaultRobustScaler()
 	isTransformer := func(Transformer) {}
@@ -0,0 +1,85 @@
+package preprocessing
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// FeatureHasher implements the hashing trick: each (column, category) pair of X is hashed to one of NFeatures output columns.
+// with AlternateSign, the sign of the added value also depends on the hash, so that colliding features tend to cancel out
+// instead of accumulating. FeatureHasher is stateless: Fit does nothing
+type FeatureHasher struct {
+	NFeatures     int
+	AlternateSign bool
+}
+
+// NewFeatureHasher returns a *FeatureHasher with NFeatures=1<<10 and AlternateSign.
+// the output is dense, so it holds nSamples*NFeatures float64: raise NFeatures with care to reduce collisions
+func NewFeatureHasher() *FeatureHasher {
+	return &FeatureHasher{NFeatures: 1 << 10, AlternateSign: true}
+}
+
+// TransformerClone ...
+func (m *FeatureHasher) TransformerClone() base.Transformer {
+	clone := *m
+	return &clone
+}
+
+// Fit ...
+func (m *FeatureHasher) Fit(X, Y mat.Matrix) base.Fiter {
+	return m
+}
+
+// hash returns the output column and the signed value for feature name
+func (m *FeatureHasher) hash(name string) (int, float64) {
+	if m.NFeatures <= 0 {
+		panic(fmt.Errorf("FeatureHasher: NFeatures must be >0, got %d", m.NFeatures))
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	sum := int32(h.Sum32())
+	value := 1.
+	if m.AlternateSign && sum < 0 {
+		value = -1
+	}
+	index := int64(sum)
+	if index < 0 {
+		index = -index
+	}
+	return int(index % int64(m.NFeatures)), value
+}
+
+// Transform hashes the category of each column of X. a category v of column j is hashed as the string "j=v"
+func (m *FeatureHasher) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	nSamples, nFeatures := X.Dims()
+	rows := make([][]string, nSamples)
+	for i := range rows {
+		rows[i] = make([]string, nFeatures)
+		for j := range rows[i] {
+			rows[i][j] = strconv.Itoa(j) + "=" + strconv.FormatFloat(X.At(i, j), 'g', -1, 64)
+		}
+	}
+	return m.TransformStrings(rows), base.ToDense(Y)
+}
+
+// TransformStrings hashes string features (tokens or "name=value" pairs). row i of the result is the sum of hashed rows[i]
+func (m *FeatureHasher) TransformStrings(rows [][]string) *mat.Dense {
+	Xout := mat.NewDense(len(rows), m.NFeatures, nil)
+	for i, row := range rows {
+		for _, name := range row {
+			j, value := m.hash(name)
+			Xout.Set(i, j, Xout.At(i, j)+value)
+		}
+	}
+	return Xout
+}
+
+// FitTransform fit to dat, then transform it
+func (m *FeatureHasher) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	m.Fit(X, Y)
+	return m.Transform(X, Y)
+}
@@ -0,0 +1,39 @@
+package preprocessing
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestFeatureHasher(t *testing.T) {
+	m := &FeatureHasher{NFeatures: 16, AlternateSign: true}
+	X := mat.NewDense(3, 2, []float64{
+		1, 1000,
+		2, 2001,
+		1, 1000,
+	})
+	Xout, _ := m.FitTransform(X, nil)
+	if _, nFeatures := Xout.Dims(); nFeatures != 16 {
+		t.Errorf("expected 16 columns, got %d", nFeatures)
+	}
+	if !floats.Equal(Xout.RawRowView(0), Xout.RawRowView(2)) {
+		t.Errorf("expected identical rows for identical inputs, got %v and %v", Xout.RawRowView(0), Xout.RawRowView(2))
+	}
+	if floats.Equal(Xout.RawRowView(0), Xout.RawRowView(1)) {
+		t.Errorf("expected distinct rows for distinct inputs, got %v", Xout.RawRowView(0))
+	}
+	for i := 0; i < 3; i++ {
+		if l1 := floats.Norm(Xout.RawRowView(i), 1); l1 == 0 || l1 > 2 {
+			t.Errorf("row %d: unexpected L1 norm %g", i, l1)
+		}
+	}
+	tokens := m.TransformStrings([][]string{{"dog", "cat"}, {"cat", "dog"}})
+	if !floats.Equal(tokens.RawRowView(0), tokens.RawRowView(1)) {
+		t.Error("expected token order not to matter")
+	}
+	if Xout, _ = NewFeatureHasher().FitTransform(X, nil); Xout.RawMatrix().Cols != 1<<10 {
+		t.Errorf("expected %d default columns, got %d", 1<<10, Xout.RawMatrix().Cols)
+	}
+}
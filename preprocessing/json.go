@@ -0,0 +1,236 @@
+package preprocessing
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func init() {
+	base.RegisterMarshaler("StandardScaler", func() base.Marshaler { return &StandardScaler{} })
+	base.RegisterMarshaler("MinMaxScaler", func() base.Marshaler { return &MinMaxScaler{} })
+	base.RegisterMarshaler("PCA", func() base.Marshaler { return &PCA{} })
+}
+
+// JSON forms of fitted transformers. field names follow scikit-learn attribute names.
+// per-feature statistics are arrays of NFeatures numbers.
+// Unmarshal also reads the form exported from python with scikit-learn get_params() under "params" and fitted attributes as lists, ie:
+//  json.dumps({"params": scaler.get_params(), "mean_": scaler.mean_.tolist(), "var_": scaler.var_.tolist(), "scale_": scaler.scale_.tolist(), "n_samples_seen_": int(scaler.n_samples_seen_)})
+
+// standardScalerJSON is the JSON form of StandardScaler:
+//  {"with_mean":bool, "with_std":bool, "mean_":[...], "var_":[...], "scale_":[...], "n_samples_seen_":int}
+type standardScalerJSON struct {
+	WithMean     bool                  `json:"with_mean"`
+	WithStd      bool                  `json:"with_std"`
+	Mean         []float64             `json:"mean_"`
+	Var          []float64             `json:"var_"`
+	Scale        []float64             `json:"scale_"`
+	NSamplesSeen samplesSeen           `json:"n_samples_seen_"`
+	Params       *standardScalerParams `json:"params,omitempty"`
+}
+
+// standardScalerParams are the scikit-learn StandardScaler get_params() used by Unmarshal
+type standardScalerParams struct {
+	WithMean bool `json:"with_mean"`
+	WithStd  bool `json:"with_std"`
+}
+
+// samplesSeen is n_samples_seen_, which scikit-learn exports as an array of per-feature counts when X had NaNs.
+// the max count is kept
+type samplesSeen int
+
+// UnmarshalJSON reads an int or an array of ints
+func (n *samplesSeen) UnmarshalJSON(buf []byte) error {
+	var counts []int
+	if err := json.Unmarshal(buf, &counts); err != nil {
+		var count int
+		if err := json.Unmarshal(buf, &count); err != nil {
+			return err
+		}
+		counts = []int{count}
+	}
+	*n = 0
+	for _, count := range counts {
+		if samplesSeen(count) > *n {
+			*n = samplesSeen(count)
+		}
+	}
+	return nil
+}
+
+// minMaxScalerJSON is the JSON form of MinMaxScaler:
+//  {"feature_range":[min,max], "min_":[...], "scale_":[...], "data_min_":[...], "data_max_":[...], "data_range_":[...], "n_samples_seen_":int}
+type minMaxScalerJSON struct {
+	FeatureRange []float64 `json:"feature_range"`
+	Min          []float64 `json:"min_"`
+	Scale        []float64 `json:"scale_"`
+	DataMin      []float64 `json:"data_min_"`
+	DataMax      []float64 `json:"data_max_"`
+	DataRange    []float64 `json:"data_range_"`
+	NSamplesSeen int       `json:"n_samples_seen_"`
+}
+
+// pcaJSON is the JSON form of PCA. components_ has NComponents rows of NFeatures values:
+//  {"n_components_":int, "min_variance_ratio":float, "components_":[[...],...], "singular_values_":[...], "explained_variance_ratio_":[...],
+//   "mean_":[...], "explained_variance_":[...]}
+// mean_ and explained_variance_ are omitted unless set by Unmarshal of a scikit-learn PCA
+type pcaJSON struct {
+	NComponents            int         `json:"n_components_"`
+	MinVarianceRatio       float64     `json:"min_variance_ratio"`
+	Components             [][]float64 `json:"components_"`
+	SingularValues         []float64   `json:"singular_values_"`
+	ExplainedVarianceRatio []float64   `json:"explained_variance_ratio_"`
+	Mean                   []float64   `json:"mean_,omitempty"`
+	ExplainedVariance      []float64   `json:"explained_variance_,omitempty"`
+	Params                 *pcaParams  `json:"params,omitempty"`
+}
+
+// pcaParams are the scikit-learn PCA get_params() checked by Unmarshal
+type pcaParams struct {
+	Whiten bool `json:"whiten"`
+}
+
+// rowToSlice returns the only row of a 1-row matrix, or nil
+func rowToSlice(m *mat.Dense) []float64 {
+	if m == nil || m.IsEmpty() {
+		return nil
+	}
+	return mat.Row(nil, 0, m)
+}
+
+// sliceToRow returns a 1-row matrix, or nil for an empty slice
+func sliceToRow(v []float64) *mat.Dense {
+	if len(v) == 0 {
+		return nil
+	}
+	return mat.NewDense(1, len(v), append([]float64{}, v...))
+}
+
+// checkLengths returns an error if some non-empty slice has not length n
+func checkLengths(n int, slices map[string][]float64) error {
+	for name, s := range slices {
+		if len(s) != 0 && len(s) != n {
+			return fmt.Errorf("%s has length %d, expected %d", name, len(s), n)
+		}
+	}
+	return nil
+}
+
+// Marshal returns the JSON form of scaler (see standardScalerJSON)
+func (scaler *StandardScaler) Marshal() ([]byte, error) {
+	return json.Marshal(standardScalerJSON{
+		WithMean:     scaler.WithMean,
+		WithStd:      scaler.WithStd,
+		Mean:         rowToSlice(scaler.Mean),
+		Var:          rowToSlice(scaler.Var),
+		Scale:        rowToSlice(scaler.Scale),
+		NSamplesSeen: samplesSeen(scaler.NSamplesSeen),
+	})
+}
+
+// Unmarshal initializes scaler from its JSON form
+func (scaler *StandardScaler) Unmarshal(buf []byte) error {
+	var s standardScalerJSON
+	if err := json.Unmarshal(buf, &s); err != nil {
+		return err
+	}
+	nFeatures := len(s.Mean)
+	if nFeatures == 0 {
+		// mean_ is null for with_mean=False
+		nFeatures = len(s.Scale)
+	}
+	if err := checkLengths(nFeatures, map[string][]float64{"var_": s.Var, "scale_": s.Scale}); err != nil {
+		return err
+	}
+	if s.Params != nil {
+		s.WithMean, s.WithStd = s.Params.WithMean, s.Params.WithStd
+	}
+	scaler.WithMean, scaler.WithStd, scaler.NSamplesSeen = s.WithMean, s.WithStd, int(s.NSamplesSeen)
+	scaler.Mean, scaler.Var, scaler.Scale = sliceToRow(s.Mean), sliceToRow(s.Var), sliceToRow(s.Scale)
+	return nil
+}
+
+// Marshal returns the JSON form of scaler (see minMaxScalerJSON)
+func (scaler *MinMaxScaler) Marshal() ([]byte, error) {
+	return json.Marshal(minMaxScalerJSON{
+		FeatureRange: scaler.FeatureRange,
+		Min:          rowToSlice(scaler.Min),
+		Scale:        rowToSlice(scaler.Scale),
+		DataMin:      rowToSlice(scaler.DataMin),
+		DataMax:      rowToSlice(scaler.DataMax),
+		DataRange:    rowToSlice(scaler.DataRange),
+		NSamplesSeen: scaler.NSamplesSeen,
+	})
+}
+
+// Unmarshal initializes scaler from its JSON form
+func (scaler *MinMaxScaler) Unmarshal(buf []byte) error {
+	var s minMaxScalerJSON
+	if err := json.Unmarshal(buf, &s); err != nil {
+		return err
+	}
+	if err := checkLengths(len(s.Scale), map[string][]float64{"min_": s.Min, "data_min_": s.DataMin, "data_max_": s.DataMax, "data_range_": s.DataRange}); err != nil {
+		return err
+	}
+	scaler.FeatureRange, scaler.NSamplesSeen = s.FeatureRange, s.NSamplesSeen
+	scaler.Min, scaler.Scale = sliceToRow(s.Min), sliceToRow(s.Scale)
+	scaler.DataMin, scaler.DataMax, scaler.DataRange = sliceToRow(s.DataMin), sliceToRow(s.DataMax), sliceToRow(s.DataRange)
+	return nil
+}
+
+// Marshal returns the JSON form of m (see pcaJSON)
+func (m *PCA) Marshal() ([]byte, error) {
+	s := pcaJSON{
+		NComponents:            m.NComponents,
+		MinVarianceRatio:       m.MinVarianceRatio,
+		SingularValues:         m.SingularValues,
+		ExplainedVarianceRatio: m.ExplainedVarianceRatio,
+		Mean:                   m.Mean,
+		ExplainedVariance:      m.ExplainedVariance,
+	}
+	if components := m.components(); components != nil {
+		_, c := components.Dims()
+		s.Components = make([][]float64, c)
+		for i := range s.Components {
+			s.Components[i] = mat.Col(nil, i, components)
+		}
+	}
+	return json.Marshal(s)
+}
+
+// Unmarshal initializes m from its JSON form
+func (m *PCA) Unmarshal(buf []byte) error {
+	var s pcaJSON
+	if err := json.Unmarshal(buf, &s); err != nil {
+		return err
+	}
+	if len(s.Components) != s.NComponents {
+		return fmt.Errorf("components_ has %d rows, expected n_components_=%d", len(s.Components), s.NComponents)
+	}
+	if s.Params != nil && s.Params.Whiten {
+		return fmt.Errorf("whiten=True is not supported")
+	}
+	if s.NComponents > 0 {
+		if err := checkLengths(len(s.Components[0]), map[string][]float64{"mean_": s.Mean}); err != nil {
+			return err
+		}
+	}
+	m.NComponents, m.MinVarianceRatio = s.NComponents, s.MinVarianceRatio
+	m.SingularValues, m.ExplainedVarianceRatio = s.SingularValues, s.ExplainedVarianceRatio
+	m.Mean, m.ExplainedVariance = s.Mean, s.ExplainedVariance
+	m.SVD = mat.SVD{}
+	m.Components = nil
+	if s.NComponents > 0 {
+		nFeatures := len(s.Components[0])
+		m.Components = mat.NewDense(nFeatures, s.NComponents, nil)
+		for i, row := range s.Components {
+			if len(row) != nFeatures {
+				return fmt.Errorf("components_[%d] has length %d, expected %d", i, len(row), nFeatures)
+			}
+			m.Components.SetCol(i, row)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package preprocessing
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestTransformersMarshalUnmarshal(t *testing.T) {
+	rnd := rand.New(base.NewLockedSource(7))
+	X := mat.NewDense(20, 3, nil)
+	X.Apply(func(i, j int, v float64) float64 { return float64(j+1) * rnd.NormFloat64() }, X)
+	type marshaler interface {
+		Transformer
+		Marshal() ([]byte, error)
+		Unmarshal([]byte) error
+	}
+	for _, tc := range []struct {
+		name          string
+		fitted, fresh marshaler
+	}{
+		{"StandardScaler", NewStandardScaler(), &StandardScaler{}},
+		{"MinMaxScaler", NewMinMaxScaler([]float64{-1, 1}), &MinMaxScaler{}},
+		{"PCA", &PCA{NComponents: 2}, &PCA{}},
+	} {
+		expected, _ := tc.fitted.FitTransform(X, nil)
+		buf, err := tc.fitted.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = tc.fresh.Unmarshal(buf); err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		actual, _ := tc.fresh.Transform(X, nil)
+		if !mat.Equal(expected, actual) {
+			t.Errorf("%s: transforms differ after round trip\n%s", tc.name, buf)
+		}
+		buf2, _ := tc.fresh.Marshal()
+		if string(buf) != string(buf2) {
+			t.Errorf("%s: unstable json\n%s\n%s", tc.name, buf, buf2)
+		}
+	}
+	if err := (&PCA{}).Unmarshal([]byte(`{"n_components_":2,"components_":[[1,0]]}`)); err == nil {
+		t.Error("expected error for inconsistent components_")
+	}
+}
+
+func TestUnmarshalScikitLearn(t *testing.T) {
+	// adapted from examples in https://scikit-learn.org/stable/modules/generated/sklearn.preprocessing.StandardScaler.html
+	// scaler = StandardScaler().fit([[0, 0], [0, 0], [1, 1], [1, 1]])
+	// json.dumps({"params": scaler.get_params(), "mean_": scaler.mean_.tolist(), "var_": scaler.var_.tolist(), "scale_": scaler.scale_.tolist(), "n_samples_seen_": int(scaler.n_samples_seen_)})
+	scaler := &StandardScaler{}
+	if err := scaler.Unmarshal([]byte(`{"params": {"copy": true, "with_mean": true, "with_std": true}, "mean_": [0.5, 0.5], "var_": [0.25, 0.25], "scale_": [0.5, 0.5], "n_samples_seen_": 4}`)); err != nil {
+		t.Fatal(err)
+	}
+	// scaler.transform([[0, 0], [0, 0], [1, 1], [1, 1], [2, 2]])
+	Xt, _ := scaler.Transform(mat.NewDense(5, 2, []float64{0, 0, 0, 0, 1, 1, 1, 1, 2, 2}), nil)
+	if expected := mat.NewDense(5, 2, []float64{-1, -1, -1, -1, 1, 1, 1, 1, 3, 3}); !mat.Equal(expected, Xt) {
+		t.Errorf("expected python scaler output %v, got %v", mat.Formatted(expected), mat.Formatted(Xt))
+	}
+	if scaler.NSamplesSeen != 4 || !scaler.WithMean || !scaler.WithStd {
+		t.Errorf("wrong scaler params %+v", scaler)
+	}
+	// with_mean=False exports mean_ as null and n_samples_seen_ may be per feature
+	if err := scaler.Unmarshal([]byte(`{"params": {"copy": true, "with_mean": false, "with_std": true}, "mean_": null, "var_": [0.25, 1], "scale_": [0.5, 1], "n_samples_seen_": [4, 3]}`)); err != nil || scaler.WithMean || scaler.NSamplesSeen != 4 {
+		t.Errorf("unexpected error %v or params %+v", err, scaler)
+	}
+
+	// adapted from example in https://scikit-learn.org/stable/modules/generated/sklearn.decomposition.PCA.html
+	// X shifted by [10, 5]; pca = PCA(n_components=2).fit(X)
+	// json.dumps({"params": pca.get_params(), "n_components_": pca.n_components_, "components_": pca.components_.tolist(), "mean_": pca.mean_.tolist(), ...})
+	pca := &PCA{}
+	if err := pca.Unmarshal([]byte(`{"params": {"copy": true, "iterated_power": "auto", "n_components": 2, "random_state": null, "svd_solver": "auto", "tol": 0.0, "whiten": false},
+	"n_components_": 2, "components_": [[0.838492237904874, 0.5449135408239331], [-0.5449135408239331, 0.838492237904874]], "mean_": [10.0, 5.0],
+	"explained_variance_": [7.939543120718442, 0.06045687928155843], "explained_variance_ratio_": [0.9924428900898052, 0.007557109910194803],
+	"singular_values_": [6.300612319734663, 0.5498039617971047]}`)); err != nil {
+		t.Fatal(err)
+	}
+	X := mat.NewDense(6, 2, []float64{-1, -1, -2, -1, -3, -2, 1, 1, 2, 1, 3, 2})
+	X.Apply(func(_, j int, v float64) float64 { return v + pca.Mean[j] }, X)
+	// pca.transform(X)
+	expected := mat.NewDense(6, 2, []float64{
+		-1.383406, -0.293579,
+		-2.221898, 0.251335,
+		-3.605304, -0.042244,
+		1.383406, 0.293579,
+		2.221898, -0.251335,
+		3.605304, 0.042244,
+	})
+	Xt, _ = pca.Transform(X, nil)
+	if !mat.EqualApprox(expected, Xt, 1e-6) {
+		t.Errorf("expected python pca output %v, got %v", mat.Formatted(expected), mat.Formatted(Xt))
+	}
+	if Xinv, _ := pca.InverseTransform(Xt, nil); !mat.EqualApprox(X, Xinv, 1e-10) {
+		t.Error("InverseTransform did not recover X")
+	}
+	if err := (&PCA{}).Unmarshal([]byte(`{"params": {"whiten": true}, "n_components_": 1, "components_": [[1, 0]]}`)); err == nil {
+		t.Error("expected error for whiten")
+	}
+}
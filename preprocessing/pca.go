@@ -8,11 +8,16 @@ import (
 )
 
 // PCA is a thin single value decomposition transformer
+// Components columns are the NComponents first right singular vectors of X (NFeatures,NComponents)
+// Fit does not center X. Mean and ExplainedVariance are only set by Unmarshal of a scikit-learn PCA,
+// Mean being then subtracted from X by Transform
 type PCA struct {
 	mat.SVD
 	MinVarianceRatio                       float64
 	NComponents                            int
 	SingularValues, ExplainedVarianceRatio []float64
+	Components                             *mat.Dense
+	Mean, ExplainedVariance                []float64
 }
 
 // NewPCA returns a *PCA
@@ -48,18 +53,35 @@ func (m *PCA) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
 			m.NComponents = c
 		}
 	}
-
+	m.Components = nil
+	m.Components = m.components()
+	m.Mean, m.ExplainedVariance = nil, nil
 	return m
 }
 
-// Transform Transforms X
-func (m *PCA) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+// components returns Components, or the NComponents first columns of SVD V
+func (m *PCA) components() *mat.Dense {
+	if m.Components != nil {
+		return m.Components
+	}
 	var v = new(mat.Dense)
 	m.SVD.VTo(v)
-	nSamples, _ := X.Dims()
 	vRows, _ := v.Dims()
+	return base.MatDenseSlice(v, 0, vRows, 0, m.NComponents)
+}
+
+// Transform Transforms X
+func (m *PCA) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	nSamples, _ := X.Dims()
 	Xout = mat.NewDense(nSamples, m.NComponents, nil)
-	Xout.Mul(X, base.MatDenseSlice(v, 0, vRows, 0, m.NComponents))
+	if m.Mean != nil {
+		Xc := mat.DenseCopyOf(X)
+		for i := 0; i < nSamples; i++ {
+			floats.Sub(Xc.RawRowView(i), m.Mean)
+		}
+		X = Xc
+	}
+	Xout.Mul(X, m.components())
 
 	Yout = base.ToDense(Y)
 	return
@@ -77,12 +99,16 @@ func (m *PCA) InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 		return X, Y
 	}
 
-	var v = new(mat.Dense)
-	m.SVD.VTo(v)
+	v := m.components()
 	nSamples, _ := X.Dims()
-	_, vCols := v.Dims()
-	Xout = mat.NewDense(nSamples, vCols, nil)
+	vRows, _ := v.Dims()
+	Xout = mat.NewDense(nSamples, vRows, nil)
 	Xout.Mul(X, v.T())
+	if m.Mean != nil {
+		for i := 0; i < nSamples; i++ {
+			floats.Add(Xout.RawRowView(i), m.Mean)
+		}
+	}
 	Yout = Y
 	return
 }
@@ -0,0 +1,138 @@
+package preprocessing
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// SplineTransformer generates univariate B-spline bases for features.
+// each feature is expanded into NKnots+Degree-1 columns.
+// KnotStrategy = "uniform","quantile".
+// Knots[f] are the NKnots base knots of feature f padded with Degree equally spaced knots on each side.
+// values outside of the base knots range are clipped to it
+type SplineTransformer struct {
+	NKnots       int
+	Degree       int
+	KnotStrategy string
+	Knots        [][]float64
+}
+
+// NewSplineTransformer returns a SplineTransformer with NKnots=5, Degree=3 and KnotStrategy="uniform"
+func NewSplineTransformer() *SplineTransformer {
+	return &SplineTransformer{NKnots: 5, Degree: 3, KnotStrategy: "uniform"}
+}
+
+// TransformerClone ...
+func (m *SplineTransformer) TransformerClone() base.Transformer {
+	clone := *m
+	return &clone
+}
+
+// Fit computes the knots of each feature
+func (m *SplineTransformer) Fit(X, Y mat.Matrix) base.Fiter {
+	if m.NKnots < 2 {
+		panic(fmt.Errorf("NKnots must be >=2, got %d", m.NKnots))
+	}
+	if m.Degree < 0 {
+		panic(fmt.Errorf("Degree must be >=0, got %d", m.Degree))
+	}
+	NSamples, NFeatures := X.Dims()
+	m.Knots = make([][]float64, NFeatures)
+	tmp := make([]float64, NSamples)
+	for f := 0; f < NFeatures; f++ {
+		mat.Col(tmp, f, X)
+		baseKnots := make([]float64, m.NKnots)
+		switch m.KnotStrategy {
+		case "uniform":
+			min, max := floats.Min(tmp), floats.Max(tmp)
+			if max == min {
+				max = min + 1
+			}
+			floats.Span(baseKnots, min, max)
+		case "quantile":
+			sorted := append([]float64{}, tmp...)
+			sort.Float64s(sorted)
+			for k := range baseKnots {
+				baseKnots[k] = stat.Quantile(float64(k)/float64(m.NKnots-1), stat.LinInterp, sorted, nil)
+			}
+			if baseKnots[m.NKnots-1] == baseKnots[0] {
+				floats.Span(baseKnots, baseKnots[0], baseKnots[0]+1)
+			}
+		default:
+			panic(fmt.Errorf("not implemented knot strategy %s", m.KnotStrategy))
+		}
+		distMin, distMax := baseKnots[1]-baseKnots[0], baseKnots[m.NKnots-1]-baseKnots[m.NKnots-2]
+		knots := make([]float64, m.NKnots+2*m.Degree)
+		for k := 0; k < m.Degree; k++ {
+			knots[k] = baseKnots[0] - float64(m.Degree-k)*distMin
+			knots[m.Degree+m.NKnots+k] = baseKnots[m.NKnots-1] + float64(k+1)*distMax
+		}
+		copy(knots[m.Degree:], baseKnots)
+		m.Knots[f] = knots
+	}
+	return m
+}
+
+// Transform returns the B-spline bases of each feature
+func (m *SplineTransformer) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	NSamples, NFeatures := X.Dims()
+	if NFeatures != len(m.Knots) {
+		panic(fmt.Errorf("X has %d features, expected %d", NFeatures, len(m.Knots)))
+	}
+	p := m.Degree
+	NBases := m.NKnots + p - 1
+	Xout = mat.NewDense(NSamples, NFeatures*NBases, nil)
+	base.Parallelize(-1, NSamples, func(th, start, end int) {
+		N, left, right := make([]float64, p+1), make([]float64, p+1), make([]float64, p+1)
+		for i := start; i < end; i++ {
+			xo := Xout.RawRowView(i)
+			for f, t := range m.Knots {
+				x := X.At(i, f)
+				lo, hi := t[p], t[p+m.NKnots-1]
+				if x < lo {
+					x = lo
+				} else if x > hi {
+					x = hi
+				}
+				// find interval mu such as t[mu] <= x < t[mu+1], using the last one for x == hi
+				mu := p
+				for mu < p+m.NKnots-2 && t[mu+1] <= x {
+					mu++
+				}
+				splineBasis(N, left, right, t, mu, p, x)
+				copy(xo[f*NBases+mu-p:], N)
+			}
+		}
+	})
+	return Xout, base.ToDense(Y)
+}
+
+// splineBasis computes in N the p+1 non-zero B-spline bases mu-p..mu at x (Cox-de Boor recursion)
+func splineBasis(N, left, right, t []float64, mu, p int, x float64) {
+	N[0] = 1
+	for j := 1; j <= p; j++ {
+		left[j] = x - t[mu+1-j]
+		right[j] = t[mu+j] - x
+		saved := 0.
+		for r := 0; r < j; r++ {
+			var temp float64
+			if den := right[r+1] + left[j-r]; den != 0 {
+				temp = N[r] / den
+			}
+			N[r] = saved + right[r+1]*temp
+			saved = left[j-r] * temp
+		}
+		N[j] = saved
+	}
+}
+
+// FitTransform fits the knots then transforms X
+func (m *SplineTransformer) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	m.Fit(X, Y)
+	return m.Transform(X, Y)
+}
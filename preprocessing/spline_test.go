@@ -0,0 +1,61 @@
+package preprocessing
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSplineTransformer(t *testing.T) {
+	rnd := rand.New(base.NewLockedSource(7))
+	X := mat.NewDense(100, 2, nil)
+	for i := 0; i < 100; i++ {
+		X.Set(i, 0, rnd.NormFloat64())
+		X.Set(i, 1, 10*rnd.Float64())
+	}
+	for _, strategy := range []string{"uniform", "quantile"} {
+		m := NewSplineTransformer()
+		m.KnotStrategy = strategy
+		Xt, _ := m.FitTransform(X, nil)
+		_, cols := Xt.Dims()
+		NBases := m.NKnots + m.Degree - 1
+		if cols != 2*NBases {
+			t.Fatalf("%s: expected %d columns, got %d", strategy, 2*NBases, cols)
+		}
+		// partition of unity, including for values outside of the fitted range
+		Xt2, _ := m.Transform(mat.NewDense(1, 2, []float64{-100, 100}), nil)
+		for _, Xt := range []*mat.Dense{Xt, Xt2} {
+			rows, _ := Xt.Dims()
+			for i := 0; i < rows; i++ {
+				for f := 0; f < 2; f++ {
+					row := Xt.RawRowView(i)[f*NBases : (f+1)*NBases]
+					if floats.Min(row) < 0 {
+						t.Errorf("%s: negative basis at row %d feature %d: %g", strategy, i, f, row)
+					}
+					if s := floats.Sum(row); math.Abs(s-1) > 1e-12 {
+						t.Errorf("%s: bases sum to %g at row %d feature %d", strategy, s, i, f)
+					}
+				}
+			}
+		}
+	}
+}
+
+func ExampleSplineTransformer() {
+	X := mat.NewDense(5, 1, []float64{0, 1, 2, 3, 4})
+	m := NewSplineTransformer()
+	m.NKnots, m.Degree = 3, 2
+	Xt, _ := m.FitTransform(X, nil)
+	fmt.Printf("%.3f\n", mat.Formatted(Xt))
+	// Output:
+	// ⎡0.500  0.500  0.000  0.000⎤
+	// ⎢0.125  0.750  0.125  0.000⎥
+	// ⎢0.000  0.500  0.500  0.000⎥
+	// ⎢0.000  0.125  0.750  0.125⎥
+	// ⎣0.000  0.000  0.500  0.500⎦
+}
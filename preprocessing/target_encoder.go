@@ -0,0 +1,135 @@
+package preprocessing
+
+import (
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+// TargetEncoder encodes each category of each column of X by the smoothed mean of the first column of Y for this category:
+// (count*categoryMean + Smoothing*TargetMean) / (count + Smoothing).
+// categories unseen at Fit are encoded by TargetMean.
+// FitTransform uses cross fitting: samples of each of CV folds are encoded with encodings fitted on the other folds,
+// to reduce target leakage. Transform uses encodings fitted on all samples
+type TargetEncoder struct {
+	Smoothing   float64
+	CV          int
+	Shuffle     bool
+	RandomState base.RandomState
+
+	Encodings  []map[float64]float64
+	TargetMean float64
+}
+
+// NewTargetEncoder returns a *TargetEncoder with Smoothing=10, CV=5 and Shuffle
+func NewTargetEncoder() *TargetEncoder {
+	return &TargetEncoder{Smoothing: 10, CV: 5, Shuffle: true}
+}
+
+// TransformerClone ...
+func (m *TargetEncoder) TransformerClone() base.Transformer {
+	clone := *m
+	if sourceCloner, ok := clone.RandomState.(base.SourceCloner); ok && sourceCloner != base.SourceCloner(nil) {
+		clone.RandomState = sourceCloner.SourceClone()
+	}
+	return &clone
+}
+
+// Fit computes encodings of each category on all samples
+func (m *TargetEncoder) Fit(Xmatrix, Ymatrix mat.Matrix) base.Fiter {
+	nSamples, _ := Xmatrix.Dims()
+	idx := make([]int, nSamples)
+	for i := range idx {
+		idx[i] = i
+	}
+	m.Encodings, m.TargetMean = m.fitIndices(Xmatrix, Ymatrix, idx)
+	return m
+}
+
+// fitIndices returns encodings and target mean computed on rows idx
+func (m *TargetEncoder) fitIndices(X, Y mat.Matrix, idx []int) (encodings []map[float64]float64, targetMean float64) {
+	if Y == nil {
+		panic(fmt.Errorf("TargetEncoder: Y is required"))
+	}
+	_, nFeatures := X.Dims()
+	for _, i := range idx {
+		targetMean += Y.At(i, 0)
+	}
+	targetMean /= float64(len(idx))
+	encodings = make([]map[float64]float64, nFeatures)
+	for j := range encodings {
+		sums, counts := make(map[float64]float64), make(map[float64]float64)
+		for _, i := range idx {
+			category := X.At(i, j)
+			sums[category] += Y.At(i, 0)
+			counts[category]++
+		}
+		encodings[j] = make(map[float64]float64, len(sums))
+		for category, sum := range sums {
+			encodings[j][category] = (sum + m.Smoothing*targetMean) / (counts[category] + m.Smoothing)
+		}
+	}
+	return
+}
+
+// encode sets rows idx of Xout to the encodings of the categories in X
+func encode(Xout *mat.Dense, X mat.Matrix, idx []int, encodings []map[float64]float64, targetMean float64) {
+	for _, i := range idx {
+		for j, encoding := range encodings {
+			if v, ok := encoding[X.At(i, j)]; ok {
+				Xout.Set(i, j, v)
+			} else {
+				Xout.Set(i, j, targetMean)
+			}
+		}
+	}
+}
+
+// Transform maps categories to their encodings
+func (m *TargetEncoder) Transform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	nSamples, nFeatures := X.Dims()
+	Xout = mat.NewDense(nSamples, nFeatures, nil)
+	idx := make([]int, nSamples)
+	for i := range idx {
+		idx[i] = i
+	}
+	encode(Xout, X, idx, m.Encodings, m.TargetMean)
+	return Xout, base.ToDense(Y)
+}
+
+// FitTransform fits encodings on all samples, and returns X encoded by cross fitting
+func (m *TargetEncoder) FitTransform(X, Y mat.Matrix) (Xout, Yout *mat.Dense) {
+	m.Fit(X, Y)
+	nSamples, nFeatures := X.Dims()
+	cv := m.CV
+	if cv < 2 || cv > nSamples {
+		return m.Transform(X, Y)
+	}
+	perm := make([]int, nSamples)
+	for i := range perm {
+		perm[i] = i
+	}
+	if m.Shuffle {
+		var shuffle = rand.New(base.DefaultSource()).Shuffle
+		if m.RandomState != base.RandomState(nil) {
+			shuffle = rand.New(m.RandomState).Shuffle
+		}
+		shuffle(nSamples, func(i, j int) { perm[i], perm[j] = perm[j], perm[i] })
+	}
+	Xout = mat.NewDense(nSamples, nFeatures, nil)
+	for fold := 0; fold < cv; fold++ {
+		var trainIdx, testIdx []int
+		for pos, i := range perm {
+			if pos%cv == fold {
+				testIdx = append(testIdx, i)
+			} else {
+				trainIdx = append(trainIdx, i)
+			}
+		}
+		encodings, targetMean := m.fitIndices(X, Y, trainIdx)
+		encode(Xout, X, testIdx, encodings, targetMean)
+	}
+	return Xout, base.ToDense(Y)
+}
@@ -0,0 +1,50 @@
+package preprocessing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestTargetEncoder(t *testing.T) {
+	// 200 categories of 20 samples, with a target mean of category/100
+	nCategories, perCategory := 200, 20
+	nSamples := nCategories * perCategory
+	rnd := rand.New(base.NewSource(7))
+	X, Y := mat.NewDense(nSamples, 1, nil), mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		category := i % nCategories
+		X.Set(i, 0, float64(category))
+		Y.Set(i, 0, float64(category)/100+.1*rnd.NormFloat64())
+	}
+	m := NewTargetEncoder()
+	m.Smoothing = 1
+	m.RandomState = base.NewSource(7)
+	Xcv, _ := m.FitTransform(X, Y)
+	if len(m.Encodings[0]) != nCategories {
+		t.Fatalf("expected %d encoded categories, got %d", nCategories, len(m.Encodings[0]))
+	}
+	Xt, _ := m.Transform(X, Y)
+	crossFitted := false
+	for i := 0; i < nSamples; i++ {
+		mean := X.At(i, 0) / 100
+		if math.Abs(Xt.At(i, 0)-mean) > .15 {
+			t.Fatalf("sample %d: expected encoding near %g, got %g", i, mean, Xt.At(i, 0))
+		}
+		if math.Abs(Xcv.At(i, 0)-mean) > .2 {
+			t.Fatalf("sample %d: expected cross fitted encoding near %g, got %g", i, mean, Xcv.At(i, 0))
+		}
+		crossFitted = crossFitted || Xcv.At(i, 0) != Xt.At(i, 0)
+	}
+	if !crossFitted {
+		t.Error("FitTransform did not use cross fitting")
+	}
+	// unseen categories are encoded by the target mean
+	Xu, _ := m.Transform(mat.NewDense(1, 1, []float64{-1}), nil)
+	if Xu.At(0, 0) != m.TargetMean {
+		t.Errorf("expected %g for unseen category, got %g", m.TargetMean, Xu.At(0, 0))
+	}
+}
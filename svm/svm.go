@@ -63,7 +63,7 @@ func svmTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func
 		}
 		return y
 	}
-	randIntn := rand.Intn
+	randIntn := rand.New(base.DefaultSource()).Intn
 	if RandomState != nil {
 		type Intner interface{ Intn(int) int }
 		if intner, ok := RandomState.(Intner); ok {
@@ -75,7 +75,7 @@ func svrTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func
 		}
 		return 1
 	}
-	randIntn := rand.Intn
+	randIntn := rand.New(base.DefaultSource()).Intn
 	if RandomState != nil {
 		type Intner interface{ Intn(int) int }
 		if intner, ok := RandomState.(Intner); ok {